@@ -0,0 +1,43 @@
+package resty
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetWithAuthRefresh 发送 GET 请求，令牌过期返回 401 时刷新令牌并重试一次
+//
+// 收到 401 后调用 refresh 获取新的 Bearer 令牌，设置到 Authorization 请求头后重新发送请求，
+// 重试只进行一次，第二次请求的结果无论状态码如何都直接返回。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//   - refresh: 令牌刷新函数，返回新的 Bearer 令牌
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - err: 请求错误或令牌刷新错误，如果成功则为 nil
+//
+// 示例:
+//
+//	headers := map[string]string{"Authorization": "Bearer " + token}
+//	resp, err := GetWithAuthRefresh("https://api.example.com", headers, func() (string, error) {
+//	    return tokenSource.Refresh()
+//	})
+func GetWithAuthRefresh(url string, header map[string]string, refresh func() (string, error)) (resp []byte, err error) {
+	res, err := Do(context.Background(), http.MethodGet, url, WithHeaders(header))
+	if err != nil {
+		return
+	}
+	if !res.Is(http.StatusUnauthorized) {
+		resp = res.Body
+		return
+	}
+
+	token, err := refresh()
+	if err != nil {
+		return
+	}
+	return doBody(http.MethodGet, url, WithHeaders(header), WithHeaders(map[string]string{"Authorization": "Bearer " + token}))
+}
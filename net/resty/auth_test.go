@@ -0,0 +1,82 @@
+package resty_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetWithAuthRefresh(t *testing.T) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		assert.Equal(t, "test-value", r.Header.Get("X-Test-Header"))
+		if r.Header.Get("Authorization") != "Bearer new-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	var refreshes int64
+	headers := map[string]string{
+		"Authorization": "Bearer expired-token",
+		"X-Test-Header": "test-value",
+	}
+	resp, err := GetWithAuthRefresh(ts.URL+"/test", headers, func() (string, error) {
+		atomic.AddInt64(&refreshes, 1)
+		return "new-token", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&refreshes))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+}
+
+func TestGetWithAuthRefreshRetriesOnce(t *testing.T) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := io.WriteString(w, `{"error":"unauthorized"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	var refreshes int64
+	resp, err := GetWithAuthRefresh(ts.URL+"/test", nil, func() (string, error) {
+		atomic.AddInt64(&refreshes, 1)
+		return "still-invalid", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"error":"unauthorized"}`), resp)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&refreshes))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+}
+
+func TestGetWithAuthRefreshError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	refreshErr := errors.New("refresh failed")
+	resp, err := GetWithAuthRefresh(ts.URL+"/test", nil, func() (string, error) {
+		return "", refreshErr
+	})
+	assert.ErrorIs(t, err, refreshErr)
+	assert.Nil(t, resp)
+}
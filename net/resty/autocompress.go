@@ -0,0 +1,70 @@
+package resty
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+)
+
+// DefaultAutoCompressMinSize JsonAutoCompress 默认的压缩阈值（字节）
+//
+// 小于该大小的 JSON 压缩后往往不会更小（gzip 自身约有 20 字节的头尾开销），压缩只会浪费 CPU。
+const DefaultAutoCompressMinSize = 1024
+
+// JsonAutoCompress 发送 JSON 格式的 POST 请求，序列化后的请求体超过阈值时才使用 gzip 压缩
+//
+// 自动设置 Content-Type 为 application/json。请求体超过 minSize 字节时以 gzip 压缩发送，
+// 并设置 Content-Encoding: gzip；未超过时与 Json 一致，按原样发送。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - body: 请求体内容，将被序列化为 JSON
+//   - header: 自定义的 HTTP 请求头
+//   - minSize: 压缩阈值（字节），小于等于 0 时使用 DefaultAutoCompressMinSize
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - error: 请求体序列化或请求过程中的错误信息，如果请求成功则为 nil
+//
+// 注意:
+//   - 服务端需要支持解压 Content-Encoding: gzip 的请求体
+//
+// 示例:
+//
+//	resp, err := JsonAutoCompress("https://api.example.com/events", events, nil, 0)
+func JsonAutoCompress(url string, body interface{}, header map[string]string, minSize int) ([]byte, error) {
+	if minSize <= 0 {
+		minSize = DefaultAutoCompressMinSize
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) <= minSize {
+		return doBody(http.MethodPost, url, WithHeaders(header), WithJSONBody(data))
+	}
+
+	compressed, err := gzipBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return doBody(http.MethodPost, url,
+		WithHeaders(header),
+		WithHeaders(map[string]string{"Content-Encoding": "gzip"}),
+		WithJSONBody(compressed),
+	)
+}
+
+// gzipBytes 使用 gzip 压缩数据
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
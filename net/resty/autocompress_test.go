@@ -0,0 +1,70 @@
+package resty_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestJsonAutoCompress(t *testing.T) {
+	type received struct {
+		encoding string
+		body     string
+	}
+	var got received
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, ContentTypeJson, r.Header.Get(ContentType))
+		assert.Equal(t, "Bearer token123", r.Header.Get("Authorization"))
+		got.encoding = r.Header.Get("Content-Encoding")
+
+		var reader io.Reader = r.Body
+		if got.encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader = zr
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.body = string(data)
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+	header := map[string]string{"Authorization": "Bearer token123"}
+
+	// 小于阈值时不压缩
+	resp, err := JsonAutoCompress(ts.URL, map[string]string{"name": "test"}, header, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	assert.Equal(t, received{body: `{"name":"test"}`}, got)
+
+	// 超过默认阈值时压缩
+	large := map[string]string{"data": strings.Repeat("a", DefaultAutoCompressMinSize)}
+	_, err = JsonAutoCompress(ts.URL, large, header, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, received{encoding: "gzip", body: `{"data":"` + strings.Repeat("a", DefaultAutoCompressMinSize) + `"}`}, got)
+
+	// 自定义阈值，恰好等于阈值时不压缩
+	_, err = JsonAutoCompress(ts.URL, map[string]string{"name": "test"}, header, len(`{"name":"test"}`))
+	assert.NoError(t, err)
+	assert.Empty(t, got.encoding)
+	_, err = JsonAutoCompress(ts.URL, map[string]string{"name": "test"}, header, len(`{"name":"test"}`)-1)
+	assert.NoError(t, err)
+	assert.Equal(t, received{encoding: "gzip", body: `{"name":"test"}`}, got)
+
+	// 无法序列化时不发送请求
+	got = received{}
+	_, err = JsonAutoCompress(ts.URL, map[string]interface{}{"ch": make(chan int)}, header, 0)
+	assert.Error(t, err)
+	assert.Equal(t, received{}, got)
+}
@@ -0,0 +1,178 @@
+package resty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrBroadcastFailed 成功的目标数量没有达到 Broadcast 要求的数量，具体结果见 *BroadcastError
+var ErrBroadcastFailed = errors.New("broadcast failed")
+
+// BroadcastResult 单个目标的请求结果
+type BroadcastResult struct {
+	// URL 目标地址
+	URL string
+	// StatusCode HTTP 状态码，请求未完成时为 0
+	StatusCode int
+	// Body 响应体
+	Body []byte
+	// Duration 请求耗时
+	Duration time.Duration
+	// Err 请求错误或非 2xx 状态码，成功时为 nil
+	Err error
+}
+
+// BroadcastError 成功数量不足时 Broadcast 返回的错误，可通过 errors.Is(err, ErrBroadcastFailed) 判断
+type BroadcastError struct {
+	// Succeeded 成功的目标数量
+	Succeeded int
+	// Required 要求成功的目标数量
+	Required int
+	// Failed 失败的目标地址
+	Failed []string
+}
+
+// Error 实现 error 接口
+func (e *BroadcastError) Error() string {
+	return fmt.Sprintf("%s: %d of %d required targets succeeded, failed: %v", ErrBroadcastFailed, e.Succeeded, e.Required, e.Failed)
+}
+
+// Unwrap 返回 ErrBroadcastFailed
+func (e *BroadcastError) Unwrap() error {
+	return ErrBroadcastFailed
+}
+
+// broadcastConfig Broadcast 的配置
+type broadcastConfig struct {
+	// quorum 要求成功的目标数量，为 0 时要求全部成功
+	quorum int
+}
+
+// BroadcastOption Broadcast 的配置项
+type BroadcastOption func(*broadcastConfig)
+
+// RequireAll 要求所有目标都成功，这是默认行为
+func RequireAll() BroadcastOption {
+	return func(c *broadcastConfig) {
+		c.quorum = 0
+	}
+}
+
+// RequireQuorum 要求至少 n 个目标成功，n 大于目标数量时等同于 RequireAll
+func RequireQuorum(n int) BroadcastOption {
+	return func(c *broadcastConfig) {
+		c.quorum = n
+	}
+}
+
+// Broadcast 向多个目标并发发送相同的请求，并汇总每个目标的结果
+//
+// 适用于缓存失效、配置下发等需要通知所有副本的场景。请求错误和非 2xx 状态码都视为失败。
+// 无论成功与否，返回的结果与 urls 一一对应，调用方可以据此重试失败的目标；
+// ctx 取消后尚未发出的请求不会再发出，其结果的 Err 为 ctx 的错误。
+// io.Reader 类型的请求体会预先读入内存，以便发送给每个目标。
+//
+// 参数:
+//   - ctx: 请求上下文
+//   - urls: 目标地址列表
+//   - method: HTTP 方法
+//   - body: 请求体内容，可以是任意类型，为 nil 时不发送请求体
+//   - header: 自定义的 HTTP 请求头
+//   - concurrency: 最大并发数，为 0 时同时向所有目标发送
+//   - opts: 成功条件，默认 RequireAll
+//
+// 返回值:
+//   - []BroadcastResult: 每个目标的结果，顺序与 urls 一致
+//   - error: 成功数量不足时为 *BroadcastError，读取请求体失败时为对应的错误
+//
+// 示例:
+//
+//	replicas := []string{"http://10.0.0.1/cache/purge", "http://10.0.0.2/cache/purge", "http://10.0.0.3/cache/purge"}
+//	results, err := Broadcast(ctx, replicas, http.MethodPost, keys, nil, 0, RequireQuorum(2))
+//	for _, r := range results {
+//	    if r.Err != nil {
+//	        log.Printf("%s: %v", r.URL, r.Err)
+//	    }
+//	}
+func Broadcast(ctx context.Context, urls []string, method string, body interface{}, header map[string]string, concurrency int, opts ...BroadcastOption) ([]BroadcastResult, error) {
+	cfg := &broadcastConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if concurrency <= 0 || concurrency > len(urls) {
+		concurrency = len(urls)
+	}
+	if reader, ok := body.(io.Reader); ok {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+
+	results := make([]BroadcastResult, len(urls))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, url := range urls {
+		results[i].URL = url
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *BroadcastResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			broadcastOne(ctx, method, body, header, result)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	required := len(urls)
+	if cfg.quorum > 0 && cfg.quorum < required {
+		required = cfg.quorum
+	}
+	broadcastErr := &BroadcastError{Required: required}
+	for _, result := range results {
+		if result.Err != nil {
+			broadcastErr.Failed = append(broadcastErr.Failed, result.URL)
+		} else {
+			broadcastErr.Succeeded++
+		}
+	}
+	if broadcastErr.Succeeded < required {
+		return results, broadcastErr
+	}
+	return results, nil
+}
+
+// broadcastOne 向单个目标发送请求并填充结果
+func broadcastOne(ctx context.Context, method string, body interface{}, header map[string]string, result *BroadcastResult) {
+	opts := []RequestOption{WithHeaders(header)}
+	if body != nil {
+		opts = append(opts, WithBody(body))
+	}
+
+	start := time.Now()
+	res, err := Do(ctx, method, result.URL, opts...)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	result.StatusCode = res.StatusCode
+	result.Body = res.Body
+	if !res.IsSuccess() {
+		result.Err = fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,89 @@
+package resty_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// replicaServer 返回指定状态码，并校验请求体和请求头
+func replicaServer(t *testing.T, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"keys":["a","b"]}`, string(body))
+
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, http.StatusText(status))
+	}))
+}
+
+func TestBroadcast(t *testing.T) {
+	ok1 := replicaServer(t, http.StatusOK)
+	defer ok1.Close()
+	ok2 := replicaServer(t, http.StatusNoContent)
+	defer ok2.Close()
+	failing := replicaServer(t, http.StatusServiceUnavailable)
+	defer failing.Close()
+
+	urls := []string{ok1.URL, failing.URL, ok2.URL}
+	body := map[string][]string{"keys": {"a", "b"}}
+	headers := map[string]string{"Authorization": "test-token", "Content-Type": "application/json"}
+
+	t.Run("require all", func(t *testing.T) {
+		results, err := Broadcast(context.Background(), urls, http.MethodPost, body, headers, 2)
+		assert.ErrorIs(t, err, ErrBroadcastFailed)
+		var broadcastErr *BroadcastError
+		if assert.True(t, errors.As(err, &broadcastErr)) {
+			assert.Equal(t, 2, broadcastErr.Succeeded)
+			assert.Equal(t, 3, broadcastErr.Required)
+			assert.Equal(t, []string{failing.URL}, broadcastErr.Failed)
+		}
+
+		// 部分失败时仍返回每个目标的结果
+		if assert.Len(t, results, 3) {
+			assert.Equal(t, ok1.URL, results[0].URL)
+			assert.Equal(t, http.StatusOK, results[0].StatusCode)
+			assert.Equal(t, []byte("OK"), results[0].Body)
+			assert.NoError(t, results[0].Err)
+			assert.Positive(t, results[0].Duration)
+
+			assert.Equal(t, failing.URL, results[1].URL)
+			assert.Equal(t, http.StatusServiceUnavailable, results[1].StatusCode)
+			assert.EqualError(t, results[1].Err, "unexpected status code: 503")
+
+			assert.Equal(t, http.StatusNoContent, results[2].StatusCode)
+			assert.NoError(t, results[2].Err)
+		}
+	})
+
+	t.Run("quorum met", func(t *testing.T) {
+		results, err := Broadcast(context.Background(), urls, http.MethodPost, body, headers, 0, RequireQuorum(2))
+		assert.NoError(t, err)
+		assert.Error(t, results[1].Err)
+	})
+
+	t.Run("quorum not met", func(t *testing.T) {
+		urls := []string{ok1.URL, failing.URL, failing.URL}
+		_, err := Broadcast(context.Background(), urls, http.MethodPost, body, headers, 0, RequireQuorum(2))
+		assert.ErrorIs(t, err, ErrBroadcastFailed)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results, err := Broadcast(ctx, urls, http.MethodPost, body, headers, 1)
+		assert.ErrorIs(t, err, ErrBroadcastFailed)
+		for _, result := range results {
+			assert.ErrorIs(t, result.Err, context.Canceled)
+		}
+	})
+}
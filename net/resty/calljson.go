@@ -0,0 +1,99 @@
+package resty
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrAPIError 服务端返回了非 2xx 状态码，具体的错误响应见 *APIError[E]
+var ErrAPIError = errors.New("api error")
+
+// APIError CallJSON 在非 2xx 响应时返回的错误，携带解析后的类型化错误响应
+//
+// 可以通过 errors.As(err, &apiErr)（apiErr 为 *APIError[E]）取出错误响应，
+// 也可以通过 errors.Is(err, ErrAPIError) 判断是否为服务端返回的错误。
+type APIError[E any] struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+	// Body 原始响应体
+	Body []byte
+	// Value 解析后的错误响应，响应体为空或不是合法的 JSON 时为 E 的零值
+	Value E
+	// DecodeErr 解析错误响应失败时的错误，解析成功或响应体为空时为 nil
+	DecodeErr error
+}
+
+// Error 实现 error 接口，E 实现了 error 时使用其错误信息
+func (e *APIError[E]) Error() string {
+	if err, ok := any(e.Value).(error); ok && e.DecodeErr == nil && len(e.Body) > 0 {
+		return fmt.Sprintf("%s: status %d: %v", ErrAPIError, e.StatusCode, err)
+	}
+	return fmt.Sprintf("%s: status %d", ErrAPIError, e.StatusCode)
+}
+
+// Unwrap 返回 ErrAPIError
+func (e *APIError[E]) Unwrap() error {
+	return ErrAPIError
+}
+
+// APIValue 返回解析后的类型化错误响应
+func (e *APIError[E]) APIValue() E {
+	return e.Value
+}
+
+// CallJSON 使用任意 HTTP 方法发送 JSON 请求，成功时将响应解析为 T，失败时将错误响应解析为 E
+//
+// 2xx 响应解析为 T 返回，响应体为空时返回 T 的零值；
+// 非 2xx 响应解析为 E 并包装为 *APIError[E] 返回，错误响应无法解析时仍然返回 *APIError[E]，
+// 其中 DecodeErr 记录解析错误，调用方可以通过 StatusCode 和 Body 自行处理。
+// 请求体不为 nil 时以 JSON 发送，header 中的 Content-Type 优先。
+//
+// 参数:
+//   - method: HTTP 方法
+//   - url: 目标请求地址
+//   - body: 请求体内容，为 nil 时不发送请求体
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - T: 成功时解析的响应
+//   - error: 请求错误、JSON 解析错误或 *APIError[E]，如果成功则为 nil
+//
+// 示例:
+//
+//	type APIErr struct {
+//	    Code    string `json:"code"`
+//	    Message string `json:"message"`
+//	}
+//	user, err := CallJSON[User, APIErr](http.MethodPut, "https://api.example.com/users/1", update, nil)
+//	var apiErr *APIError[APIErr]
+//	if errors.As(err, &apiErr) && apiErr.APIValue().Code == "conflict" {
+//	    // 处理冲突
+//	}
+func CallJSON[T, E any](method, url string, body interface{}, header map[string]string) (T, error) {
+	var result T
+	opts := []RequestOption{WithHeaders(header)}
+	if body != nil {
+		opts = []RequestOption{WithJSONBody(body), WithHeaders(header)}
+	}
+	res, err := Do(context.Background(), method, url, opts...)
+	if err != nil {
+		return result, err
+	}
+
+	if !res.IsSuccess() {
+		apiErr := &APIError[E]{StatusCode: res.StatusCode, Body: res.Body}
+		if len(res.Body) > 0 {
+			apiErr.DecodeErr = json.Unmarshal(res.Body, &apiErr.Value)
+		}
+		return result, apiErr
+	}
+
+	if len(res.Body) > 0 {
+		if err = decodeEntity(res.Body, &result); err != nil {
+			return result, truncatedError(res.Body, res.ContentLength, err)
+		}
+	}
+	return result, nil
+}
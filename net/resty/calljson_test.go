@@ -0,0 +1,90 @@
+package resty_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// apiErrorBody 测试用的错误响应
+type apiErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error 实现 error 接口
+func (e apiErrorBody) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// callServer 根据路径返回成功、错误或无法解析的响应
+func callServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, ContentTypeJson, r.Header.Get(ContentType))
+			assert.JSONEq(t, `{"name":"test"}`, string(body))
+			_, _ = w.Write([]byte(`{"status":"created","data":"1"}`))
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/conflict":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"code":"conflict","message":"user exists"}`))
+		case "/html":
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`<html>bad gateway</html>`))
+		}
+	}))
+}
+
+func TestCallJSON(t *testing.T) {
+	ts := callServer(t)
+	defer ts.Close()
+
+	result, err := CallJSON[TestResponse, apiErrorBody](http.MethodPost, ts.URL+"/ok", map[string]string{"name": "test"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "created", result.Status)
+
+	// 空响应体返回零值
+	result, err = CallJSON[TestResponse, apiErrorBody](http.MethodDelete, ts.URL+"/empty", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TestResponse{}, result)
+
+	// 成功响应的类型不匹配
+	_, err = CallJSON[[]TestResponse, apiErrorBody](http.MethodPost, ts.URL+"/ok", map[string]string{"name": "test"}, nil)
+	assert.ErrorIs(t, err, ErrJSONTypeMismatch)
+}
+
+func TestCallJSONAPIError(t *testing.T) {
+	ts := callServer(t)
+	defer ts.Close()
+
+	_, err := CallJSON[TestResponse, apiErrorBody](http.MethodPut, ts.URL+"/conflict", nil, nil)
+	assert.ErrorIs(t, err, ErrAPIError)
+	assert.EqualError(t, err, "api error: status 409: conflict: user exists")
+	var apiErr *APIError[apiErrorBody]
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+		assert.Equal(t, apiErrorBody{Code: "conflict", Message: "user exists"}, apiErr.APIValue())
+		assert.NoError(t, apiErr.DecodeErr)
+	}
+
+	// 错误响应无法解析时保留原始响应体
+	_, err = CallJSON[TestResponse, apiErrorBody](http.MethodGet, ts.URL+"/html", nil, nil)
+	assert.EqualError(t, err, "api error: status 502")
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+		assert.Equal(t, "<html>bad gateway</html>", string(apiErr.Body))
+		var syntaxErr *json.SyntaxError
+		assert.ErrorAs(t, apiErr.DecodeErr, &syntaxErr)
+		assert.Equal(t, apiErrorBody{}, apiErr.APIValue())
+	}
+}
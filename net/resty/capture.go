@@ -0,0 +1,128 @@
+package resty
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RedactedValue 捕获请求时替换敏感 header 值的占位符
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactedHeaders 捕获请求时默认脱敏的 header
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// CapturedRequest 可序列化的请求快照，用于复现线上问题
+//
+// 可以直接使用 encoding/json 序列化，Body 以 base64 编码。
+type CapturedRequest struct {
+	// Method HTTP 方法
+	Method string `json:"method"`
+	// URL 完整的请求地址，包括查询参数
+	URL string `json:"url"`
+	// Header 请求头，敏感 header 的值被替换为 RedactedValue
+	Header http.Header `json:"header,omitempty"`
+	// Body 请求体
+	Body []byte `json:"body,omitempty"`
+	// CapturedAt 捕获时间
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// CaptureRequest 捕获请求的方法、地址、请求头和请求体，敏感 header 会被脱敏
+//
+// 请求体通过 GetBody 读取副本，不会消费原请求的 Body，
+// 包内发送的请求（Response.RawResponse.Request.RawRequest、DoRaw 返回的 Request）都可以捕获；
+// 没有 GetBody 的流式请求体无法重复读取，捕获结果不包含请求体。
+// 查询参数不会脱敏，请避免将令牌放在查询参数中。
+//
+// 参数:
+//   - req: 需要捕获的请求
+//   - redact: 额外需要脱敏的 header，DefaultRedactedHeaders 中的 header 始终脱敏
+//
+// 返回值:
+//   - CapturedRequest: 请求快照
+//   - error: 读取请求体失败时的错误
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodPost, url, WithJSONBody(body))
+//	if err == nil && resp.IsServerError() {
+//	    captured, _ := CaptureRequest(resp.RawResponse.Request.RawRequest, "X-Tenant-Secret")
+//	    data, _ := json.Marshal(captured)
+//	    // 将 data 附在问题报告中
+//	}
+func CaptureRequest(req *http.Request, redact ...string) (CapturedRequest, error) {
+	captured := CapturedRequest{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Header:     req.Header.Clone(),
+		CapturedAt: time.Now(),
+	}
+	for _, key := range append(DefaultRedactedHeaders[:len(DefaultRedactedHeaders):len(DefaultRedactedHeaders)], redact...) {
+		key = http.CanonicalHeaderKey(key)
+		if values, ok := captured.Header[key]; ok {
+			for i := range values {
+				values[i] = RedactedValue
+			}
+		}
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return CapturedRequest{}, err
+		}
+		defer body.Close()
+		if captured.Body, err = io.ReadAll(body); err != nil {
+			return CapturedRequest{}, err
+		}
+	}
+	return captured, nil
+}
+
+// Replay 重新发送捕获的请求
+//
+// 值为 RedactedValue 的 header 不会发送，需要认证的请求应在重放前将 c.Header 中的对应值替换为有效的凭据。
+// 与其他便捷函数一致，非 2xx 状态码不会作为错误返回。
+//
+// 参数:
+//   - c: CaptureRequest 捕获的请求
+//
+// 返回值:
+//   - []byte: 响应体
+//   - *resty.Response: resty 原始响应，可以查看状态码和响应头
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	var captured CapturedRequest
+//	_ = json.Unmarshal(report, &captured)
+//	captured.Header.Set("Authorization", "Bearer "+debugToken)
+//	body, res, err := Replay(captured)
+func Replay(c CapturedRequest) ([]byte, *resty.Response, error) {
+	req := GetRequest(DefaultTimeout)
+	for key, values := range c.Header {
+		for _, value := range values {
+			if value != RedactedValue {
+				req.Header.Add(key, value)
+			}
+		}
+	}
+	if len(c.Body) > 0 {
+		req.SetBody(c.Body)
+	}
+
+	res, err := req.Execute(c.Method, c.URL)
+	if err != nil {
+		return nil, res, err
+	}
+	return res.Body(), res, nil
+}
@@ -0,0 +1,80 @@
+package resty_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestCaptureAndReplay(t *testing.T) {
+	type received struct {
+		method string
+		url    string
+		header http.Header
+		body   string
+	}
+	requests := make(chan received, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{method: r.Method, url: r.URL.String(), header: r.Header.Clone(), body: string(body)}
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("boom"))
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodPatch, ts.URL+"/orders/1?dry_run=true",
+		WithHeaders(map[string]string{
+			"Authorization": "Bearer secret",
+			"X-Tenant-Key":  "tenant-secret",
+			"X-Request-ID":  "req-1",
+		}),
+		WithJSONBody(map[string]string{"status": "paid"}),
+	)
+	assert.NoError(t, err)
+	original := <-requests
+
+	captured, err := CaptureRequest(resp.RawResponse.Request.RawRequest, "x-tenant-key")
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodPatch, captured.Method)
+	assert.Equal(t, ts.URL+"/orders/1?dry_run=true", captured.URL)
+	assert.Equal(t, RedactedValue, captured.Header.Get("Authorization"))
+	assert.Equal(t, RedactedValue, captured.Header.Get("X-Tenant-Key"))
+	assert.Equal(t, "req-1", captured.Header.Get("X-Request-ID"))
+	assert.JSONEq(t, `{"status":"paid"}`, string(captured.Body))
+	// 捕获不会修改原请求
+	assert.Equal(t, "Bearer secret", resp.RawResponse.Request.RawRequest.Header.Get("Authorization"))
+
+	// 序列化后不包含敏感信息
+	data, err := json.Marshal(captured)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+
+	var restored CapturedRequest
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	body, res, err := Replay(restored)
+	assert.NoError(t, err)
+	assert.Equal(t, "boom", string(body))
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode())
+
+	replayed := <-requests
+	assert.Equal(t, original.method, replayed.method)
+	assert.Equal(t, original.url, replayed.url)
+	assert.Equal(t, original.body, replayed.body)
+	assert.Equal(t, original.header.Get(ContentType), replayed.header.Get(ContentType))
+	assert.Equal(t, "req-1", replayed.header.Get("X-Request-ID"))
+	// 脱敏的 header 不会发送
+	assert.Empty(t, replayed.header.Get("Authorization"))
+	assert.Empty(t, replayed.header.Get("X-Tenant-Key"))
+}
+
+func TestReplayError(t *testing.T) {
+	body, _, err := Replay(CapturedRequest{Method: http.MethodGet, URL: "http://127.0.0.1:0"})
+	assert.Error(t, err)
+	assert.Nil(t, body)
+}
@@ -0,0 +1,292 @@
+package resty
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// 分块上传相关的默认值
+const (
+	// DefaultChunkSize 默认的分块大小
+	DefaultChunkSize int64 = 8 << 20
+	// DefaultChunkRetries 每个分块默认的最大重试次数
+	DefaultChunkRetries = 3
+	// ContentTypeOctetStream 二进制数据的 Content-Type 值
+	ContentTypeOctetStream = "application/octet-stream"
+)
+
+// ErrInvalidResumeToken 续传令牌无法解析，或与本次上传的文件大小、分块大小不一致
+var ErrInvalidResumeToken = errors.New("invalid resume token")
+
+// ChunkProtocol 分块上传协议，描述上传单个分块和完成上传的请求
+//
+// UploadChunked 负责读取分块、并发调度和重试，协议只需要返回每个请求的方法、地址和选项，
+// 2xx 状态码视为服务端已确认。ContentRangeProtocol 是默认实现，
+// tus 等协议可以自行实现该接口，分块请求需要等待服务端确认时可以在选项中加入 WithExpectContinue。
+type ChunkProtocol interface {
+	// Chunk 返回上传 [offset, offset+len(chunk)) 分块的请求方法、地址和选项，size 为文件总大小
+	Chunk(url string, offset int64, chunk []byte, size int64) (method, target string, opts []RequestOption)
+	// Finalize 返回所有分块上传完成后通知服务端的请求方法、地址和选项
+	Finalize(url string, size int64) (method, target string, opts []RequestOption)
+}
+
+// ContentRangeProtocol 使用 Content-Range 标识分块位置的上传协议
+//
+// 每个分块以 "Content-Range: bytes start-end/size" 发送，
+// 完成上传时向 FinalizeURL 发送 POST 请求，并携带 "Content-Range: bytes */size"。
+type ContentRangeProtocol struct {
+	// Method 上传分块的 HTTP 方法，为空时使用 PUT
+	Method string
+	// OffsetHeader 不为空时同时在该请求头中发送分块的起始偏移量，如 "Upload-Offset"
+	OffsetHeader string
+	// FinalizeURL 完成上传的请求地址，为空时使用上传地址
+	FinalizeURL string
+}
+
+// Chunk 实现 ChunkProtocol
+func (p ContentRangeProtocol) Chunk(url string, offset int64, chunk []byte, size int64) (string, string, []RequestOption) {
+	method := p.Method
+	if method == "" {
+		method = http.MethodPut
+	}
+	header := map[string]string{
+		ContentType:     ContentTypeOctetStream,
+		"Content-Range": fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(chunk))-1, size),
+	}
+	if p.OffsetHeader != "" {
+		header[p.OffsetHeader] = strconv.FormatInt(offset, 10)
+	}
+	return method, url, []RequestOption{WithHeaders(header), WithBody(chunk)}
+}
+
+// Finalize 实现 ChunkProtocol
+func (p ContentRangeProtocol) Finalize(url string, size int64) (string, string, []RequestOption) {
+	if p.FinalizeURL != "" {
+		url = p.FinalizeURL
+	}
+	return http.MethodPost, url, []RequestOption{
+		WithHeaders(map[string]string{"Content-Range": fmt.Sprintf("bytes */%d", size)}),
+	}
+}
+
+// ChunkConfig 分块上传配置
+type ChunkConfig struct {
+	// ChunkSize 分块大小，为 0 时使用 DefaultChunkSize
+	ChunkSize int64
+	// Concurrency 同时上传的分块数，为 0 时逐个上传；要求按顺序上传的协议（如 tus）必须为 0 或 1
+	Concurrency int
+	// Protocol 上传协议，为 nil 时使用 ContentRangeProtocol{}
+	Protocol ChunkProtocol
+	// Retry 单个分块的重试配置，为 nil 时最多重试 DefaultChunkRetries 次
+	Retry *RetryConfig
+	// Header 每个请求都会携带的 HTTP 请求头
+	Header map[string]string
+	// ResumeToken 上一次上传失败时 *ChunkUploadError 中的续传令牌，已确认的分块不会再次上传
+	ResumeToken string
+}
+
+// ChunkUploadError 分块上传失败时返回的错误，携带可用于续传的令牌
+type ChunkUploadError struct {
+	// ResumeToken 记录了已确认分块的续传令牌，传入 ChunkConfig.ResumeToken 继续上传
+	ResumeToken string
+	// Err 导致上传失败的错误
+	Err error
+}
+
+// Error 实现 error 接口
+func (e *ChunkUploadError) Error() string {
+	return fmt.Sprintf("chunked upload failed: %v", e.Err)
+}
+
+// Unwrap 返回导致上传失败的错误
+func (e *ChunkUploadError) Unwrap() error {
+	return e.Err
+}
+
+// resumeState 续传令牌的内容
+type resumeState struct {
+	Size      int64   `json:"size"`
+	ChunkSize int64   `json:"chunk_size"`
+	Acked     []int64 `json:"acked"`
+}
+
+// UploadChunked 将 r 中 size 字节的数据分块上传，适合在不稳定的网络上传输大文件
+//
+// 每个分块独立发送并按 ChunkConfig.Retry 重试，单个分块失败不会导致已上传的分块重新发送。
+// 所有分块都被服务端确认（返回 2xx）后发送完成请求，并返回其响应。
+// 上传失败时返回 *ChunkUploadError，其中的续传令牌记录了已确认的分块，
+// 之后的调用传入该令牌即可跳过这些分块。
+//
+// 参数:
+//   - ctx: 请求上下文，取消后停止上传
+//   - url: 上传地址
+//   - r: 待上传的数据，分块并发上传时会被并发读取
+//   - size: 数据总大小
+//   - cfg: 分块上传配置
+//
+// 返回值:
+//   - *Response: 完成请求的响应
+//   - error: *ChunkUploadError、ErrInvalidResumeToken 或完成请求的错误，如果成功则为 nil
+//
+// 示例:
+//
+//	file, _ := os.Open("/data/backup.tar")
+//	stat, _ := file.Stat()
+//	resp, err := UploadChunked(ctx, "https://storage.example.com/uploads/1", file, stat.Size(), ChunkConfig{
+//	    ChunkSize:   16 << 20,
+//	    Concurrency: 4,
+//	})
+//	var uploadErr *ChunkUploadError
+//	if errors.As(err, &uploadErr) {
+//	    // 保存 uploadErr.ResumeToken，下次通过 ChunkConfig.ResumeToken 继续上传
+//	}
+func UploadChunked(ctx context.Context, url string, r io.ReaderAt, size int64, cfg ChunkConfig) (*Response, error) {
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = DefaultChunkSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Protocol == nil {
+		cfg.Protocol = ContentRangeProtocol{}
+	}
+	if cfg.Retry == nil {
+		cfg.Retry = &RetryConfig{MaxRetries: DefaultChunkRetries}
+	}
+
+	state := resumeState{Size: size, ChunkSize: cfg.ChunkSize}
+	acked := make(map[int64]bool)
+	if cfg.ResumeToken != "" {
+		previous, err := decodeResumeToken(cfg.ResumeToken)
+		if err != nil {
+			return nil, err
+		}
+		if previous.Size != size || previous.ChunkSize != cfg.ChunkSize {
+			return nil, fmt.Errorf("%w: token is for size %d and chunk size %d", ErrInvalidResumeToken, previous.Size, previous.ChunkSize)
+		}
+		for _, index := range previous.Acked {
+			acked[index] = true
+		}
+	}
+
+	var pending []int64
+	for index := int64(0); index*cfg.ChunkSize < size; index++ {
+		if !acked[index] {
+			pending = append(pending, index)
+		}
+	}
+
+	if err := uploadChunks(ctx, url, r, size, &cfg, pending, acked); err != nil {
+		for index := range acked {
+			state.Acked = append(state.Acked, index)
+		}
+		slices.Sort(state.Acked)
+		return nil, &ChunkUploadError{ResumeToken: encodeResumeToken(state), Err: err}
+	}
+
+	method, target, opts := cfg.Protocol.Finalize(url, size)
+	res, err := Do(ctx, method, target, append([]RequestOption{WithHeaders(cfg.Header), WithRetry(*cfg.Retry)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	if StatusClass(res.StatusCode) != ClassSuccess {
+		return res, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return res, nil
+}
+
+// uploadChunks 并发上传 pending 中的分块，成功的分块记录到 acked 中，遇到第一个错误后停止调度
+func uploadChunks(ctx context.Context, url string, r io.ReaderAt, size int64, cfg *ChunkConfig, pending []int64, acked map[int64]bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	indexes := make(chan int64)
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				err := uploadChunk(ctx, url, r, size, cfg, index)
+				mu.Lock()
+				if err == nil {
+					acked[index] = true
+				} else if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for _, index := range pending {
+		select {
+		case indexes <- index:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+// uploadChunk 读取并上传单个分块
+func uploadChunk(ctx context.Context, url string, r io.ReaderAt, size int64, cfg *ChunkConfig, index int64) error {
+	offset := index * cfg.ChunkSize
+	chunk := make([]byte, min(cfg.ChunkSize, size-offset))
+	// ReadAt 读满分块时也可能返回 io.EOF
+	if n, err := r.ReadAt(chunk, offset); n < len(chunk) {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	method, target, opts := cfg.Protocol.Chunk(url, offset, chunk, size)
+	res, err := Do(ctx, method, target, append([]RequestOption{WithHeaders(cfg.Header), WithRetry(*cfg.Retry)}, opts...)...)
+	if err != nil {
+		return fmt.Errorf("chunk at offset %d: %w", offset, err)
+	}
+	if StatusClass(res.StatusCode) != ClassSuccess {
+		return fmt.Errorf("chunk at offset %d: unexpected status code: %d", offset, res.StatusCode)
+	}
+	return nil
+}
+
+// encodeResumeToken 将续传状态编码为令牌
+func encodeResumeToken(state resumeState) string {
+	data, _ := json.Marshal(state)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeResumeToken 解析续传令牌
+func decodeResumeToken(token string) (resumeState, error) {
+	var state resumeState
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &state)
+	}
+	if err != nil {
+		return resumeState{}, fmt.Errorf("%w: %v", ErrInvalidResumeToken, err)
+	}
+	return state, nil
+}
@@ -0,0 +1,128 @@
+package resty_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// chunkStore 模拟按 Content-Range 接收分块的存储服务
+type chunkStore struct {
+	t         *testing.T
+	mu        sync.Mutex
+	data      []byte
+	attempts  map[string]int
+	finalized int
+	// fail 返回 true 时丢弃该分块
+	fail func(contentRange string, attempt int) bool
+}
+
+func newChunkStore(t *testing.T, size int) *chunkStore {
+	return &chunkStore{t: t, data: make([]byte, size), attempts: make(map[string]int)}
+}
+
+func (s *chunkStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	contentRange := r.Header.Get("Content-Range")
+	if r.Method == http.MethodPost {
+		s.mu.Lock()
+		s.finalized++
+		s.mu.Unlock()
+		assert.Equal(s.t, fmt.Sprintf("bytes */%d", len(s.data)), contentRange)
+		_, _ = io.WriteString(w, `{"status":"complete"}`)
+		return
+	}
+
+	s.mu.Lock()
+	s.attempts[contentRange]++
+	attempt := s.attempts[contentRange]
+	s.mu.Unlock()
+	if s.fail != nil && s.fail(contentRange, attempt) {
+		// 模拟连接中断
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		_ = conn.Close()
+		return
+	}
+
+	var start, end, total int
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	copy(s.data[start:end+1], body)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestUploadChunked(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 105))
+	store := newChunkStore(t, len(content))
+	store.fail = func(contentRange string, attempt int) bool {
+		return contentRange == "bytes 300-399/1050" && attempt == 1
+	}
+	ts := httptest.NewServer(store)
+	defer ts.Close()
+
+	resp, err := UploadChunked(context.Background(), ts.URL, bytes.NewReader(content), int64(len(content)), ChunkConfig{
+		ChunkSize:   100,
+		Concurrency: 3,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte(`{"status":"complete"}`), resp.Body)
+	}
+	assert.Equal(t, content, store.data)
+	assert.Equal(t, 11, len(store.attempts))
+	assert.Equal(t, 2, store.attempts["bytes 300-399/1050"])
+	assert.Equal(t, 1, store.attempts["bytes 1000-1049/1050"])
+	assert.Equal(t, 1, store.finalized)
+}
+
+func TestUploadChunkedResume(t *testing.T) {
+	content := []byte(strings.Repeat("abcdefghij", 50))
+	store := newChunkStore(t, len(content))
+	var broken atomic.Bool
+	broken.Store(true)
+	store.fail = func(contentRange string, attempt int) bool {
+		return broken.Load() && contentRange == "bytes 200-299/500"
+	}
+	ts := httptest.NewServer(store)
+	defer ts.Close()
+
+	cfg := ChunkConfig{ChunkSize: 100, Retry: &RetryConfig{MaxRetries: 1}}
+	_, err := UploadChunked(context.Background(), ts.URL, bytes.NewReader(content), int64(len(content)), cfg)
+	var uploadErr *ChunkUploadError
+	if !assert.True(t, errors.As(err, &uploadErr)) {
+		return
+	}
+	assert.NotEmpty(t, uploadErr.ResumeToken)
+	assert.Equal(t, 0, store.finalized)
+
+	// 续传时只上传未确认的分块
+	broken.Store(false)
+	cfg.ResumeToken = uploadErr.ResumeToken
+	_, err = UploadChunked(context.Background(), ts.URL, bytes.NewReader(content), int64(len(content)), cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, content, store.data)
+	assert.Equal(t, 1, store.attempts["bytes 0-99/500"])
+	assert.Equal(t, 1, store.attempts["bytes 100-199/500"])
+	assert.Equal(t, 3, store.attempts["bytes 200-299/500"])
+	assert.Equal(t, 1, store.finalized)
+}
+
+func TestUploadChunkedInvalidToken(t *testing.T) {
+	content := []byte("data")
+	_, err := UploadChunked(context.Background(), "http://127.0.0.1:0", bytes.NewReader(content), 4, ChunkConfig{ResumeToken: "not-a-token"})
+	assert.ErrorIs(t, err, ErrInvalidResumeToken)
+}
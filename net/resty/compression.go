@@ -0,0 +1,72 @@
+package resty
+
+import (
+	"io"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultRawAcceptEncoding 原始压缩模式下未指定编码时默认声明的 Accept-Encoding
+const DefaultRawAcceptEncoding = "gzip"
+
+// WithRawCompression 保留上游压缩后的原始响应体，不做透明解压
+//
+// 默认情况下 Go 的 Transport 会自动声明 gzip 并在收到后解压、移除 Content-Encoding 响应头。
+// 开启后会设置 Transport 的 DisableCompression 并跳过 resty 的 gzip 解压，
+// 响应体保持服务端返回的原始字节，Response.ContentEncoding 和 Response.ContentLength
+// 为服务端返回的原始值，适用于代理透传场景。
+// 未通过 WithAcceptEncoding 或请求头指定 Accept-Encoding 时默认声明 DefaultRawAcceptEncoding。
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodGet, "https://upstream.example.com/data", WithRawCompression())
+//	w.Header().Set("Content-Encoding", resp.ContentEncoding)
+//	w.Write(resp.Body)
+func WithRawCompression() RequestOption {
+	return func(c *requestConfig) {
+		c.rawCompression = true
+	}
+}
+
+// WithAcceptEncoding 显式声明客户端接受的内容编码，例如 "gzip, br"
+//
+// 与 Go 的 Transport 行为一致，调用方显式协商编码后不会再做透明解压，
+// 因此会同时开启 WithRawCompression，响应体需要调用方根据 Response.ContentEncoding 自行解码。
+func WithAcceptEncoding(encoding string) RequestOption {
+	return func(c *requestConfig) {
+		c.rawCompression = true
+		c.acceptEncoding = encoding
+	}
+}
+
+// applyRawCompression 配置客户端和请求以获取未解压的原始响应体
+func applyRawCompression(client *resty.Client, req *resty.Request, cfg *requestConfig) error {
+	transport, err := client.Transport()
+	if err != nil {
+		return err
+	}
+	transport.DisableCompression = true
+
+	switch {
+	case cfg.acceptEncoding != "":
+		req.SetHeader("Accept-Encoding", cfg.acceptEncoding)
+	case req.Header.Get("Accept-Encoding") == "":
+		req.SetHeader("Accept-Encoding", DefaultRawAcceptEncoding)
+	}
+
+	// resty 会自动解压 gzip 响应体，这里改为自行读取原始响应体
+	req.SetDoNotParseResponse(true)
+	return nil
+}
+
+// readRawBody 读取未经解析的原始响应体并关闭
+func readRawBody(res *resty.Response) (*resty.Response, error) {
+	body := res.RawBody()
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return res, err
+	}
+	return res.SetBody(data), nil
+}
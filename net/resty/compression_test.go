@@ -0,0 +1,84 @@
+package resty_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// gzipServer 在客户端声明支持 gzip 时返回压缩后的响应体
+func gzipServer(t *testing.T, body string) (*httptest.Server, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(gz, body); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	compressed := buf.Bytes()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = io.WriteString(w, body)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Length", strconv.Itoa(len(compressed)))
+		_, _ = w.Write(compressed)
+	}))
+	return ts, compressed
+}
+
+func TestDefaultCompression(t *testing.T) {
+	ts, _ := gzipServer(t, `{"status":"ok"}`)
+	defer ts.Close()
+
+	// 默认行为：透明解压
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp.Body)
+	assert.Empty(t, resp.ContentEncoding)
+}
+
+func TestWithRawCompression(t *testing.T) {
+	ts, compressed := gzipServer(t, `{"status":"ok"}`)
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL, WithRawCompression())
+	assert.NoError(t, err)
+	assert.Equal(t, compressed, resp.Body, "body should remain gzipped")
+	assert.Equal(t, "gzip", resp.ContentEncoding)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, int64(len(compressed)), resp.ContentLength)
+
+	gz, err := gzip.NewReader(bytes.NewReader(resp.Body))
+	assert.NoError(t, err)
+	plain, err := io.ReadAll(gz)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":"ok"}`, string(plain))
+}
+
+func TestWithAcceptEncoding(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip, br", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "br")
+		_, _ = io.WriteString(w, "raw-br-bytes")
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL, WithAcceptEncoding("gzip, br"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("raw-br-bytes"), resp.Body)
+	assert.Equal(t, "br", resp.ContentEncoding)
+}
@@ -0,0 +1,42 @@
+package resty
+
+import (
+	"context"
+	"net/http"
+)
+
+// PutIfMatch 发送携带 If-Match 的 PUT 请求，用于乐观并发控制
+//
+// 资源的当前 ETag 与 etag 不一致时服务端返回 412 Precondition Failed，
+// 此时 conflict 为 true 而不是返回错误，调用方应重新读取资源后再次提交。
+// 与其他便捷函数一致，其他非 2xx 状态码不会作为错误返回。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - body: 请求体内容，可以是任意类型
+//   - etag: 读取资源时得到的 ETag，原样写入 If-Match（包括引号和 W/ 前缀）
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - bool: 是否因 ETag 不一致而被拒绝（412）
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	info, _ := RemoteFileInfo("https://api.example.com/docs/1", nil)
+//	resp, conflict, err := PutIfMatch("https://api.example.com/docs/1", doc, info.ETag, nil)
+//	if conflict {
+//	    // 资源已被其他人修改，重新读取后重试
+//	}
+func PutIfMatch(url string, body interface{}, etag string, header map[string]string) ([]byte, bool, error) {
+	res, err := Do(context.Background(), http.MethodPut, url,
+		WithHeaders(header),
+		WithHeaders(map[string]string{"If-Match": etag}),
+		WithBody(body),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	return res.Body, res.StatusCode == http.StatusPreconditionFailed, nil
+}
@@ -0,0 +1,45 @@
+package resty_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestPutIfMatch(t *testing.T) {
+	current := `"v2"`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name":"test"}`, string(body))
+
+		if r.Header.Get("If-Match") != current {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = io.WriteString(w, `{"error":"etag mismatch"}`)
+			return
+		}
+		w.Header().Set("ETag", `"v3"`)
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	body := map[string]string{"name": "test"}
+
+	resp, conflict, err := PutIfMatch(ts.URL, body, `"v2"`, headers)
+	assert.NoError(t, err)
+	assert.False(t, conflict)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+
+	resp, conflict, err = PutIfMatch(ts.URL, body, `"v1"`, headers)
+	assert.NoError(t, err)
+	assert.True(t, conflict)
+	assert.Equal(t, []byte(`{"error":"etag mismatch"}`), resp)
+}
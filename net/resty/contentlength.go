@@ -0,0 +1,71 @@
+package resty
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrContentLengthMismatch 响应体的实际长度与 Content-Length 声明的长度不一致
+var ErrContentLengthMismatch = errors.New("response body length does not match Content-Length")
+
+var strictContentLength atomic.Bool
+
+// SetStrictContentLength 设置是否严格校验响应体长度，默认关闭
+//
+// 开启后，包内客户端读取的响应体长度与 Content-Length 不一致时返回 ErrContentLengthMismatch，
+// 包括服务端在发送完声明的长度之前关闭连接的情况（否则只会得到 io.ErrUnexpectedEOF）。
+// 被透明解压的响应没有可比较的长度，不做校验。
+//
+// 注意:
+//   - net/http 只读取 Content-Length 声明的字节数，多出的数据不会交给调用方，
+//     Transport 会在发现连接上有多余数据时丢弃该连接，因此这种情况不会报错，也不会污染后续请求
+//
+// 示例:
+//
+//	SetStrictContentLength(true)
+//	_, err := Get("https://flaky.example.com/data")
+//	if errors.Is(err, ErrContentLengthMismatch) {
+//	    // 响应被截断
+//	}
+func SetStrictContentLength(strict bool) {
+	strictContentLength.Store(strict)
+}
+
+// checkContentLength 在 resty 读取响应体后校验长度，注册为 OnAfterResponse 钩子
+func checkContentLength(_ *resty.Client, res *resty.Response) error {
+	if !strictContentLength.Load() {
+		return nil
+	}
+	return verifyContentLength(res)
+}
+
+// verifyContentLength 比较已读取的响应体长度与声明的 Content-Length
+func verifyContentLength(res *resty.Response) error {
+	if res == nil || res.RawResponse == nil || res.RawResponse.Uncompressed {
+		return nil
+	}
+	declared := res.RawResponse.ContentLength
+	if declared < 0 || res.Request.Method == http.MethodHead {
+		return nil
+	}
+	if received := int64(len(res.Body())); received != declared {
+		return fmt.Errorf("%w: declared %d bytes, received %d", ErrContentLengthMismatch, declared, received)
+	}
+	return nil
+}
+
+// contentLengthError 开启严格校验时，将响应体提前结束的错误转换为 ErrContentLengthMismatch
+func contentLengthError(res *resty.Response, err error) error {
+	if err == nil || !strictContentLength.Load() || !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	if res == nil || res.RawResponse == nil || res.RawResponse.ContentLength < 0 {
+		return err
+	}
+	return fmt.Errorf("%w: declared %d bytes, received %d: %w", ErrContentLengthMismatch, res.RawResponse.ContentLength, len(res.Body()), err)
+}
@@ -0,0 +1,65 @@
+package resty_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// shortBodyServer 声明 10 字节的 Content-Length，只发送 5 字节后关闭连接
+func shortBodyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 10\r\n\r\nhello")
+		_ = buf.Flush()
+	}))
+}
+
+func TestSetStrictContentLength(t *testing.T) {
+	ts := shortBodyServer()
+	defer ts.Close()
+
+	_, err := Get(ts.URL)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.NotErrorIs(t, err, ErrContentLengthMismatch, "strict mode is opt-in")
+
+	SetStrictContentLength(true)
+	t.Cleanup(func() { SetStrictContentLength(false) })
+
+	_, err = Get(ts.URL)
+	assert.ErrorIs(t, err, ErrContentLengthMismatch)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.ErrorContains(t, err, "declared 10 bytes, received 5")
+
+	// 未经 resty 解析的响应体同样校验
+	_, err = Do(context.Background(), http.MethodGet, ts.URL, WithRawCompression())
+	assert.ErrorIs(t, err, ErrContentLengthMismatch)
+}
+
+func TestSetStrictContentLengthValid(t *testing.T) {
+	SetStrictContentLength(true)
+	t.Cleanup(func() { SetStrictContentLength(false) })
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "15")
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	resp, err := Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+
+	info, err := RemoteFileInfo(ts.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), info.Size)
+}
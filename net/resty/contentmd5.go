@@ -0,0 +1,71 @@
+package resty
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ContentMD5 请求头的 Content-MD5 字段名
+const ContentMD5 = "Content-MD5"
+
+// ErrContentMD5Mismatch 调用方设置的 Content-MD5 与请求体计算出的摘要不一致
+var ErrContentMD5Mismatch = errors.New("content-md5 mismatch")
+
+// WithContentMD5 根据请求体计算 Content-MD5 请求头（base64 编码的 MD5 摘要）
+//
+// 请求体必须是 []byte、string 或 io.ReadSeeker；io.ReadSeeker 会先完整读取一遍计算摘要，
+// 再重置到开头发送。其他类型的请求体在发送前返回错误。
+// 调用方已设置的 Content-MD5 与计算结果不一致时，请求不会发送，返回 ErrContentMD5Mismatch。
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodPut, "https://storage.example.com/bucket/key",
+//	    WithBody(data),
+//	    WithContentMD5(),
+//	)
+func WithContentMD5() RequestOption {
+	return func(c *requestConfig) {
+		c.contentMD5 = true
+	}
+}
+
+// WithContentMD5Body 设置可 Seek 的流式请求体，并计算 Content-MD5 请求头
+//
+// 与 WithBody(body) 加 WithContentMD5() 等价，通过参数类型保证请求体可以在计算摘要后重置。
+func WithContentMD5Body(body io.ReadSeeker) RequestOption {
+	return func(c *requestConfig) {
+		c.body = body
+		c.contentMD5 = true
+	}
+}
+
+// bodyContentMD5 计算请求体的 Content-MD5，并校验调用方设置的值
+func (c *requestConfig) bodyContentMD5() (string, error) {
+	hash := md5.New()
+	switch body := c.body.(type) {
+	case []byte:
+		hash.Write(body)
+	case string:
+		_, _ = io.WriteString(hash, body)
+	case io.ReadSeeker:
+		if _, err := io.Copy(hash, body); err != nil {
+			return "", err
+		}
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("content-md5 requires a []byte, string or io.ReadSeeker body, got %T", c.body)
+	}
+
+	sum := base64.StdEncoding.EncodeToString(hash.Sum(nil))
+	// WithHeaders 已将 header 名称规范化
+	if v, ok := c.header[http.CanonicalHeaderKey(ContentMD5)]; ok && v != sum {
+		return "", fmt.Errorf("%w: header %s, computed %s", ErrContentMD5Mismatch, v, sum)
+	}
+	return sum, nil
+}
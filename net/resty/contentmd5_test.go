@@ -0,0 +1,94 @@
+package resty_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// md5Server 校验 Content-MD5 请求头与实际收到的请求体一致
+func md5Server(t *testing.T, count *int64) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(count, 1)
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := md5.Sum(body)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), r.Header.Get("Content-MD5"))
+		assert.NotEmpty(t, body)
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestWithContentMD5(t *testing.T) {
+	var count int64
+	ts := md5Server(t, &count)
+	defer ts.Close()
+
+	tests := []struct {
+		name string
+		opts []RequestOption
+	}{
+		{name: "bytes", opts: []RequestOption{WithBody([]byte("byte body")), WithContentMD5()}},
+		{name: "string", opts: []RequestOption{WithContentMD5(), WithBody("string body")}},
+		{name: "seeker", opts: []RequestOption{WithBody(strings.NewReader("seeker body")), WithContentMD5()}},
+		{name: "streaming", opts: []RequestOption{WithContentMD5Body(bytes.NewReader(bytes.Repeat([]byte("x"), 1<<16)))}},
+		{name: "matching header", opts: []RequestOption{
+			WithHeaders(map[string]string{"content-md5": "6ZoYxCjLONXyYIU2eJIuAw=="}),
+			WithBody("abc123"),
+			WithContentMD5(),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := Do(context.Background(), http.MethodPut, ts.URL, tt.opts...)
+			if assert.NoError(t, err) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+		})
+	}
+	assert.Equal(t, int64(len(tests)), atomic.LoadInt64(&count))
+}
+
+func TestWithContentMD5Rejected(t *testing.T) {
+	var count int64
+	ts := md5Server(t, &count)
+	defer ts.Close()
+
+	// 调用方设置的值与计算结果不一致
+	_, err := Do(context.Background(), http.MethodPut, ts.URL,
+		WithHeaders(map[string]string{"Content-MD5": "AAAAAAAAAAAAAAAAAAAAAA=="}),
+		WithBody("abc123"),
+		WithContentMD5(),
+	)
+	assert.ErrorIs(t, err, ErrContentMD5Mismatch)
+
+	// 不支持的请求体类型
+	_, err = Do(context.Background(), http.MethodPut, ts.URL,
+		WithBody(map[string]string{"name": "test"}),
+		WithContentMD5(),
+	)
+	assert.Error(t, err)
+
+	// 不可 Seek 的流式请求体
+	_, err = Do(context.Background(), http.MethodPut, ts.URL,
+		WithBody(io.MultiReader(strings.NewReader("stream"))),
+		WithContentMD5(),
+	)
+	assert.Error(t, err)
+
+	assert.Equal(t, int64(0), atomic.LoadInt64(&count), "rejected requests should not be sent")
+}
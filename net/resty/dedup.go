@@ -0,0 +1,84 @@
+package resty
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// dedupGroup 合并并发的相同 GET 请求
+var dedupGroup singleflight.Group
+
+// GetDeduped 发送 GET 请求，并发的相同请求共享同一次调用
+//
+// 同一时刻 url 和 header 都相同的请求只会真正发送一次，其余调用方等待并共享其结果，
+// 适用于缓存击穿时大量请求同时访问开销较大的幂等接口。
+// header 也是合并条件的一部分，携带不同认证信息的请求不会共享响应。
+// 结果只在这一次调用期间共享，调用结束后（包括失败）下一次请求会重新发送，错误不会被缓存。
+//
+// 共享的请求不会因为某一个调用方的 ctx 取消而中止，ctx 取消时只有该调用方提前返回 ctx 的错误；
+// 请求本身的超时时间为 DefaultTimeout。
+//
+// 参数:
+//   - ctx: 请求上下文，决定调用方最多等待多久
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组，每个调用方得到独立的副本
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	resp, err := GetDeduped(ctx, "https://api.example.com/config", nil)
+func GetDeduped(ctx context.Context, url string, header map[string]string) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// 共享请求保留 ctx 中的值（如 rpc headers），但不受单个调用方取消的影响
+	flightCtx := context.WithoutCancel(ctx)
+	ch := dedupGroup.DoChan(dedupKey(url, header), func() (interface{}, error) {
+		res, err := Do(flightCtx, http.MethodGet, url, WithHeaders(header))
+		if err != nil {
+			return nil, err
+		}
+		return res.Body, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		body := result.Val.([]byte)
+		if result.Shared {
+			body = bytes.Clone(body)
+		}
+		return body, nil
+	}
+}
+
+// dedupKey 根据 url 和按名称排序的 header 生成合并请求的键
+func dedupKey(url string, header map[string]string) string {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(url)
+	for _, k := range keys {
+		b.WriteString("\n")
+		b.WriteString(http.CanonicalHeaderKey(k))
+		b.WriteString(": ")
+		b.WriteString(header[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package resty_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetDeduped(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([][]byte, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := GetDeduped(context.Background(), ts.URL, map[string]string{"Authorization": "test-token"})
+			assert.NoError(t, err)
+			results[i] = resp
+		}(i)
+	}
+	// 等待所有调用方进入同一次请求
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, resp := range results {
+		assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	}
+	// 每个调用方得到独立的副本
+	results[0][0] = 'x'
+	assert.Equal(t, byte('{'), results[1][0])
+}
+
+func TestGetDedupedDifferentHeaders(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+
+	var wg sync.WaitGroup
+	for _, token := range []string{"alice", "bob"} {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			resp, err := GetDeduped(context.Background(), ts.URL, map[string]string{"Authorization": token})
+			assert.NoError(t, err)
+			assert.Equal(t, token, string(resp))
+		}(token)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestGetDedupedErrorNotCached(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次请求断开连接，之后正常返回
+		if calls.Add(1) == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer ts.Close()
+
+	_, err := GetDeduped(context.Background(), ts.URL, nil)
+	assert.Error(t, err)
+
+	resp, err := GetDeduped(context.Background(), ts.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ok"), resp)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestGetDedupedCanceled(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := GetDeduped(ctx, ts.URL, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,97 @@
+package resty
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var (
+	defaultQueryMutex  sync.RWMutex
+	defaultQueryParams map[string]string
+)
+
+// SetDefaultQueryParams 设置全局的默认查询参数，传入 nil 或空 map 时取消默认参数
+//
+// 默认参数对包内创建的所有客户端生效（包括 GetRequest 返回的请求和 PostChannel 等流式接口），
+// 适合集中固定 api-version 等每个请求都需要携带的参数。
+// 请求地址中已有的参数和通过 WithQuery、SetQueryParam 设置的同名参数优先于默认参数，
+// 默认参数只在请求没有该参数时追加。参数名和值会按查询字符串的规则编码，调用方传入未编码的原始值即可。
+// 默认参数在签名函数之前添加，签名时可以看到完整的查询参数。
+//
+// 参数:
+//   - params: 默认查询参数，传入的 map 会被复制，之后的修改不会影响已设置的默认参数
+//
+// 示例:
+//
+//	SetDefaultQueryParams(map[string]string{"api-version": "2"})
+//	// 实际请求 https://api.example.com/users?api-version=2
+//	resp, err := Get("https://api.example.com/users")
+//	// 显式指定的参数覆盖默认值，实际请求 https://api.example.com/users?api-version=3
+//	resp, err = Get("https://api.example.com/users?api-version=3")
+func SetDefaultQueryParams(params map[string]string) {
+	var copied map[string]string
+	if len(params) > 0 {
+		copied = make(map[string]string, len(params))
+		for k, v := range params {
+			copied[k] = v
+		}
+	}
+
+	defaultQueryMutex.Lock()
+	defer defaultQueryMutex.Unlock()
+	defaultQueryParams = copied
+}
+
+// loadDefaultQueryParams 返回当前的默认查询参数，返回的 map 不可修改
+func loadDefaultQueryParams() map[string]string {
+	defaultQueryMutex.RLock()
+	defer defaultQueryMutex.RUnlock()
+	return defaultQueryParams
+}
+
+// applyDefaultQuery 在客户端的 OnBeforeRequest 阶段为请求补充默认查询参数
+func applyDefaultQuery(_ *resty.Client, r *resty.Request) error {
+	params := loadDefaultQueryParams()
+	if len(params) == 0 {
+		return nil
+	}
+	// 地址无法解析时交给 resty 报告错误
+	existing := url.Values{}
+	if u, err := url.Parse(r.URL); err == nil {
+		existing = u.Query()
+	}
+	for k, v := range params {
+		if existing.Has(k) || r.QueryParam.Has(k) {
+			continue
+		}
+		r.QueryParam.Set(k, v)
+	}
+	return nil
+}
+
+// applyDefaultQueryToRequest 为绕过 resty 请求流程的流式请求补充默认查询参数
+func applyDefaultQueryToRequest(req *http.Request) {
+	params := loadDefaultQueryParams()
+	if len(params) == 0 {
+		return
+	}
+	query := req.URL.Query()
+	added := url.Values{}
+	for k, v := range params {
+		if !query.Has(k) {
+			added.Set(k, v)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	// 追加而不是重新编码已有参数，保留调用方原始的编码和顺序
+	if req.URL.RawQuery == "" {
+		req.URL.RawQuery = added.Encode()
+	} else {
+		req.URL.RawQuery += "&" + added.Encode()
+	}
+}
@@ -0,0 +1,81 @@
+package resty_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// queryEchoServer 返回收到的原始查询字符串
+func queryEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.RawQuery))
+	}))
+}
+
+func TestSetDefaultQueryParams(t *testing.T) {
+	ts := queryEchoServer()
+	defer ts.Close()
+
+	SetDefaultQueryParams(map[string]string{"api-version": "2", "scope": "a b&c"})
+	t.Cleanup(func() { SetDefaultQueryParams(nil) })
+
+	tests := []struct {
+		name     string
+		url      string
+		opts     []RequestOption
+		expected string
+	}{
+		{"defaults", ts.URL, nil, "api-version=2&scope=a+b%26c"},
+		{"url query overrides", ts.URL + "?api-version=3", nil, "api-version=3&scope=a+b%26c"},
+		{"option overrides", ts.URL, []RequestOption{WithQuery(map[string]string{"api-version": "4"})}, "api-version=4&scope=a+b%26c"},
+		{"keeps other params", ts.URL + "?q=x", []RequestOption{WithQuery(map[string]string{"page": "1"})}, "q=x&api-version=2&page=1&scope=a+b%26c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := Do(context.Background(), http.MethodGet, tt.url, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(resp.Body))
+		})
+	}
+
+	// GetRequest 返回的请求同样携带默认参数，请求级参数优先
+	res, err := GetRequest(DefaultTimeout).SetQueryParam("scope", "own").Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "api-version=2&scope=own", res.String())
+
+	// 流式接口同样携带默认参数
+	resp, err := PostChannel(context.Background(), ts.URL+"?api-version=5", closedLines(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "api-version=5&scope=a+b%26c", string(resp))
+}
+
+func TestSetDefaultQueryParamsCopiesAndClears(t *testing.T) {
+	ts := queryEchoServer()
+	defer ts.Close()
+
+	params := map[string]string{"api-version": "2"}
+	SetDefaultQueryParams(params)
+	t.Cleanup(func() { SetDefaultQueryParams(nil) })
+	params["api-version"] = "9"
+
+	resp, err := Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "api-version=2", string(resp))
+
+	SetDefaultQueryParams(nil)
+	resp, err = Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Empty(t, string(resp))
+}
+
+// closedLines 返回已关闭的空 channel
+func closedLines() <-chan []byte {
+	lines := make(chan []byte)
+	close(lines)
+	return lines
+}
@@ -0,0 +1,55 @@
+package resty
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// WithDialer 使用自定义的 net.Dialer 建立连接，用于调整连接超时和 TCP keep-alive 等参数
+//
+// 每个请求使用独立的 Transport，设置只作用于本次请求，不会影响其他请求的连接；
+// 也因此连接不会在请求之间复用。与 WithSOCKS5 同时使用时，该 dialer 用于连接 SOCKS5 代理。
+//
+// 示例:
+//
+//	dialer := &net.Dialer{Timeout: 3 * time.Second, KeepAlive: 5 * time.Second}
+//	resp, err := Do(ctx, http.MethodGet, "https://stream.example.com/events", WithDialer(dialer))
+func WithDialer(dialer *net.Dialer) RequestOption {
+	return func(c *requestConfig) {
+		c.dialer = dialer
+	}
+}
+
+// GetWithDialer 使用自定义的 net.Dialer 发送 HTTP GET 请求
+//
+// 请求使用独立的 Transport 建立连接，不会修改共享的客户端配置，
+// 适合个别需要更激进的 keep-alive 探测以发现失效对端的长连接接口。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - dialer: 建立连接使用的 dialer，可设置 Timeout、KeepAlive 等，为 nil 时使用默认配置
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	dialer := &net.Dialer{Timeout: 3 * time.Second, KeepAlive: 5 * time.Second}
+//	resp, err := GetWithDialer("https://stream.example.com/status", dialer, nil)
+func GetWithDialer(url string, dialer *net.Dialer, header map[string]string) ([]byte, error) {
+	return doBody(http.MethodGet, url, WithHeaders(header), WithDialer(dialer))
+}
+
+// applyDialer 将客户端 Transport 的拨号替换为 dialer
+func applyDialer(client *resty.Client, dialer *net.Dialer) error {
+	transport, err := client.Transport()
+	if err != nil {
+		return err
+	}
+	transport.DialContext = dialer.DialContext
+	return nil
+}
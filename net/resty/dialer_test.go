@@ -0,0 +1,84 @@
+package resty_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// recordingDialer 返回一个记录拨号地址的 dialer
+func recordingDialer() (*net.Dialer, func() []string) {
+	var (
+		mu    sync.Mutex
+		addrs []string
+	)
+	dialer := &net.Dialer{
+		Timeout:   time.Second,
+		KeepAlive: 5 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			mu.Lock()
+			defer mu.Unlock()
+			addrs = append(addrs, address)
+			return nil
+		},
+	}
+	return dialer, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), addrs...)
+	}
+}
+
+func TestGetWithDialer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	dialer, dialed := recordingDialer()
+	resp, err := GetWithDialer(ts.URL, dialer, map[string]string{"Authorization": "test-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	assert.Equal(t, []string{ts.Listener.Addr().String()}, dialed())
+
+	// dialer 拒绝连接时请求失败
+	errDial := errors.New("dial refused")
+	refusing := &net.Dialer{Control: func(network, address string, c syscall.RawConn) error {
+		return errDial
+	}}
+	_, err = GetWithDialer(ts.URL, refusing, nil)
+	assert.ErrorIs(t, err, errDial)
+
+	// 为 nil 时使用默认配置
+	resp, err = GetWithDialer(ts.URL, nil, map[string]string{"Authorization": "test-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+}
+
+func TestWithDialerSOCKS5(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer ts.Close()
+
+	socks := newSOCKS5Server(t, "", "", nil)
+	dialer, dialed := recordingDialer()
+	res, err := Do(context.Background(), http.MethodGet, ts.URL, WithSOCKS5(socks.listener.Addr().String(), nil), WithDialer(dialer))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ok"), res.Body)
+
+	// dialer 用于连接 SOCKS5 代理，目标地址由代理连接
+	assert.Equal(t, []string{socks.listener.Addr().String()}, dialed())
+	assert.Equal(t, []string{ts.Listener.Addr().String()}, socks.requested)
+}
@@ -0,0 +1,81 @@
+package resty
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDownloadFileName 无法从响应和地址中得到文件名时使用的文件名
+const DefaultDownloadFileName = "download"
+
+// DownloadToDir 下载文件到目录中，文件名由服务端建议
+//
+// 文件名优先取 Content-Disposition 的 filename* （RFC 5987，支持 UTF-8 等编码）或 filename 参数，
+// 没有时取请求地址路径的最后一段，仍然没有时使用 DefaultDownloadFileName。
+// 文件名中的路径分隔符会被去除，"."、".." 等无效文件名会被替换，服务端无法将文件写到 dir 之外。
+// 同名文件已存在时默认为新文件追加数字后缀（如 "report-1.pdf"），WithOverwrite(true) 时覆盖。
+// 文件的写入方式与 DownloadToFile 相同。
+//
+// 参数:
+//   - url: 目标文件地址
+//   - dir: 保存文件的目录，必须已经存在
+//   - header: 自定义的 HTTP 请求头
+//   - opts: 下载选项，目前只有 WithIdleReadTimeout 和 WithOverwrite 生效
+//
+// 返回值:
+//   - savedPath: 文件实际保存的路径
+//   - err: 请求错误、非 2xx 状态码、写入错误或 *StalledError，如果成功则为 nil
+//
+// 示例:
+//
+//	path, err := DownloadToDir("https://example.com/reports/latest", "/data/reports", nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println("saved to", path)
+func DownloadToDir(url, dir string, header map[string]string, opts ...RequestOption) (savedPath string, err error) {
+	cfg := newRequestConfig(opts...)
+	return downloadFile(context.Background(), url, "", header, cfg, cfg.existingPolicy(renameNew), func(res *http.Response) string {
+		return filepath.Join(dir, downloadFileName(res))
+	})
+}
+
+// downloadFileName 根据 Content-Disposition 或请求地址得到安全的文件名
+func downloadFileName(res *http.Response) string {
+	if _, params, err := mime.ParseMediaType(res.Header.Get("Content-Disposition")); err == nil {
+		// mime.ParseMediaType 会将 filename* 解码后放入 filename
+		if name := sanitizeFileName(params["filename"]); name != "" {
+			return name
+		}
+	}
+	if res.Request != nil && res.Request.URL != nil {
+		// URL.Path 已经过百分号解码
+		if name := sanitizeFileName(path.Base(res.Request.URL.Path)); name != "" {
+			return name
+		}
+	}
+	return DefaultDownloadFileName
+}
+
+// sanitizeFileName 去除文件名中的路径和控制字符，无法作为文件名时返回空字符串
+func sanitizeFileName(name string) string {
+	// 同时按两种分隔符取最后一段，避免 Windows 风格的路径绕过
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Trim(name, ".") == "" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,88 @@
+package resty_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestDownloadToDir(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if disposition := r.URL.Query().Get("cd"); disposition != "" {
+			w.Header().Set("Content-Disposition", disposition)
+		}
+		_, _ = io.WriteString(w, "content")
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		cd       string
+		expected string
+	}{
+		{"plain", "/files/1", `attachment; filename="report.pdf"`, "report.pdf"},
+		{"utf-8", "/files/1", `attachment; filename="fallback.txt"; filename*=UTF-8''%E6%8A%A5%E5%91%8A.txt`, "报告.txt"},
+		{"traversal", "/files/1", `attachment; filename="../../etc/passwd"`, "passwd"},
+		{"windows traversal", "/files/1", `attachment; filename="..\\..\\evil.exe"`, "evil.exe"},
+		{"dot dot", "/files/archive.zip", `attachment; filename=".."`, "archive.zip"},
+		{"url fallback", "/files/data.csv", "", "data.csv"},
+		{"default", "/", "", DefaultDownloadFileName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			target := ts.URL + tt.path
+			if tt.cd != "" {
+				target += "?cd=" + url.QueryEscape(tt.cd)
+			}
+
+			savedPath, err := DownloadToDir(target, dir, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tt.expected), savedPath)
+			data, err := os.ReadFile(savedPath)
+			assert.NoError(t, err)
+			assert.Equal(t, "content", string(data))
+		})
+	}
+}
+
+func TestDownloadToDirCollision(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="report.pdf"`)
+		_, _ = io.WriteString(w, "new")
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "report.pdf")
+	assert.NoError(t, os.WriteFile(existing, []byte("old"), 0o644))
+
+	savedPath, err := DownloadToDir(ts.URL, dir, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "report-1.pdf"), savedPath)
+
+	savedPath, err = DownloadToDir(ts.URL, dir, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "report-2.pdf"), savedPath)
+
+	data, err := os.ReadFile(existing)
+	assert.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+
+	// 覆盖已存在的文件
+	savedPath, err = DownloadToDir(ts.URL, dir, nil, WithOverwrite(true))
+	assert.NoError(t, err)
+	assert.Equal(t, existing, savedPath)
+	data, err = os.ReadFile(existing)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	assertNoTempFiles(t, dir)
+}
@@ -0,0 +1,336 @@
+package resty
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrChecksumMismatch 下载内容的校验和与期望值不一致
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// FileInfo 远程文件的元信息
+type FileInfo struct {
+	// Size 文件大小（字节），未知时为 0
+	Size int64
+	// LastModified 最后修改时间，未知时为零值
+	LastModified time.Time
+	// ETag 实体标签，未知时为空
+	ETag string
+	// AcceptRanges 服务端是否支持范围请求
+	AcceptRanges bool
+	// ContentType 文件的 Content-Type
+	ContentType string
+}
+
+// RemoteFileInfo 获取远程文件的元信息，用于在下载前判断是否需要下载
+//
+// 优先发送 HEAD 请求；当服务端拒绝 HEAD（405 或 501）时，
+// 退化为携带 Range: bytes=0-0 的 GET 请求，并从 Content-Range 中解析文件总大小。
+// 响应中缺失的头信息以零值返回，不视为错误。
+//
+// 参数:
+//   - url: 目标文件地址
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - FileInfo: 远程文件的元信息
+//   - error: 请求错误或非 2xx 状态码，如果成功则为 nil
+//
+// 示例:
+//
+//	info, err := RemoteFileInfo("https://example.com/file.zip", nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(info.Size, info.ETag)
+func RemoteFileInfo(url string, header map[string]string) (FileInfo, error) {
+	res, err := Do(context.Background(), http.MethodHead, url, WithHeaders(header))
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	status := res.StatusCode
+	if status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented {
+		return remoteFileInfoByRange(url, header)
+	}
+	if StatusClass(status) != ClassSuccess {
+		return FileInfo{}, fmt.Errorf("unexpected status code: %d", status)
+	}
+
+	info := parseFileInfo(res.Header)
+	if res.ContentLength > 0 {
+		info.Size = res.ContentLength
+	}
+	return info, nil
+}
+
+// remoteFileInfoByRange 通过 Range: bytes=0-0 的 GET 请求获取文件元信息
+func remoteFileInfoByRange(url string, header map[string]string) (FileInfo, error) {
+	res, err := DoRaw(context.Background(), http.MethodGet, url,
+		WithHeaders(header),
+		WithHeaders(map[string]string{"Range": "bytes=0-0"}),
+	)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	// 只关心响应头，响应体最多一个字节，关闭时丢弃后复用连接
+	defer res.Body.Close()
+
+	info := parseFileInfo(res.Header)
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		// 服务端处理了范围请求，说明支持 Range
+		info.AcceptRanges = true
+		if total, ok := parseContentRangeTotal(res.Header.Get("Content-Range")); ok {
+			info.Size = total
+		}
+	case http.StatusOK:
+		// 服务端忽略了 Range，返回了完整内容
+		if res.ContentLength > 0 {
+			info.Size = res.ContentLength
+		}
+	default:
+		return FileInfo{}, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return info, nil
+}
+
+// parseFileInfo 从响应头中解析除大小以外的文件元信息
+func parseFileInfo(header http.Header) FileInfo {
+	info := FileInfo{
+		ETag:         header.Get("ETag"),
+		AcceptRanges: strings.EqualFold(strings.TrimSpace(header.Get("Accept-Ranges")), "bytes"),
+		ContentType:  header.Get(ContentType),
+	}
+	if lastModified := header.Get("Last-Modified"); lastModified != "" {
+		if t, err := http.ParseTime(lastModified); err == nil {
+			info.LastModified = t
+		}
+	}
+	return info
+}
+
+// parseContentRangeTotal 解析 Content-Range 头中的总大小，例如 "bytes 0-0/1234"
+func parseContentRangeTotal(contentRange string) (int64, bool) {
+	idx := strings.LastIndex(contentRange, "/")
+	if idx < 0 {
+		return 0, false
+	}
+	total, err := strconv.ParseInt(strings.TrimSpace(contentRange[idx+1:]), 10, 64)
+	if err != nil || total < 0 {
+		return 0, false
+	}
+	return total, true
+}
+
+// WithOverwrite 设置下载目标文件已存在时是否覆盖
+//
+// DownloadToFile 和 DownloadVerified 默认覆盖，设置为 false 时目标文件已存在会返回 *os.PathError，
+// 可通过 errors.Is(err, os.ErrExist) 判断；DownloadToDir 默认为新文件名追加数字后缀，设置为 true 时覆盖。
+func WithOverwrite(overwrite bool) RequestOption {
+	return func(c *requestConfig) {
+		c.overwrite = &overwrite
+	}
+}
+
+// existingFilePolicy 保存下载文件时目标文件已存在的处理方式
+type existingFilePolicy int
+
+const (
+	// replaceExisting 覆盖已存在的文件
+	replaceExisting existingFilePolicy = iota
+	// rejectExisting 返回 os.ErrExist
+	rejectExisting
+	// renameNew 为新文件名追加数字后缀
+	renameNew
+)
+
+// existingPolicy 根据 WithOverwrite 的设置返回处理方式，未设置时使用 fallback
+func (c *requestConfig) existingPolicy(fallback existingFilePolicy) existingFilePolicy {
+	switch {
+	case c.overwrite == nil:
+		return fallback
+	case *c.overwrite:
+		return replaceExisting
+	default:
+		return rejectExisting
+	}
+}
+
+// DownloadToFile 下载文件到本地
+//
+// 响应体先写入目标目录下的临时文件，完整写入并 fsync 后再原子地重命名为目标文件，
+// 下载失败时删除临时文件，目标文件要么不存在，要么保持下载前的完整内容，不会出现写了一半的文件。
+// 下载不设置超时，适合大文件，可以通过 WithIdleReadTimeout 在服务端停止发送数据时提前中止。
+//
+// 参数:
+//   - url: 目标文件地址
+//   - destPath: 本地保存路径
+//   - header: 自定义的 HTTP 请求头
+//   - opts: 下载选项，目前只有 WithIdleReadTimeout 和 WithOverwrite 生效
+//
+// 返回值:
+//   - error: 请求错误、非 2xx 状态码、写入错误、*StalledError 或目标文件已存在的错误，如果成功则为 nil
+//
+// 示例:
+//
+//	err := DownloadToFile("https://example.com/app.tar.gz", "/data/app.tar.gz", nil, WithOverwrite(false))
+//	if errors.Is(err, os.ErrExist) {
+//	    log.Println("already downloaded")
+//	}
+func DownloadToFile(url, destPath string, header map[string]string, opts ...RequestOption) error {
+	return downloadToPath(context.Background(), url, destPath, "", header, newRequestConfig(opts...))
+}
+
+// DownloadVerified 下载文件到本地，并校验内容的 SHA-256
+//
+// 响应体在写入文件的同时计算摘要，不需要再次读取文件。
+// 文件的写入方式与 DownloadToFile 相同，只有校验和一致时才会替换目标文件。
+//
+// 参数:
+//   - url: 目标文件地址
+//   - destPath: 本地保存路径
+//   - expectedSHA256: 期望的 SHA-256 十六进制摘要，不区分大小写
+//   - header: 自定义的 HTTP 请求头
+//   - opts: 下载选项，目前只有 WithIdleReadTimeout 和 WithOverwrite 生效
+//
+// 返回值:
+//   - error: 请求错误、非 2xx 状态码、写入错误、*StalledError、目标文件已存在的错误或 ErrChecksumMismatch，如果成功则为 nil
+//
+// 示例:
+//
+//	err := DownloadVerified("https://example.com/app.tar.gz", "/tmp/app.tar.gz", sum, nil)
+//	if errors.Is(err, ErrChecksumMismatch) {
+//	    log.Fatal("corrupted download")
+//	}
+func DownloadVerified(url, destPath, expectedSHA256 string, header map[string]string, opts ...RequestOption) error {
+	return downloadToPath(context.Background(), url, destPath, expectedSHA256, header, newRequestConfig(opts...))
+}
+
+// downloadToPath 下载文件并保存到固定的路径
+func downloadToPath(ctx context.Context, url, destPath, expectedSHA256 string, header map[string]string, cfg *requestConfig) error {
+	policy := cfg.existingPolicy(replaceExisting)
+	if policy == rejectExisting {
+		// 提前检查，避免下载完成后才发现无法写入
+		if _, err := os.Lstat(destPath); err == nil {
+			return &os.PathError{Op: "download", Path: destPath, Err: os.ErrExist}
+		}
+	}
+	_, err := downloadFile(ctx, url, expectedSHA256, header, cfg, policy, func(*http.Response) string {
+		return destPath
+	})
+	return err
+}
+
+// downloadFile 下载文件并原子地写入 destPath 返回的路径，expectedSHA256 不为空时校验内容的 SHA-256
+//
+// destPath 在收到响应头之后调用，可以根据响应头决定保存路径。返回实际保存的路径。
+func downloadFile(ctx context.Context, url, expectedSHA256 string, header map[string]string, cfg *requestConfig, policy existingFilePolicy, destPath func(res *http.Response) string) (string, error) {
+	ctx, done, err := track(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	res, err := doStream(ctx, newClient(0), http.MethodGet, url, nil, header)
+	if err != nil {
+		return "", err
+	}
+	body := res.Body
+	if cfg.idleReadTimeout > 0 {
+		body = watchIdle(body, cfg.idleReadTimeout, cancel)
+	}
+	defer drainBody(body)
+
+	if StatusClass(res.StatusCode) != ClassSuccess {
+		return "", fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	return writeFileAtomic(destPath(res), policy, func(w io.Writer) error {
+		hash := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(w, hash), body); err != nil {
+			return err
+		}
+		if expectedSHA256 == "" {
+			return nil
+		}
+		actual := hex.EncodeToString(hash.Sum(nil))
+		if !strings.EqualFold(actual, expectedSHA256) {
+			return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expectedSHA256, actual)
+		}
+		return nil
+	})
+}
+
+// writeFileAtomic 将 write 写出的内容先写入同目录下的临时文件，成功后再重命名为 destPath，返回实际保存的路径
+//
+// write 返回错误时删除临时文件，destPath 保持不变。不覆盖已存在的文件时使用硬链接代替重命名，
+// 在下载期间被其他进程创建的目标文件也不会被覆盖。
+func writeFileAtomic(destPath string, policy existingFilePolicy, write func(w io.Writer) error) (savedPath string, err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), "."+filepath.Base(destPath)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	err = write(tmp)
+	if err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if policy == replaceExisting {
+		return destPath, os.Rename(tmp.Name(), destPath)
+	}
+	for i := 1; ; i++ {
+		savedPath = destPath
+		if policy == renameNew && i > 1 {
+			savedPath = numberedPath(destPath, i-1)
+		}
+		err = os.Link(tmp.Name(), savedPath)
+		if err == nil {
+			_ = os.Remove(tmp.Name())
+			return savedPath, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+		if policy == rejectExisting {
+			return "", &os.PathError{Op: "download", Path: destPath, Err: os.ErrExist}
+		}
+	}
+}
+
+// numberedPath 在文件扩展名之前追加数字后缀，如 "report.pdf" 变为 "report-1.pdf"
+func numberedPath(path string, n int) string {
+	ext := filepath.Ext(path)
+	// 以 "." 开头且没有其他扩展名的文件（如 ".env"）整体作为文件名
+	if ext == filepath.Base(path) {
+		ext = ""
+	}
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), n, ext)
+}
@@ -0,0 +1,225 @@
+package resty_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestRemoteFileInfo(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	content := "hello remote file"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/plain")
+		http.ServeContent(w, r, "file.txt", modTime, strings.NewReader(content))
+	}))
+	defer ts.Close()
+
+	headers := map[string]string{
+		"Authorization": "test-token",
+	}
+	info, err := RemoteFileInfo(ts.URL+"/file.txt", headers)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.True(t, modTime.Equal(info.LastModified), "last modified mismatch")
+	assert.Equal(t, `"v1"`, info.ETag)
+	assert.True(t, info.AcceptRanges)
+	assert.Equal(t, "text/plain", info.ContentType)
+}
+
+func TestRemoteFileInfoHeadRejected(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	content := "hello remote file"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "bytes=0-0", r.Header.Get("Range"))
+		http.ServeContent(w, r, "file.txt", modTime, strings.NewReader(content))
+	}))
+	defer ts.Close()
+
+	info, err := RemoteFileInfo(ts.URL+"/file.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.True(t, modTime.Equal(info.LastModified), "last modified mismatch")
+	assert.True(t, info.AcceptRanges)
+	assert.Empty(t, info.ETag, "missing header should yield zero value")
+}
+
+func TestRemoteFileInfoMissingHeaders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	info, err := RemoteFileInfo(ts.URL+"/file.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, FileInfo{}, info)
+}
+
+func TestDownloadVerified(t *testing.T) {
+	content := "verified artifact content"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, content)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	sum := sha256.Sum256([]byte(content))
+	expected := hex.EncodeToString(sum[:])
+	headers := map[string]string{"Authorization": "test-token"}
+	dest := filepath.Join(t.TempDir(), "artifact")
+
+	// 校验和一致，大小写不敏感
+	err := DownloadVerified(ts.URL, dest, strings.ToUpper(expected), headers)
+	assert.NoError(t, err)
+	data, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	// 校验和不一致时保留原有文件
+	err = DownloadVerified(ts.URL, dest, strings.Repeat("0", 64), headers)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	data, err = os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	// 目标文件不存在时不会留下文件
+	other := filepath.Join(t.TempDir(), "artifact")
+	err = DownloadVerified(ts.URL, other, strings.Repeat("0", 64), headers)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.NoFileExists(t, other)
+	assertNoTempFiles(t, filepath.Dir(other))
+}
+
+func TestDownloadVerifiedStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	dest := filepath.Join(t.TempDir(), "artifact")
+	err := DownloadVerified(ts.URL, dest, strings.Repeat("0", 64), nil)
+	assert.EqualError(t, err, "unexpected status code: 404")
+	assert.NoFileExists(t, dest)
+}
+
+// truncatingServer 声明完整长度但只发送一半内容后断开连接
+func truncatingServer(t *testing.T, content string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, content[:len(content)/2])
+		w.(http.Flusher).Flush()
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = conn.Close()
+	}))
+}
+
+// assertNoTempFiles 断言目录中没有残留的临时文件
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestDownloadToFile(t *testing.T) {
+	content := "downloaded file content"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, content)
+	}))
+	defer ts.Close()
+
+	dest := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(dest, []byte("old"), 0o644))
+
+	err := DownloadToFile(ts.URL, dest, nil)
+	assert.NoError(t, err)
+	data, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assertNoTempFiles(t, filepath.Dir(dest))
+}
+
+func TestDownloadToFileInterrupted(t *testing.T) {
+	ts := truncatingServer(t, strings.Repeat("new content ", 1000))
+	defer ts.Close()
+
+	t.Run("absent", func(t *testing.T) {
+		dest := filepath.Join(t.TempDir(), "file")
+		err := DownloadToFile(ts.URL, dest, nil)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.NoFileExists(t, dest)
+		assertNoTempFiles(t, filepath.Dir(dest))
+	})
+
+	t.Run("previous version", func(t *testing.T) {
+		dest := filepath.Join(t.TempDir(), "file")
+		assert.NoError(t, os.WriteFile(dest, []byte("previous version"), 0o644))
+
+		err := DownloadToFile(ts.URL, dest, nil)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		data, err := os.ReadFile(dest)
+		assert.NoError(t, err)
+		assert.Equal(t, "previous version", string(data))
+		assertNoTempFiles(t, filepath.Dir(dest))
+	})
+}
+
+func TestDownloadToFileNoOverwrite(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "new")
+	}))
+	defer ts.Close()
+
+	dest := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(dest, []byte("old"), 0o644))
+
+	err := DownloadToFile(ts.URL, dest, nil, WithOverwrite(false))
+	assert.ErrorIs(t, err, os.ErrExist)
+	assert.Equal(t, 0, requests, "existing destination should be rejected before downloading")
+	data, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+
+	other := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, DownloadToFile(ts.URL, other, nil, WithOverwrite(false)))
+	data, err = os.ReadFile(other)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	assertNoTempFiles(t, filepath.Dir(other))
+}
@@ -0,0 +1,80 @@
+package resty
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultDownloadConcurrency DownloadMany 默认同时进行的下载数
+const DefaultDownloadConcurrency = 4
+
+// DownloadItem DownloadMany 中的单个下载任务
+type DownloadItem struct {
+	// URL 文件地址
+	URL string
+	// DestPath 本地保存路径
+	DestPath string
+	// Header 自定义的 HTTP 请求头
+	Header map[string]string
+}
+
+// DownloadMany 并发下载多个文件，返回每个文件的下载结果
+//
+// 每个文件的写入方式与 DownloadToFile 相同：响应体流式写入临时文件，完整写入后再重命名为目标文件，
+// 已存在的目标文件会被覆盖。单个文件失败不影响其他文件；ctx 取消后正在进行的下载会中止，
+// 尚未开始的下载不会再发出，其结果为 ctx 的错误。
+//
+// 参数:
+//   - ctx: 请求上下文，取消后停止所有下载
+//   - items: 下载任务列表
+//   - concurrency: 最大并发数，小于等于 0 时使用 DefaultDownloadConcurrency
+//
+// 返回值:
+//   - []error: 每个任务的错误，顺序与 items 一致，成功时为 nil
+//
+// 示例:
+//
+//	items := []DownloadItem{
+//	    {URL: "https://cdn.example.com/a.png", DestPath: "/data/assets/a.png"},
+//	    {URL: "https://cdn.example.com/b.png", DestPath: "/data/assets/b.png"},
+//	}
+//	for i, err := range DownloadMany(ctx, items, 8) {
+//	    if err != nil {
+//	        log.Printf("%s: %v", items[i].URL, err)
+//	    }
+//	}
+func DownloadMany(ctx context.Context, items []DownloadItem, concurrency int) []error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultDownloadConcurrency
+	}
+
+	errs := make([]error, len(items))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		// 等待并发名额期间 ctx 可能已经取消
+		if err := ctx.Err(); err != nil {
+			<-sem
+			errs[i] = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, item DownloadItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = downloadToPath(ctx, item.URL, item.DestPath, "", item.Header, newRequestConfig())
+		}(i, item)
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,103 @@
+package resty_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestDownloadMany(t *testing.T) {
+	var active, peak atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, r.URL.Path+" "+r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	var items []DownloadItem
+	for i := 0; i < 6; i++ {
+		name := "file" + strconv.Itoa(i)
+		items = append(items, DownloadItem{
+			URL:      ts.URL + "/" + name,
+			DestPath: filepath.Join(dir, name),
+			Header:   map[string]string{"Authorization": "token-" + strconv.Itoa(i)},
+		})
+	}
+	items = append(items, DownloadItem{URL: ts.URL + "/missing", DestPath: filepath.Join(dir, "missing")})
+
+	errs := DownloadMany(context.Background(), items, 2)
+	if !assert.Len(t, errs, len(items)) {
+		return
+	}
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, errs[i])
+		data, err := os.ReadFile(items[i].DestPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "/file"+strconv.Itoa(i)+" token-"+strconv.Itoa(i), string(data))
+	}
+	// 单个失败不影响其他文件，失败时不留下目标文件
+	assert.EqualError(t, errs[6], "unexpected status code: 404")
+	assert.NoFileExists(t, items[6].DestPath)
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+
+	assert.Empty(t, DownloadMany(context.Background(), nil, 0))
+}
+
+func TestDownloadManyCanceled(t *testing.T) {
+	started := make(chan struct{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+		started <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	items := []DownloadItem{
+		{URL: ts.URL + "/slow", DestPath: filepath.Join(dir, "slow")},
+		{URL: ts.URL + "/queued", DestPath: filepath.Join(dir, "queued")},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	errs := DownloadMany(ctx, items, 1)
+
+	// 正在进行的下载中止，尚未开始的下载不会发出
+	assert.ErrorIs(t, errs[0], context.Canceled)
+	assert.ErrorIs(t, errs[1], context.Canceled)
+	assert.NoFileExists(t, items[0].DestPath)
+	assert.NoFileExists(t, items[1].DestPath)
+	// 不留下临时文件
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,30 @@
+package resty
+
+import (
+	"io"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// maxDrainBytes 关闭响应体前最多丢弃的字节数，剩余数据更多时直接关闭连接比读完更划算
+const maxDrainBytes = 64 << 10
+
+// drainAndClose 丢弃未读取的原始响应体后关闭，使连接可以放回连接池复用
+//
+// 只在使用 SetDoNotParseResponse 的请求上需要调用，resty 解析过的响应体已经读完并关闭。
+// 任何提前返回的错误路径都应通过 defer 调用，否则连接既不会被复用也不会及时释放。
+func drainAndClose(res *resty.Response) {
+	if res == nil || res.RawResponse == nil {
+		return
+	}
+	drainBody(res.RawResponse.Body)
+}
+
+// drainBody 丢弃最多 maxDrainBytes 字节的剩余数据后关闭 body，用于绕过 resty 的流式请求
+func drainBody(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	_, _ = io.CopyN(io.Discard, body, maxDrainBytes)
+	_ = body.Close()
+}
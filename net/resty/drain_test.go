@@ -0,0 +1,42 @@
+package resty_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestDrainAndClose(t *testing.T) {
+	var conns atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(strings.Repeat("error detail ", 1000)))
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	client := resty.New()
+	for i := 0; i < 3; i++ {
+		res, err := client.R().SetDoNotParseResponse(true).Get(ts.URL)
+		assert.NoError(t, err)
+		// 未读取的响应体被丢弃后，连接可以被下一个请求复用
+		DrainAndClose(res)
+	}
+	assert.Equal(t, int32(1), conns.Load())
+
+	// nil 响应不会 panic
+	DrainAndClose(nil)
+	DrainAndClose(&resty.Response{})
+}
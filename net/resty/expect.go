@@ -0,0 +1,38 @@
+package resty
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// WithExpectContinue 发送请求体前先携带 Expect: 100-continue 等待服务端确认
+//
+// 服务端返回 100 Continue 后才发送请求体；如果服务端直接返回最终响应（如 401、413、417），
+// 请求体不会被发送，避免大请求体在注定被拒绝的请求上浪费带宽。
+// 超过 timeout 仍未收到任何响应时照常发送请求体，以兼容不支持 100-continue 的服务端。
+// 请求体没有被发送时不会被消费，重试时仍然可以重放。没有请求体的请求不受影响。
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodPut, "https://storage.example.com/objects/1",
+//	    WithBody(data),
+//	    WithExpectContinue(time.Second),
+//	)
+func WithExpectContinue(timeout time.Duration) RequestOption {
+	return func(c *requestConfig) {
+		c.expectContinue = timeout
+	}
+}
+
+// applyExpectContinue 设置客户端 Transport 等待 100 Continue 的时间，并为请求添加 Expect 请求头
+func applyExpectContinue(client *resty.Client, header http.Header, timeout time.Duration) error {
+	transport, err := client.Transport()
+	if err != nil {
+		return err
+	}
+	transport.ExpectContinueTimeout = timeout
+	header.Set("Expect", "100-continue")
+	return nil
+}
@@ -0,0 +1,133 @@
+package resty_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// countingListener 统计服务端从连接中读取的字节数
+type countingListener struct {
+	net.Listener
+	read *atomic.Int64
+}
+
+func (l countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return countingConn{Conn: conn, read: l.read}, nil
+}
+
+type countingConn struct {
+	net.Conn
+	read *atomic.Int64
+}
+
+func (c countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+// expectServer 请求携带 "Authorization: ok" 时读取请求体，否则直接返回 417
+func expectServer(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	read := &atomic.Int64{}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "100-continue", r.Header.Get("Expect"))
+		if r.Header.Get("Authorization") != "ok" {
+			w.WriteHeader(http.StatusExpectationFailed)
+			return
+		}
+		// 读取请求体时服务端自动发送 100 Continue
+		n, err := io.Copy(io.Discard, r.Body)
+		assert.NoError(t, err)
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "received")
+		assert.Equal(t, int64(1<<20), n)
+	}))
+	ts.Listener = countingListener{Listener: ts.Listener, read: read}
+	ts.Start()
+	return ts, read
+}
+
+func TestWithExpectContinue(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 1<<20)
+
+	t.Run("rejected", func(t *testing.T) {
+		ts, read := expectServer(t)
+		defer ts.Close()
+
+		resp, err := Do(context.Background(), http.MethodPut, ts.URL,
+			WithBody(body),
+			WithExpectContinue(5*time.Second),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusExpectationFailed, resp.StatusCode)
+		assert.Less(t, read.Load(), int64(len(body)), "body should not be transmitted")
+	})
+
+	t.Run("continue", func(t *testing.T) {
+		ts, read := expectServer(t)
+		defer ts.Close()
+
+		resp, err := Do(context.Background(), http.MethodPut, ts.URL,
+			WithHeaders(map[string]string{"Authorization": "ok"}),
+			WithBody(body),
+			WithExpectContinue(5*time.Second),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("received"), resp.Body)
+		assert.Greater(t, read.Load(), int64(len(body)))
+	})
+}
+
+func TestUploadFileExpectContinue(t *testing.T) {
+	ts, read := expectServer(t)
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "large.bin")
+	assert.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("x"), 1<<20), 0o644))
+
+	_, err := UploadFile(ts.URL, path, "file", nil, WithExpectContinue(5*time.Second))
+	assert.NoError(t, err)
+	assert.Less(t, read.Load(), int64(1<<20), "file should not be transmitted")
+}
+
+func TestWithExpectContinueRetry(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 1<<20)
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次请求在发送请求体之前被拒绝
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, body, data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodPut, ts.URL,
+		WithBody(body),
+		WithExpectContinue(5*time.Second),
+		WithRetry(RetryConfig{MaxRetries: 1, WaitTime: time.Millisecond}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), attempts.Load())
+}
@@ -0,0 +1,31 @@
+package resty
+
+import (
+	"context"
+	"time"
+)
+
+// ResetShutdown 恢复 Shutdown 之前的状态，仅供测试使用
+func ResetShutdown() {
+	shutdownMutex.Lock()
+	defer shutdownMutex.Unlock()
+	shutdownDone = false
+	rootCtx, rootCancel = context.WithCancel(context.Background())
+}
+
+// DrainAndClose 导出 drainAndClose，仅供测试使用
+var DrainAndClose = drainAndClose
+
+// WithPayloadCounter 导出 withPayloadCounter，返回读取累计字节数的函数，仅供测试使用
+func WithPayloadCounter(ctx context.Context) (context.Context, func() (int64, int64)) {
+	ctx, counter := withPayloadCounter(ctx)
+	return ctx, func() (int64, int64) {
+		return counter.requestBytes.Load(), counter.responseBytes.Load()
+	}
+}
+
+// SetRetryAfterFunc 替换重试的等待和超时预算使用的定时器，返回恢复默认定时器的函数，仅供测试使用
+func SetRetryAfterFunc(f func(d time.Duration, fn func()) (stop func() bool)) (restore func()) {
+	afterFuncHook.Store(&f)
+	return func() { afterFuncHook.Store(nil) }
+}
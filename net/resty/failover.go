@@ -0,0 +1,77 @@
+package resty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	failoverMutex sync.Mutex
+	// failoverLastGood 记录每组 hosts 最近一次成功的 host
+	failoverLastGood = make(map[string]string)
+)
+
+// GetWithFailover 依次向多个 host 发送 GET 请求，网络错误或 5xx 时切换到下一个 host
+//
+// 每组 hosts 会记住最近一次成功的 host，下次调用时优先尝试，其余 host 按原顺序排在其后。
+// 非 5xx 的响应（包括 4xx）视为 host 可用，直接返回响应体。
+//
+// 参数:
+//   - path: 请求路径，会拼接在每个 host 之后，如 "/api/v1/users"
+//   - hosts: 带 scheme 的 host 列表，如 "https://primary.example.com"
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 成功的 host 返回的响应体
+//   - error: 所有 host 都失败时为最后一个 host 的错误
+//
+// 示例:
+//
+//	hosts := []string{"https://primary.example.com", "https://backup.example.com"}
+//	resp, err := GetWithFailover("/api/v1/users", hosts, nil)
+func GetWithFailover(path string, hosts []string, header map[string]string) ([]byte, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("no hosts to try")
+	}
+
+	key := strings.Join(hosts, "\n")
+	var lastErr error
+	for _, host := range failoverOrder(key, hosts) {
+		res, err := Do(context.Background(), http.MethodGet, joinSlash(host, path), WithHeaders(header))
+		if err == nil && res.IsServerError() {
+			err = fmt.Errorf("unexpected status code: %d", res.StatusCode)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		failoverMutex.Lock()
+		failoverLastGood[key] = host
+		failoverMutex.Unlock()
+		return res.Body, nil
+	}
+	return nil, lastErr
+}
+
+// failoverOrder 返回本次尝试的 host 顺序，最近一次成功的 host 排在最前
+func failoverOrder(key string, hosts []string) []string {
+	failoverMutex.Lock()
+	lastGood := failoverLastGood[key]
+	failoverMutex.Unlock()
+
+	order := make([]string, 0, len(hosts))
+	if lastGood != "" {
+		order = append(order, lastGood)
+	}
+	for _, host := range hosts {
+		if host != lastGood {
+			order = append(order, host)
+		}
+	}
+	return order
+}
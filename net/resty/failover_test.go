@@ -0,0 +1,74 @@
+package resty_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetWithFailover(t *testing.T) {
+	var primaryHits, backupHits int64
+	var primaryDown atomic.Bool
+	primaryDown.Store(true)
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&primaryHits, 1)
+		if primaryDown.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, "primary")
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&backupHits, 1)
+		assert.Equal(t, "/api/users", r.URL.Path)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		_, _ = io.WriteString(w, "backup")
+	}))
+	defer backup.Close()
+
+	hosts := []string{primary.URL, backup.URL + "/"}
+	headers := map[string]string{"Authorization": "test-token"}
+
+	// 主 host 返回 5xx，切换到备用 host
+	resp, err := GetWithFailover("/api/users", hosts, headers)
+	assert.NoError(t, err)
+	assert.Equal(t, "backup", string(resp))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&primaryHits))
+
+	// 记住最近成功的 host，下次优先尝试
+	primaryDown.Store(false)
+	resp, err = GetWithFailover("api/users", hosts, headers)
+	assert.NoError(t, err)
+	assert.Equal(t, "backup", string(resp))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&primaryHits))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&backupHits))
+}
+
+func TestGetWithFailoverAllFailed(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	// 连接失败的 host
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closedURL := closed.URL
+	closed.Close()
+
+	_, err := GetWithFailover("/", []string{closedURL, down.URL}, nil)
+	assert.EqualError(t, err, "unexpected status code: 502")
+
+	_, err = GetWithFailover("/", []string{down.URL, closedURL}, nil)
+	assert.Error(t, err)
+	assert.NotEqual(t, "unexpected status code: 502", err.Error())
+
+	_, err = GetWithFailover("/", nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package resty
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedFormValue 结构体中包含无法编码为表单的字段，如 chan、func 或非字符串 key 的 map
+var ErrUnsupportedFormValue = errors.New("unsupported form value")
+
+// textMarshalerType encoding.TextMarshaler 的反射类型
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// FormStruct 将结构体编码为 x-www-form-urlencoded 表单并发送 POST 请求
+//
+// 字段名取自 `form:"..."` 标签，没有标签时使用字段名，`form:"-"` 跳过该字段，
+// `form:"name,omitempty"` 在字段为零值时跳过。未导出的字段和值为 nil 的指针不会被编码，
+// 没有标签的匿名嵌入结构体会展开到外层。
+//
+// 嵌套结构体和集合使用常见的方括号写法：
+//   - 嵌套结构体: address[city]=Beijing
+//   - 基本类型的切片: tags[]=a&tags[]=b
+//   - 结构体的切片: items[0][name]=x&items[1][name]=y
+//   - 字符串 key 的 map: meta[k]=v
+//
+// time.Time 使用 RFC 3339 格式，实现了 encoding.TextMarshaler 的类型使用其文本形式。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - v: 结构体或结构体指针
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - error: v 无法编码时返回 ErrUnsupportedFormValue，否则为请求过程中的错误信息
+//
+// 示例:
+//
+//	type SignUp struct {
+//	    Name    string   `form:"name"`
+//	    Tags    []string `form:"tags"`
+//	    Address struct {
+//	        City string `form:"city"`
+//	    } `form:"address"`
+//	}
+//	resp, err := FormStruct("https://api.example.com/signup", signUp, nil)
+//	// 请求体: address%5Bcity%5D=Beijing&name=test&tags%5B%5D=a&tags%5B%5D=b
+func FormStruct(url string, v interface{}, header map[string]string) ([]byte, error) {
+	values, err := encodeFormStruct(v)
+	if err != nil {
+		return nil, err
+	}
+	return doBody(http.MethodPost, url, WithHeaders(header), WithHeaders(map[string]string{ContentType: ContentTypeForm}), WithBody(values.Encode()))
+}
+
+// encodeFormStruct 将结构体编码为表单值
+func encodeFormStruct(v interface{}) (url.Values, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, fmt.Errorf("%w: nil %s", ErrUnsupportedFormValue, value.Type())
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected struct, got %v", ErrUnsupportedFormValue, value.Kind())
+	}
+
+	values := url.Values{}
+	if err := encodeFormFields(values, "", value); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// encodeFormFields 编码结构体的字段，prefix 为外层的 key，顶层结构体为空
+func encodeFormFields(values url.Values, prefix string, value reflect.Value) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("form")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		// 没有标签的匿名嵌入结构体展开到外层
+		if field.Anonymous && !hasTag {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := encodeFormFields(values, prefix, embedded); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if opts == "omitempty" && fieldValue.IsZero() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "[" + name + "]"
+		}
+		if err := encodeFormValue(values, key, fieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeFormValue 按类型编码单个值
+func encodeFormValue(values url.Values, key string, value reflect.Value) error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if s, ok, err := formScalar(value); ok || err != nil {
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrUnsupportedFormValue, key, err)
+		}
+		values.Add(key, s)
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return encodeFormFields(values, key, value)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			elem := value.Index(i)
+			// 基本类型的元素使用 key[]，复合类型的元素需要下标区分
+			elemKey := key + "[]"
+			if !isFormScalar(elem) {
+				elemKey = key + "[" + strconv.Itoa(i) + "]"
+			}
+			if err := encodeFormValue(values, elemKey, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%w: %s: map key must be string", ErrUnsupportedFormValue, key)
+		}
+		mapKeys := value.MapKeys()
+		sort.Slice(mapKeys, func(i, j int) bool {
+			return mapKeys[i].String() < mapKeys[j].String()
+		})
+		for _, mapKey := range mapKeys {
+			if err := encodeFormValue(values, key+"["+mapKey.String()+"]", value.MapIndex(mapKey)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %s: %s", ErrUnsupportedFormValue, key, value.Type())
+	}
+}
+
+// formScalar 将基本类型、time.Time 和 encoding.TextMarshaler 转换为字符串，ok 为 false 表示不是这些类型
+func formScalar(value reflect.Value) (s string, ok bool, err error) {
+	if value.Type() == reflect.TypeOf(time.Time{}) {
+		return value.Interface().(time.Time).Format(time.RFC3339), true, nil
+	}
+	if value.Type().Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), true, err
+	}
+	if value.CanAddr() && reflect.PointerTo(value.Type()).Implements(textMarshalerType) {
+		text, err := value.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), true, err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(value.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), true, nil
+	}
+	return "", false, nil
+}
+
+// isFormScalar 判断值（解引用后）是否编码为单个字符串
+func isFormScalar(value reflect.Value) bool {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return true
+		}
+		value = value.Elem()
+	}
+	_, ok, _ := formScalar(value)
+	return ok
+}
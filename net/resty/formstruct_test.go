@@ -0,0 +1,142 @@
+package resty_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+type formAddress struct {
+	City   string `form:"city"`
+	Street string `form:"street,omitempty"`
+}
+
+type formItem struct {
+	SKU      string `form:"sku"`
+	Quantity int    `form:"qty"`
+}
+
+type formAudit struct {
+	Source string `form:"source"`
+}
+
+type formSignUp struct {
+	formAudit
+	Name      string            `form:"name"`
+	Age       int               `form:"age"`
+	Score     float64           `form:"score"`
+	Active    bool              `form:"active"`
+	Nickname  *string           `form:"nickname"`
+	Note      string            `form:"note,omitempty"`
+	Tags      []string          `form:"tags"`
+	Address   formAddress       `form:"address"`
+	Items     []formItem        `form:"items"`
+	Meta      map[string]string `form:"meta"`
+	CreatedAt time.Time         `form:"created_at"`
+	IP        net.IP            `form:"ip"`
+	Secret    string            `form:"-"`
+	Plain     string
+	internal  string
+}
+
+// formEchoServer 校验表单请求并返回原始请求体
+func formEchoServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+}
+
+func TestFormStruct(t *testing.T) {
+	ts := formEchoServer(t)
+	defer ts.Close()
+
+	v := formSignUp{
+		formAudit: formAudit{Source: "web"},
+		Name:      "test",
+		Age:       18,
+		Score:     9.5,
+		Active:    true,
+		Tags:      []string{"a", "b"},
+		Address:   formAddress{City: "Beijing"},
+		Items:     []formItem{{SKU: "x", Quantity: 1}, {SKU: "y", Quantity: 2}},
+		Meta:      map[string]string{"k": "v"},
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		IP:        net.ParseIP("10.0.0.1"),
+		Secret:    "hidden",
+		Plain:     "plain",
+		internal:  "internal",
+	}
+	resp, err := FormStruct(ts.URL, &v, nil)
+	assert.NoError(t, err)
+
+	form, err := url.ParseQuery(string(resp))
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"source":        {"web"},
+		"name":          {"test"},
+		"age":           {"18"},
+		"score":         {"9.5"},
+		"active":        {"true"},
+		"tags[]":        {"a", "b"},
+		"address[city]": {"Beijing"},
+		"items[0][sku]": {"x"},
+		"items[0][qty]": {"1"},
+		"items[1][sku]": {"y"},
+		"items[1][qty]": {"2"},
+		"meta[k]":       {"v"},
+		"created_at":    {"2024-01-02T03:04:05Z"},
+		"ip":            {"10.0.0.1"},
+		"Plain":         {"plain"},
+	}, form)
+}
+
+func TestFormStructPointers(t *testing.T) {
+	ts := formEchoServer(t)
+	defer ts.Close()
+
+	nickname := "tester"
+	type withPointers struct {
+		Nickname *string      `form:"nickname"`
+		Address  *formAddress `form:"address"`
+		Missing  *formAddress `form:"missing"`
+		IDs      []int        `form:"ids"`
+	}
+	resp, err := FormStruct(ts.URL, withPointers{
+		Nickname: &nickname,
+		Address:  &formAddress{City: "Shanghai", Street: "Nanjing Rd"},
+		IDs:      []int{3, 1},
+	}, map[string]string{"Authorization": "test-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, "address%5Bcity%5D=Shanghai&address%5Bstreet%5D=Nanjing+Rd&ids%5B%5D=3&ids%5B%5D=1&nickname=tester", string(resp))
+}
+
+func TestFormStructUnsupported(t *testing.T) {
+	ts := formEchoServer(t)
+	defer ts.Close()
+
+	_, err := FormStruct(ts.URL, map[string]string{"name": "test"}, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedFormValue)
+
+	_, err = FormStruct(ts.URL, (*formAddress)(nil), nil)
+	assert.ErrorIs(t, err, ErrUnsupportedFormValue)
+
+	_, err = FormStruct(ts.URL, struct {
+		Callback func() `form:"callback"`
+	}{Callback: func() {}}, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedFormValue)
+
+	_, err = FormStruct(ts.URL, struct {
+		Scores map[int]string `form:"scores"`
+	}{Scores: map[int]string{1: "a"}}, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedFormValue)
+}
@@ -0,0 +1,157 @@
+package resty
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ContentTypeGRPCWeb gRPC-Web 二进制格式的 Content-Type 值
+const ContentTypeGRPCWeb = "application/grpc-web+proto"
+
+// gRPC-Web 帧的标志位
+const (
+	grpcWebDataFrame    byte = 0x00
+	grpcWebTrailerFrame byte = 0x80
+)
+
+// GRPCWebError gRPC-Web 响应的 grpc-status 不为 0 时返回的错误
+type GRPCWebError struct {
+	// Code gRPC 状态码
+	Code int
+	// Message grpc-message 中的错误描述（已解码）
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *GRPCWebError) Error() string {
+	return fmt.Sprintf("grpc-web status %d: %s", e.Code, e.Message)
+}
+
+// PostGRPCWeb 调用 gRPC-Web 接口，发送带长度前缀的请求帧并解析响应帧和 trailers
+//
+// body 为已序列化的 protobuf 消息，函数负责添加 gRPC-Web 的 5 字节帧头。
+// 响应中的数据帧依次拼接为 payload，trailer 帧解析为 trailers；
+// 服务端只返回响应头（Trailers-Only）时，trailers 从响应头中读取。
+//
+// 参数:
+//   - url: gRPC-Web 接口地址，如 https://api.example.com/pkg.Service/Method
+//   - body: 序列化后的请求消息
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - payload: 响应消息，多个数据帧时为拼接结果
+//   - trailers: 响应 trailers，包含 grpc-status、grpc-message 等
+//   - err: 请求错误、帧格式错误、非 2xx 状态码或 *GRPCWebError，如果成功则为 nil
+//
+// 示例:
+//
+//	req, _ := proto.Marshal(&pb.GetUserRequest{Id: 1})
+//	payload, _, err := PostGRPCWeb("https://api.example.com/user.UserService/GetUser", req, nil)
+//	var user pb.User
+//	err = proto.Unmarshal(payload, &user)
+func PostGRPCWeb(url string, body []byte, header map[string]string) (payload []byte, trailers http.Header, err error) {
+	res, err := Do(context.Background(), http.MethodPost, url,
+		WithHeaders(header),
+		WithHeaders(map[string]string{
+			ContentType:  ContentTypeGRPCWeb,
+			"Accept":     ContentTypeGRPCWeb,
+			"X-Grpc-Web": "1",
+		}),
+		WithBody(grpcWebFrame(grpcWebDataFrame, body)),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !res.IsSuccess() {
+		return nil, nil, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	payload, trailers, err = parseGRPCWebFrames(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if trailers.Get("Grpc-Status") == "" {
+		// Trailers-Only 响应
+		trailers = res.Header
+	}
+	return payload, trailers, grpcWebStatus(trailers)
+}
+
+// grpcWebFrame 为数据添加 gRPC-Web 帧头：1 字节标志位和 4 字节大端长度
+func grpcWebFrame(flag byte, data []byte) []byte {
+	frame := make([]byte, 5, 5+len(data))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+// parseGRPCWebFrames 解析响应中的数据帧和 trailer 帧
+func parseGRPCWebFrames(data []byte) ([]byte, http.Header, error) {
+	var payload []byte
+	trailers := http.Header{}
+	for len(data) > 0 {
+		if len(data) < 5 {
+			return nil, nil, errors.New("grpc-web: truncated frame header")
+		}
+		flag := data[0]
+		length := binary.BigEndian.Uint32(data[1:5])
+		if uint64(len(data)-5) < uint64(length) {
+			return nil, nil, errors.New("grpc-web: truncated frame")
+		}
+		frame := data[5 : 5+length]
+		data = data[5+length:]
+
+		if flag&grpcWebTrailerFrame == 0 {
+			payload = append(payload, frame...)
+			continue
+		}
+		if err := parseGRPCWebTrailers(frame, trailers); err != nil {
+			return nil, nil, err
+		}
+	}
+	return payload, trailers, nil
+}
+
+// parseGRPCWebTrailers 解析 trailer 帧中 "key: value\r\n" 格式的 trailers
+func parseGRPCWebTrailers(frame []byte, trailers http.Header) error {
+	for _, line := range bytes.Split(frame, []byte("\r\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		key, value, ok := strings.Cut(string(line), ":")
+		if !ok {
+			return fmt.Errorf("grpc-web: malformed trailer %q", line)
+		}
+		trailers.Add(textproto.TrimString(key), textproto.TrimString(value))
+	}
+	return nil
+}
+
+// grpcWebStatus 将非 0 的 grpc-status 转换为 *GRPCWebError
+func grpcWebStatus(trailers http.Header) error {
+	status := trailers.Get("Grpc-Status")
+	if status == "" {
+		return errors.New("grpc-web: missing grpc-status")
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return fmt.Errorf("grpc-web: invalid grpc-status %q", status)
+	}
+	if code == 0 {
+		return nil
+	}
+	// grpc-message 使用百分号编码
+	message := trailers.Get("Grpc-Message")
+	if decoded, err := url.PathUnescape(message); err == nil {
+		message = decoded
+	}
+	return &GRPCWebError{Code: code, Message: message}
+}
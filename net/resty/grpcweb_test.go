@@ -0,0 +1,116 @@
+package resty_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// grpcWebTestFrame 构造一个 gRPC-Web 帧
+func grpcWebTestFrame(flag byte, data string) []byte {
+	frame := make([]byte, 5)
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+func TestPostGRPCWeb(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/grpc-web+proto", r.Header.Get("Content-Type"))
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, grpcWebTestFrame(0x00, "request"), body)
+
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		w.WriteHeader(http.StatusOK)
+		var resp []byte
+		resp = append(resp, grpcWebTestFrame(0x00, "hello ")...)
+		resp = append(resp, grpcWebTestFrame(0x00, "world")...)
+		resp = append(resp, grpcWebTestFrame(0x80, "grpc-status: 0\r\ngrpc-message: \r\nx-custom: value\r\n")...)
+		_, err = w.Write(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	headers := map[string]string{"Authorization": "test-token"}
+	payload, trailers, err := PostGRPCWeb(ts.URL+"/pkg.Service/Method", []byte("request"), headers)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello world"), payload)
+	assert.Equal(t, "0", trailers.Get("Grpc-Status"))
+	assert.Equal(t, "value", trailers.Get("X-Custom"))
+}
+
+func TestPostGRPCWebError(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "trailer frame",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(grpcWebTestFrame(0x80, "grpc-status: 5\r\ngrpc-message: user%20not%20found\r\n"))
+			},
+		},
+		{
+			name: "trailers only",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Grpc-Status", "5")
+				w.Header().Set("Grpc-Message", "user%20not%20found")
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			_, trailers, err := PostGRPCWeb(ts.URL, nil, nil)
+			var grpcErr *GRPCWebError
+			if assert.True(t, errors.As(err, &grpcErr)) {
+				assert.Equal(t, 5, grpcErr.Code)
+				assert.Equal(t, "user not found", grpcErr.Message)
+			}
+			assert.Equal(t, "5", trailers.Get("Grpc-Status"))
+		})
+	}
+}
+
+func TestPostGRPCWebMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{name: "truncated header", body: []byte{0x00, 0x00}},
+		{name: "truncated frame", body: grpcWebTestFrame(0x00, "hello")[:7]},
+		{name: "missing status", body: grpcWebTestFrame(0x00, "hello")},
+		{name: "malformed trailer", body: grpcWebTestFrame(0x80, "grpc-status 0")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(tt.body)
+			}))
+			defer ts.Close()
+
+			_, _, err := PostGRPCWeb(ts.URL, nil, nil)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package resty
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultMaxResponseHeaderBytes 包内客户端默认允许的响应头最大字节数
+const DefaultMaxResponseHeaderBytes int64 = 1 << 20
+
+// ErrResponseHeadersTooLarge 响应头超过了允许的最大字节数
+var ErrResponseHeadersTooLarge = errors.New("response headers too large")
+
+// WithMaxResponseHeaderBytes 设置响应头的最大字节数，默认为 DefaultMaxResponseHeaderBytes
+//
+// 超过限制时请求返回 ErrResponseHeadersTooLarge；设置为 0 时不限制。
+func WithMaxResponseHeaderBytes(n int64) RequestOption {
+	return func(c *requestConfig) {
+		c.maxHeaderBytes = &n
+	}
+}
+
+// setMaxResponseHeaderBytes 设置客户端 Transport 的响应头大小限制，n 为 0 时不限制
+func setMaxResponseHeaderBytes(client *resty.Client, n int64) error {
+	transport, err := client.Transport()
+	if err != nil {
+		return err
+	}
+	// Transport 将 0 视为使用内置的默认限制，因此以最大值表示不限制
+	if n <= 0 {
+		n = math.MaxInt64
+	}
+	transport.MaxResponseHeaderBytes = n
+	return nil
+}
+
+// headerLimitError 将 net/http 的响应头超限错误转换为 ErrResponseHeadersTooLarge
+func headerLimitError(err error) error {
+	// net/http 未导出该错误类型，只能通过错误信息识别
+	if err != nil && strings.Contains(err.Error(), "server response headers exceeded") {
+		return fmt.Errorf("%w: %w", ErrResponseHeadersTooLarge, err)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package resty_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestMaxResponseHeaderBytes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 约 2MB 的 Set-Cookie
+		for i := 0; i < 32; i++ {
+			w.Header().Add("Set-Cookie", strings.Repeat("a", 64<<10))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	// 默认限制
+	_, err := Get(ts.URL)
+	assert.ErrorIs(t, err, ErrResponseHeadersTooLarge)
+
+	// 自定义限制
+	_, err = Do(context.Background(), http.MethodGet, ts.URL, WithMaxResponseHeaderBytes(4<<20))
+	assert.NoError(t, err)
+
+	// 0 表示不限制
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL, WithMaxResponseHeaderBytes(0))
+	if assert.NoError(t, err) {
+		assert.Len(t, resp.Header.Values("Set-Cookie"), 32)
+	}
+}
+
+func TestMaxResponseHeaderBytesSmall(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Large", strings.Repeat("b", 8<<10))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	_, err := Get(ts.URL)
+	assert.NoError(t, err)
+
+	_, err = Do(context.Background(), http.MethodGet, ts.URL, WithMaxResponseHeaderBytes(4<<10))
+	assert.ErrorIs(t, err, ErrResponseHeadersTooLarge)
+}
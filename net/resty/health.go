@@ -0,0 +1,78 @@
+package resty
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// DefaultHealthCheckConcurrency HealthCheck 同时进行的最大检查数
+const DefaultHealthCheckConcurrency = 8
+
+// HealthCheck 并发地对多个地址发送 GET 请求，返回每个地址的检查结果
+//
+// 适用于服务启动时探测依赖是否就绪。每个地址独立计时，请求错误和非 2xx 状态码都视为失败，
+// 最多同时进行 DefaultHealthCheckConcurrency 个检查。重复的地址只检查一次。
+//
+// 参数:
+//   - urls: 待检查的地址列表
+//   - timeout: 单个检查的超时时间（秒），小于等于 0 时使用 DefaultTimeout
+//
+// 返回值:
+//   - map[string]error: 每个地址的检查结果，检查通过时为 nil
+//
+// 示例:
+//
+//	results := HealthCheck([]string{"http://db-proxy/healthz", "http://cache/healthz"}, 3)
+//	for url, err := range results {
+//	    if err != nil {
+//	        log.Printf("%s not ready: %v", url, err)
+//	    }
+//	}
+func HealthCheck(urls []string, timeout int64) map[string]error {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	results := make(map[string]error, len(urls))
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, DefaultHealthCheckConcurrency)
+	)
+	for _, url := range urls {
+		mu.Lock()
+		_, seen := results[url]
+		results[url] = nil
+		mu.Unlock()
+		if seen {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(url string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := checkHealth(url, timeout)
+			mu.Lock()
+			results[url] = err
+			mu.Unlock()
+		}(url)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkHealth 对单个地址发送 GET 请求，非 2xx 状态码返回错误
+func checkHealth(url string, timeout int64) error {
+	res, err := Do(context.Background(), http.MethodGet, url, WithTimeout(seconds(timeout)))
+	if err != nil {
+		return err
+	}
+	if !res.IsSuccess() {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package resty_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var requests atomic.Int32
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer healthy.Close()
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(3 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	start := time.Now()
+	results := HealthCheck([]string{healthy.URL, unhealthy.URL, slow.URL, closed.URL, healthy.URL}, 1)
+
+	assert.Len(t, results, 4)
+	assert.NoError(t, results[healthy.URL])
+	assert.EqualError(t, results[unhealthy.URL], "unexpected status code: 503")
+	assert.Error(t, results[slow.URL])
+	assert.Error(t, results[closed.URL])
+	// 重复的地址只检查一次
+	assert.Equal(t, int32(1), requests.Load())
+	// 检查并发进行，慢的依赖只受单个检查的超时限制
+	assert.Less(t, time.Since(start), 2500*time.Millisecond)
+
+	assert.Empty(t, HealthCheck(nil, 1))
+}
+
+func TestHealthCheckConcurrency(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	var urls []string
+	for i := 0; i < DefaultHealthCheckConcurrency*3; i++ {
+		urls = append(urls, server.URL+"/?i="+strconv.Itoa(i))
+	}
+	results := HealthCheck(urls, 0)
+	assert.Len(t, results, len(urls))
+	for _, err := range results {
+		assert.NoError(t, err)
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(DefaultHealthCheckConcurrency))
+	assert.Greater(t, peak.Load(), int32(1))
+}
@@ -0,0 +1,58 @@
+package resty
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// WithHost 覆盖请求的 Host，与请求地址中的主机无关
+//
+// 直接设置 http.Request.Host，不依赖 net/http 会忽略的 Host 请求头。
+// HTTPS 请求的 SNI 和证书校验同样使用该主机名，因此可以直接请求 IP 地址访问共享 IP 上的虚拟主机。
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodGet, "https://10.0.0.5/health", WithHost("api.example.com"))
+func WithHost(host string) RequestOption {
+	return func(c *requestConfig) {
+		c.host = host
+	}
+}
+
+// applyHostTLS 将 HTTPS 请求的 SNI 设置为 host 中的主机名（不含端口）
+func applyHostTLS(client *resty.Client, host string) error {
+	transport, err := client.Transport()
+	if err != nil {
+		return err
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	serverName := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		serverName = h
+	}
+	transport.TLSClientConfig.ServerName = serverName
+	return nil
+}
+
+// GetWithHost 发送 HTTP GET 请求，并使用 hostHeader 作为请求的 Host
+//
+// 参数:
+//   - url: 目标请求地址，通常使用 IP 地址
+//   - hostHeader: 请求的 Host，如 "api.example.com"，HTTPS 请求同时用于 SNI
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	resp, err := GetWithHost("http://10.0.0.5/health", "api.example.com", nil)
+func GetWithHost(url, hostHeader string, header map[string]string) ([]byte, error) {
+	return doBody(http.MethodGet, url, WithHeaders(header), WithHost(hostHeader))
+}
@@ -0,0 +1,35 @@
+package resty_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetWithHost(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("X-Token"))
+		_, _ = w.Write([]byte(r.Host))
+	}))
+	defer ts.Close()
+
+	resp, err := GetWithHost(ts.URL, "api.example.com", map[string]string{"X-Token": "token"})
+	assert.NoError(t, err)
+	assert.Equal(t, "api.example.com", string(resp))
+}
+
+func TestWithHostTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// SNI 与 Host 一致
+		_, _ = w.Write([]byte(r.Host + " " + r.TLS.ServerName))
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL, WithHost("example.com:8443"), WithTLSInsecure())
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com:8443 example.com", string(resp.Body))
+}
@@ -0,0 +1,103 @@
+package resty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrTransferStalled 响应体在 WithIdleReadTimeout 设置的时间内没有收到任何数据
+var ErrTransferStalled = errors.New("transfer stalled")
+
+// StalledError 传输停滞时返回的错误，可通过 errors.Is(err, ErrTransferStalled) 判断
+type StalledError struct {
+	// Received 停滞前已收到的响应体字节数
+	Received int64
+	// IdleTimeout 允许的最长无数据时间
+	IdleTimeout time.Duration
+}
+
+// Error 实现 error 接口
+func (e *StalledError) Error() string {
+	return fmt.Sprintf("%s: no data for %s after %d bytes", ErrTransferStalled, e.IdleTimeout, e.Received)
+}
+
+// Unwrap 返回 ErrTransferStalled
+func (e *StalledError) Unwrap() error {
+	return ErrTransferStalled
+}
+
+// WithIdleReadTimeout 设置读取响应体时允许的最长无数据时间
+//
+// 每次读到数据后重新计时，超过 d 没有收到任何数据时取消请求并返回 *StalledError，
+// 持续有数据到达的慢速传输不受影响。计时从开始读取响应体时开始，
+// 等待响应头的时间仍由 WithTimeout 控制；下载大文件时可以配合 WithTimeout(0) 取消总超时。
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodGet, "https://example.com/large.bin",
+//	    WithTimeout(0),
+//	    WithIdleReadTimeout(30*time.Second),
+//	)
+func WithIdleReadTimeout(d time.Duration) RequestOption {
+	return func(c *requestConfig) {
+		c.idleReadTimeout = d
+	}
+}
+
+// idleReader 带看门狗的响应体，超过 timeout 没有读到数据时调用 cancel 取消请求
+type idleReader struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	cancel   context.CancelFunc
+	stalled  atomic.Bool
+	received int64
+}
+
+// watchIdle 为响应体启动看门狗
+func watchIdle(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleReader {
+	r := &idleReader{body: body, timeout: timeout, cancel: cancel}
+	r.timer = time.AfterFunc(timeout, func() {
+		r.stalled.Store(true)
+		cancel()
+	})
+	return r
+}
+
+// Read 实现 io.Reader 接口，读到数据后重新计时
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.received += int64(n)
+		r.timer.Reset(r.timeout)
+	}
+	if err != nil && err != io.EOF && r.stalled.Load() {
+		return n, &StalledError{Received: r.received, IdleTimeout: r.timeout}
+	}
+	return n, err
+}
+
+// Close 停止看门狗并关闭响应体
+func (r *idleReader) Close() error {
+	r.timer.Stop()
+	r.cancel()
+	return r.body.Close()
+}
+
+// readIdleBody 在看门狗的监控下读取未经解析的原始响应体
+func readIdleBody(res *resty.Response, timeout time.Duration, cancel context.CancelFunc) (*resty.Response, error) {
+	body := watchIdle(res.RawBody(), timeout, cancel)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return res, err
+	}
+	return res.SetBody(data), nil
+}
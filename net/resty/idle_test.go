@@ -0,0 +1,80 @@
+package resty_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// pausingServer 先写入 first，暂停 pause 后再写入 second
+func pausingServer(first string, pause time.Duration, second string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(first))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(pause):
+		}
+		_, _ = w.Write([]byte(second))
+	}))
+}
+
+func TestWithIdleReadTimeout(t *testing.T) {
+	ts := pausingServer("first-part", 500*time.Millisecond, "second-part")
+	defer ts.Close()
+
+	start := time.Now()
+	_, err := Do(context.Background(), http.MethodGet, ts.URL, WithIdleReadTimeout(100*time.Millisecond))
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+	assert.ErrorIs(t, err, ErrTransferStalled)
+
+	var stalled *StalledError
+	if assert.True(t, errors.As(err, &stalled)) {
+		assert.Equal(t, int64(len("first-part")), stalled.Received)
+		assert.Equal(t, 100*time.Millisecond, stalled.IdleTimeout)
+	}
+
+	// 暂停时间在允许范围内
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL, WithIdleReadTimeout(2*time.Second))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte("first-partsecond-part"), resp.Body)
+	}
+}
+
+func TestWithIdleReadTimeoutSteady(t *testing.T) {
+	// 总耗时远超空闲时间，但持续有数据到达
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < 10; i++ {
+			_, _ = w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL, WithIdleReadTimeout(150*time.Millisecond))
+	if assert.NoError(t, err) {
+		assert.Equal(t, strings.Repeat("chunk", 10), string(resp.Body))
+	}
+}
+
+func TestDownloadVerifiedStalled(t *testing.T) {
+	ts := pausingServer("first-part", 500*time.Millisecond, "second-part")
+	defer ts.Close()
+
+	dest := filepath.Join(t.TempDir(), "artifact")
+	err := DownloadVerified(ts.URL, dest, strings.Repeat("0", 64), nil, WithIdleReadTimeout(100*time.Millisecond))
+	assert.ErrorIs(t, err, ErrTransferStalled)
+	assert.NoFileExists(t, dest)
+}
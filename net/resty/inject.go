@@ -0,0 +1,34 @@
+package resty
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/yocover/global-toolkit/net/rpc"
+)
+
+// InjectRestyHeaders 将上下文中的 RPC headers 写入 resty 请求的请求头
+//
+// 规则与 rpc.InjectHTTPHeaders 一致：键名转换为规范形式（x-request-id → X-Request-Id），
+// 请求中已设置的同名请求头优先，传入 rpc.OverrideHeaders 时改为替换已有的值。
+// 应在设置完自定义请求头之后调用，以便正确判断请求中已有的值。
+//
+// 参数:
+//   - ctx: 上下文
+//   - req: resty 请求，为 nil 时不做任何操作
+//   - opts: 注入规则，如 rpc.AllowHeaderPrefixes("x-ctx-")
+//
+// 示例:
+//
+//	req := client.R().SetContext(ctx).SetHeader("Authorization", "Bearer token123")
+//	InjectRestyHeaders(ctx, req, rpc.AllowHeaders("x-request-id", "traceparent"))
+//	resp, err := req.Get("https://api.example.com/orders")
+func InjectRestyHeaders(ctx context.Context, req *resty.Request, opts ...rpc.PropagationOption) {
+	if req == nil {
+		return
+	}
+	if req.Header == nil {
+		req.Header = make(map[string][]string)
+	}
+	rpc.InjectHTTPHeaders(ctx, req.Header, opts...)
+}
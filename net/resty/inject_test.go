@@ -0,0 +1,52 @@
+package resty_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+	"github.com/yocover/global-toolkit/net/rpc"
+)
+
+func TestInjectRestyHeaders(t *testing.T) {
+	var received http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	ctx := rpc.SetRPCHeaders(context.Background(), map[string]string{
+		"x-request-id": "from-ctx",
+		"x-ctx-tenant": "acme",
+		"x-internal":   "secret",
+	})
+	client := resty.New()
+
+	// 按规则筛选，请求中已有的值优先
+	req := client.R().SetContext(ctx).SetHeader("X-Request-ID", "from-request")
+	InjectRestyHeaders(ctx, req, rpc.AllowHeaders("x-request-id"), rpc.AllowHeaderPrefixes("x-ctx-"))
+	_, err := req.Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-request", received.Get("X-Request-Id"))
+	assert.Equal(t, "acme", received.Get("X-Ctx-Tenant"))
+	assert.Empty(t, received.Values("X-Internal"))
+
+	// 覆盖模式
+	req = client.R().SetContext(ctx).SetHeader("X-Request-ID", "from-request")
+	InjectRestyHeaders(ctx, req, rpc.OverrideHeaders())
+	_, err = req.Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"from-ctx"}, received.Values("X-Request-Id"))
+	assert.Equal(t, "secret", received.Get("X-Internal"))
+
+	// 上下文中没有 headers，请求为 nil
+	req = client.R().SetHeader("Accept", "application/json")
+	InjectRestyHeaders(context.Background(), req)
+	assert.Equal(t, http.Header{"Accept": {"application/json"}}, req.Header)
+	assert.NotPanics(t, func() { InjectRestyHeaders(ctx, nil) })
+}
@@ -0,0 +1,332 @@
+package resty
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// ErrTruncatedResponse 读取响应体时连接中途断开，或收到的字节数少于声明的 Content-Length
+//
+// 响应体长度完整（或未声明长度）但 JSON 不完整时视为格式错误，不返回该错误。
+// 截断的响应重试后可能成功，而格式错误的 JSON 重试也不会成功，可以通过 errors.Is 区分两者。
+var ErrTruncatedResponse = errors.New("truncated response")
+
+// GetJSONMap 发送 GET 请求并将 JSON 响应解析为 map
+//
+// 与 json.Unmarshal 不同，这里使用 json.Decoder 的 UseNumber 解析，
+// 数字会以 json.Number 保留原始文本，避免 64 位整型 ID 转为 float64 后丢失精度。
+// 响应的顶层值不是 JSON 对象时返回 ErrJSONTypeMismatch，需要接受任意顶层值时使用 GetJSONValue。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//   - timeout: 请求超时时间（秒）
+//
+// 返回值:
+//   - map[string]interface{}: 解析后的 JSON 对象，数字类型为 json.Number
+//   - error: 请求错误、JSON 解析错误或 ErrJSONTypeMismatch，如果成功则为 nil
+//
+// 示例:
+//
+//	data, err := GetJSONMap("https://api.example.com/user", nil, 30)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	id, _ := data["id"].(json.Number).Int64()
+func GetJSONMap(url string, header map[string]string, timeout int64) (map[string]interface{}, error) {
+	resp, err := GetWithTimeOut(url, header, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONMap(resp)
+}
+
+// PostJSONMap 发送 POST 请求并将 JSON 响应解析为 map
+//
+// 解析方式与 GetJSONMap 相同。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - body: 请求体内容，可以是任意类型
+//   - header: 自定义的 HTTP 请求头
+//   - timeout: 请求超时时间（秒）
+//
+// 返回值:
+//   - map[string]interface{}: 解析后的 JSON 对象，数字类型为 json.Number
+//   - error: 请求错误、JSON 解析错误或 ErrJSONTypeMismatch，如果成功则为 nil
+//
+// 示例:
+//
+//	headers := map[string]string{"Content-Type": "application/json"}
+//	data, err := PostJSONMap("https://api.example.com/search", body, headers, 30)
+func PostJSONMap(url string, body interface{}, header map[string]string, timeout int64) (map[string]interface{}, error) {
+	resp, err := PostWithTimeOut(url, body, header, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONMap(resp)
+}
+
+// GetJSONValue 发送 GET 请求并解析任意顶层类型的 JSON 响应
+//
+// 解析结果的类型为 map[string]interface{}、[]interface{}、string、json.Number、bool 或 nil。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//   - timeout: 请求超时时间（秒）
+//
+// 返回值:
+//   - interface{}: 解析后的 JSON 值，数字类型为 json.Number
+//   - error: 请求错误或 JSON 解析错误，如果成功则为 nil
+//
+// 示例:
+//
+//	value, err := GetJSONValue("https://api.example.com/users", nil, 30)
+//	users, ok := value.([]interface{})
+func GetJSONValue(url string, header map[string]string, timeout int64) (interface{}, error) {
+	resp, err := GetWithTimeOut(url, header, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONValue(resp)
+}
+
+// GetJSONArray 发送 GET 请求，并将顶层为数组的 JSON 响应逐个元素解析为 []T
+//
+// 响应体通过 json.Decoder 边读取边解析，不会先把整个响应体读入内存，
+// 适合分块传输的大数组，内存占用只取决于解析结果本身。解析方式与 GetJSONMap 相同，
+// 元素为 interface{} 时数字以 json.Number 保留。响应为 null 时返回 nil。
+// 流式读取的时长不确定，因此不设置整体超时，请通过 ctx 控制请求的生命周期。
+//
+// 参数:
+//   - ctx: 请求上下文，取消后请求和响应体读取都会终止
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []T: 解析后的数组
+//   - error: 非 2xx 状态码、顶层不是数组（ErrJSONTypeMismatch）、响应体被截断（ErrTruncatedResponse）
+//     或元素解析失败时返回错误，错误信息包含出错元素的下标
+//
+// 示例:
+//
+//	type Event struct {
+//	    ID   int64  `json:"id"`
+//	    Type string `json:"type"`
+//	}
+//	events, err := GetJSONArray[Event](ctx, "https://api.example.com/events/export", nil)
+func GetJSONArray[T any](ctx context.Context, url string, header map[string]string) ([]T, error) {
+	res, err := DoRaw(ctx, http.MethodGet, url, WithHeaders(header), WithTimeout(0))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if StatusClass(res.StatusCode) != ClassSuccess {
+		return nil, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	items, err := decodeJSONArray[T](res.Body)
+	if err != nil {
+		zap.L().Error("Json Transform Error", zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// decodeJSONArray 逐个元素解析顶层为数组的 JSON 文档，文档中只能包含一个顶层值
+func decodeJSONArray[T any](r io.Reader) ([]T, error) {
+	body := &readErrorRecorder{r: r}
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, arrayError(0, err, body.err)
+	}
+	var items []T
+	switch token {
+	case json.Delim('['):
+		items = []T{}
+		for decoder.More() {
+			var item T
+			if err := decoder.Decode(&item); err != nil {
+				return nil, arrayError(len(items), err, body.err)
+			}
+			items = append(items, item)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, arrayError(len(items), err, body.err)
+		}
+	case nil:
+	default:
+		return nil, fmt.Errorf("%w: expected array, got %s", ErrJSONTypeMismatch, tokenKind(token))
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("invalid data after top-level JSON value")
+	}
+	return items, nil
+}
+
+// arrayError 为解析数组时的错误标明出错的元素下标，读取响应体失败时包装为 ErrTruncatedResponse
+//
+// 响应体完整读取但数组没有结束时视为格式错误。
+func arrayError(index int, err, readErr error) error {
+	if readErr != nil {
+		return fmt.Errorf("%w: json array ended after %d elements: %w", ErrTruncatedResponse, index, readErr)
+	}
+	return fmt.Errorf("invalid json array element %d: %w", index, err)
+}
+
+// tokenKind 返回 json.Decoder 顶层 token 对应的 JSON 类型
+func tokenKind(token json.Token) string {
+	switch token.(type) {
+	case json.Delim:
+		return "object"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	}
+	return "number"
+}
+
+// decodeJSONMap 解析顶层为对象的 JSON 文档
+func decodeJSONMap(data []byte) (map[string]interface{}, error) {
+	value, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: expected object, got %s", ErrJSONTypeMismatch, jsonKind(data))
+	}
+	return result, nil
+}
+
+// decodeJSONValue 使用 UseNumber 解析 JSON 文档，文档中只能包含一个顶层值
+func decodeJSONValue(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var result interface{}
+	err := decoder.Decode(&result)
+	if err == nil {
+		if _, extra := decoder.Token(); !errors.Is(extra, io.EOF) {
+			err = errors.New("invalid data after top-level JSON value")
+		}
+	}
+	if err != nil {
+		zap.L().Error("Json Transform Error", zap.Error(err))
+		return nil, err
+	}
+	return result, nil
+}
+
+// decodeEntity 将 JSON 文档解析到 entity 指向的对象中
+//
+// 解析前先比较文档顶层值的类型与 entity 的类型，不匹配时返回描述两者的 ErrJSONTypeMismatch，
+// 而不是 encoding/json 的底层错误。null 与任意类型兼容，解析后 entity 保持不变。
+func decodeEntity(data []byte, entity interface{}) error {
+	value := reflect.ValueOf(entity)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("entity must be a non-nil pointer, got %T", entity)
+	}
+
+	if expected := expectedJSONKind(value.Type().Elem()); expected != "" {
+		if actual := jsonKind(data); actual != expected && actual != "null" && actual != "empty" {
+			return fmt.Errorf("%w: expected JSON %s for %T but got %s", ErrJSONTypeMismatch, expected, entity, actual)
+		}
+	}
+	return json.Unmarshal(data, entity)
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonNumberType      = reflect.TypeOf(json.Number(""))
+)
+
+// expectedJSONKind 返回类型 t 对应的 JSON 类型，可以接受多种 JSON 类型时返回空字符串
+func expectedJSONKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == jsonNumberType {
+		return "number"
+	}
+	// 自定义解析的类型自行决定接受哪些 JSON 类型
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return ""
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice:
+		// []byte 以 base64 字符串表示
+		if t.Elem().Kind() == reflect.Uint8 {
+			return ""
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return ""
+}
+
+// truncatedError 响应体短于声明的 Content-Length 时，将解析错误包装为 ErrTruncatedResponse，其他错误原样返回
+//
+// contentLength 为声明的 Content-Length，小于 0 表示未知。长度完整或未知的响应体（包括空响应体）
+// 解析失败时视为格式错误，而不是截断。
+func truncatedError(body []byte, contentLength int64, err error) error {
+	if err == nil || contentLength < 0 || int64(len(body)) >= contentLength {
+		return err
+	}
+	return fmt.Errorf("%w: received %d of %d bytes: %w", ErrTruncatedResponse, len(body), contentLength, err)
+}
+
+// truncatedReadError 将读取响应体时连接提前断开的错误包装为 ErrTruncatedResponse
+func truncatedReadError(res *resty.Response, err error) error {
+	if err == nil || res == nil || res.RawResponse == nil || !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	if contentLength := res.RawResponse.ContentLength; contentLength >= 0 {
+		return fmt.Errorf("%w: received %d of %d bytes: %w", ErrTruncatedResponse, len(res.Body()), contentLength, err)
+	}
+	return fmt.Errorf("%w: received %d bytes: %w", ErrTruncatedResponse, len(res.Body()), err)
+}
+
+// readErrorRecorder 记录读取响应体时发生的错误（io.EOF 除外），用于区分连接中断和 JSON 本身不完整
+type readErrorRecorder struct {
+	r   io.Reader
+	err error
+}
+
+// Read 实现 io.Reader
+func (r *readErrorRecorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,429 @@
+package resty_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetJSONMap(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"id":9007199254740993,"name":"test","score":1.5}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	headers := map[string]string{
+		"Authorization": "test-token",
+	}
+	data, err := GetJSONMap(ts.URL+"/test", headers, 30)
+	assert.NoError(t, err)
+
+	// 大整数应以 json.Number 保留原始精度
+	id, ok := data["id"].(json.Number)
+	assert.True(t, ok, "id should be json.Number")
+	value, err := id.Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9007199254740993), value)
+
+	assert.Equal(t, "test", data["name"])
+	assert.Equal(t, json.Number("1.5"), data["score"])
+}
+
+func TestGetJSONMapInvalidJSON(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"id":`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	data, err := GetJSONMap(ts.URL+"/test", nil, 30)
+	assert.Error(t, err)
+	assert.Nil(t, data)
+}
+
+func TestGetJSONMapNotObject(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "array", body: `[{"id":1}]`},
+		{name: "number", body: `9007199254740993`},
+		{name: "string", body: `"test"`},
+		{name: "null", body: `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				_, err := io.WriteString(w, tt.body)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer ts.Close()
+
+			data, err := GetJSONMap(ts.URL, nil, 30)
+			assert.ErrorIs(t, err, ErrJSONTypeMismatch)
+			assert.Nil(t, data)
+		})
+	}
+}
+
+func TestPostJSONMap(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, `{"name":"test"}`, string(body))
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.WriteString(w, `{"id":9007199254740993,"created":true}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	data, err := PostJSONMap(ts.URL, `{"name":"test"}`, headers, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("9007199254740993"), data["id"])
+	assert.Equal(t, true, data["created"])
+}
+
+func TestGetJSONValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected interface{}
+	}{
+		{name: "object", body: `{"id":1}`, expected: map[string]interface{}{"id": json.Number("1")}},
+		{name: "array", body: `[9007199254740993,"a"]`, expected: []interface{}{json.Number("9007199254740993"), "a"}},
+		{name: "number", body: `18446744073709551615`, expected: json.Number("18446744073709551615")},
+		{name: "string", body: `"test"`, expected: "test"},
+		{name: "null", body: `null`, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				_, err := io.WriteString(w, tt.body)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer ts.Close()
+
+			value, err := GetJSONValue(ts.URL, nil, 30)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestGetJSONValueInvalidJSON(t *testing.T) {
+	for _, body := range []string{`[1,`, `{"id":1} {"id":2}`, ``} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, err := io.WriteString(w, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}))
+
+		value, err := GetJSONValue(ts.URL, nil, 30)
+		assert.Error(t, err, body)
+		assert.NotErrorIs(t, err, ErrJSONTypeMismatch, body)
+		assert.Nil(t, value)
+		ts.Close()
+	}
+}
+
+// entityServer 对 GET 和 POST 请求都返回固定的 JSON 响应体
+func entityServer(t *testing.T, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+}
+
+func TestEntityTopLevelTypes(t *testing.T) {
+	variants := map[string]func(url string, entity interface{}) error{
+		"get": func(url string, entity interface{}) error {
+			return GetWithEntity(url, entity, nil, 30)
+		},
+		"post": func(url string, entity interface{}) error {
+			return PostWithEntity(url, map[string]string{"q": "x"}, nil, entity, 30)
+		},
+	}
+
+	for name, call := range variants {
+		t.Run(name, func(t *testing.T) {
+			t.Run("array", func(t *testing.T) {
+				ts := entityServer(t, `[{"status":"ok","data":"1"},{"status":"ok","data":"2"}]`)
+				defer ts.Close()
+
+				var entity []TestResponse
+				assert.NoError(t, call(ts.URL, &entity))
+				assert.Equal(t, []TestResponse{{Status: "ok", Data: "1"}, {Status: "ok", Data: "2"}}, entity)
+			})
+
+			t.Run("string", func(t *testing.T) {
+				ts := entityServer(t, `"token-123"`)
+				defer ts.Close()
+
+				var entity string
+				assert.NoError(t, call(ts.URL, &entity))
+				assert.Equal(t, "token-123", entity)
+			})
+
+			t.Run("number", func(t *testing.T) {
+				ts := entityServer(t, `42`)
+				defer ts.Close()
+
+				var entity int
+				assert.NoError(t, call(ts.URL, &entity))
+				assert.Equal(t, 42, entity)
+			})
+
+			t.Run("mismatch", func(t *testing.T) {
+				ts := entityServer(t, `{"status":"ok"}`)
+				defer ts.Close()
+
+				var entity []TestResponse
+				err := call(ts.URL, &entity)
+				assert.ErrorIs(t, err, ErrJSONTypeMismatch)
+				assert.EqualError(t, err, "json type mismatch: expected JSON array for *[]resty_test.TestResponse but got object")
+
+				var count int
+				assert.ErrorIs(t, call(ts.URL, &count), ErrJSONTypeMismatch)
+			})
+
+			t.Run("array into struct", func(t *testing.T) {
+				ts := entityServer(t, `[{"status":"ok"}]`)
+				defer ts.Close()
+
+				var entity TestResponse
+				err := call(ts.URL, &entity)
+				assert.EqualError(t, err, "json type mismatch: expected JSON object for *resty_test.TestResponse but got array")
+			})
+
+			t.Run("not a pointer", func(t *testing.T) {
+				ts := entityServer(t, `{"status":"ok"}`)
+				defer ts.Close()
+
+				assert.EqualError(t, call(ts.URL, TestResponse{}), "entity must be a non-nil pointer, got resty_test.TestResponse")
+			})
+		})
+	}
+}
+
+func TestEntityTruncatedResponse(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	// 声明了 Content-Length，连接在发送完之前断开
+	url := misbehavingServer(t, func(conn net.Conn) {
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 40\r\n\r\n{\"id\":1,\"na")
+	})
+	var u user
+	err := GetWithEntity(url, &u, nil, 5)
+	assert.ErrorIs(t, err, ErrTruncatedResponse)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.ErrorContains(t, err, "received 11 of 40 bytes")
+
+	// 分块传输在结束块之前断开
+	url = misbehavingServer(t, func(conn net.Conn) {
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nTransfer-Encoding: chunked\r\n\r\nb\r\n{\"id\":1,\"na\r\n")
+	})
+	err = GetWithEntity(url, &u, nil, 5)
+	assert.ErrorIs(t, err, ErrTruncatedResponse)
+	assert.ErrorContains(t, err, "received 11 bytes")
+	_, err = GetJSONMap(url, nil, 5)
+	assert.ErrorIs(t, err, ErrTruncatedResponse)
+	_, err = CallJSON[user, struct{}](http.MethodGet, url, nil, nil)
+	assert.ErrorIs(t, err, ErrTruncatedResponse)
+
+	// 格式错误的 JSON 不视为截断
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"id":1,"name":}`)
+	}))
+	defer ts.Close()
+	err = GetWithEntity(ts.URL, &u, nil, 5)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrTruncatedResponse)
+	_, err = GetJSONMap(ts.URL, nil, 5)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrTruncatedResponse)
+}
+
+func TestEntityIncompleteJSONNotTruncated(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name     string
+		response string
+	}{
+		// 响应体与 Content-Length 一致，只是 JSON 本身不完整
+		{"full length", "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 11\r\n\r\n{\"id\":1,\"na"},
+		// 没有 Content-Length，服务端正常关闭连接
+		{"no content length", "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nConnection: close\r\n\r\n{\"id\":1,\"na"},
+		// 没有响应体
+		{"no content", "HTTP/1.1 204 No Content\r\n\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := misbehavingServer(t, func(conn net.Conn) {
+				_, _ = io.WriteString(conn, tt.response)
+			})
+			var u user
+			err := GetWithEntity(url, &u, nil, 5)
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, ErrTruncatedResponse)
+			assert.NotContains(t, err.Error(), "truncated")
+		})
+	}
+}
+
+func TestGetJSONArray(t *testing.T) {
+	type event struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		// 分块发送，每个元素单独刷新
+		flusher := w.(http.Flusher)
+		_, _ = io.WriteString(w, "[")
+		for i := 1; i <= 3; i++ {
+			if i > 1 {
+				_, _ = io.WriteString(w, ",")
+			}
+			_, _ = fmt.Fprintf(w, `{"id":%d,"type":"click"}`, i)
+			flusher.Flush()
+		}
+		_, _ = io.WriteString(w, "]\n")
+	}))
+	defer ts.Close()
+
+	events, err := GetJSONArray[event](context.Background(), ts.URL, map[string]string{"Authorization": "test-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, []event{{1, "click"}, {2, "click"}, {3, "click"}}, events)
+}
+
+func TestGetJSONArrayValues(t *testing.T) {
+	tests := []struct {
+		body string
+		want []interface{}
+	}{
+		{`[]`, []interface{}{}},
+		{`null`, nil},
+		// 数字以 json.Number 保留
+		{`[9007199254740993, "a", true, null, {"k": [1]}]`, []interface{}{
+			json.Number("9007199254740993"), "a", true, nil,
+			map[string]interface{}{"k": []interface{}{json.Number("1")}},
+		}},
+	}
+	for _, tt := range tests {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, tt.body)
+		}))
+		values, err := GetJSONArray[interface{}](context.Background(), ts.URL, nil)
+		assert.NoError(t, err, tt.body)
+		assert.Equal(t, tt.want, values, tt.body)
+		ts.Close()
+	}
+}
+
+func TestGetJSONArrayErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(t *testing.T, err error)
+	}{
+		{"not an array", http.StatusOK, `{"items":[]}`, func(t *testing.T, err error) {
+			assert.ErrorIs(t, err, ErrJSONTypeMismatch)
+			assert.ErrorContains(t, err, "expected array, got object")
+		}},
+		{"malformed element", http.StatusOK, `[1, 2, x]`, func(t *testing.T, err error) {
+			assert.ErrorContains(t, err, "invalid json array element 2")
+			assert.NotErrorIs(t, err, ErrTruncatedResponse)
+		}},
+		{"element type mismatch", http.StatusOK, `[1, "two"]`, func(t *testing.T, err error) {
+			assert.ErrorContains(t, err, "invalid json array element 1")
+		}},
+		{"incomplete", http.StatusOK, `[1, 2, 3`, func(t *testing.T, err error) {
+			assert.NotErrorIs(t, err, ErrTruncatedResponse)
+			assert.ErrorContains(t, err, "invalid json array element 3")
+		}},
+		{"trailing data", http.StatusOK, `[1] [2]`, func(t *testing.T, err error) {
+			assert.ErrorContains(t, err, "invalid data after top-level JSON value")
+		}},
+		{"error status", http.StatusInternalServerError, `[]`, func(t *testing.T, err error) {
+			assert.ErrorContains(t, err, "unexpected status code: 500")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer ts.Close()
+			values, err := GetJSONArray[int](context.Background(), ts.URL, nil)
+			assert.Nil(t, values)
+			tt.check(t, err)
+		})
+	}
+
+	// 连接在声明的 Content-Length 之前断开
+	url := misbehavingServer(t, func(conn net.Conn) {
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 20\r\n\r\n[1, 2, 3")
+	})
+	_, err := GetJSONArray[int](context.Background(), url, nil)
+	assert.ErrorIs(t, err, ErrTruncatedResponse)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.ErrorContains(t, err, "after 2 elements")
+
+	// ctx 取消时中止读取
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "[1,")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = GetJSONArray[int](ctx, ts.URL, nil)
+	assert.Error(t, err)
+}
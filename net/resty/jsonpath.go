@@ -0,0 +1,347 @@
+package resty
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSON 字段提取相关的错误
+var (
+	// ErrPathNotFound 路径在 JSON 文档中不存在，具体位置见 *PathError
+	ErrPathNotFound = errors.New("json path not found")
+	// ErrJSONTypeMismatch JSON 值的类型与期望的类型不一致
+	ErrJSONTypeMismatch = errors.New("json type mismatch")
+	// ErrInvalidJSONPath 路径的语法不合法
+	ErrInvalidJSONPath = errors.New("invalid json path")
+)
+
+// PathError 路径解析失败时返回的错误，可通过 errors.Is(err, ErrPathNotFound) 判断
+type PathError struct {
+	// Path 完整的查询路径
+	Path string
+	// Resolved 已成功解析的最深路径前缀，根节点即不存在时为空
+	Resolved string
+	// Segment 第一个无法解析的路径段
+	Segment string
+}
+
+// Error 实现 error 接口
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s: %q (resolved %q, missing %q)", ErrPathNotFound, e.Path, e.Resolved, e.Segment)
+}
+
+// Unwrap 返回 ErrPathNotFound
+func (e *PathError) Unwrap() error {
+	return ErrPathNotFound
+}
+
+// jsonPathSegment 解析后的单个路径段
+type jsonPathSegment struct {
+	// key 对象的键名或数组的下标
+	key string
+	// end 该路径段在原始路径中的结束位置
+	end int
+}
+
+// GetJSONField 发送 GET 请求，并从 JSON 响应中提取单个字段
+//
+// 路径语法见 ExtractJSONField。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - path: 字段路径，如 "data.items.0.id"
+//   - header: 自定义的 HTTP 请求头
+//   - timeout: 请求超时时间（秒）
+//
+// 返回值:
+//   - json.RawMessage: 字段的原始 JSON 值
+//   - error: 请求错误、JSON 解析错误或 *PathError，如果成功则为 nil
+//
+// 示例:
+//
+//	raw, err := GetJSONField("https://api.example.com/items", "data.items[0].id", nil, 30)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	id, err := AsInt64(raw)
+func GetJSONField(url string, path string, header map[string]string, timeout int64) (json.RawMessage, error) {
+	resp, err := GetWithTimeOut(url, header, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractJSONField(resp, path)
+}
+
+// GetJSONPath 发送 GET 请求，并返回 JSON 响应中路径对应的值
+//
+// 路径语法与 ExtractJSONField 相同，也可以使用 JSONPath 风格的 "$" 根节点前缀，
+// 如 "$.data.items[0].id"，单独的 "$" 返回整个文档；不支持通配符、切片和过滤表达式。
+// 返回值的类型与 GetJSONValue 一致，数字为 json.Number。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//   - path: 字段路径
+//
+// 返回值:
+//   - interface{}: 路径对应的值，类型为 map[string]interface{}、[]interface{}、string、json.Number、bool 或 nil
+//   - error: 请求错误、JSON 解析错误、ErrInvalidJSONPath 或 *PathError（路径不存在），如果成功则为 nil
+//
+// 示例:
+//
+//	value, err := GetJSONPath("https://api.example.com/orders/1", nil, "$.data.items[0].sku")
+//	if errors.Is(err, ErrPathNotFound) {
+//	    // 字段不存在
+//	}
+func GetJSONPath(url string, header map[string]string, path string) (interface{}, error) {
+	resp, err := GetWithHeaders(url, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// 去掉 "$" 根节点前缀，路径不存在时在错误中保留原始路径
+	trimmed := path
+	if strings.HasPrefix(path, "$") {
+		trimmed = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	}
+	raw, err := ExtractJSONField(resp, trimmed)
+	if err != nil {
+		var pathErr *PathError
+		if errors.As(err, &pathErr) {
+			pathErr.Resolved = path[:len(path)-len(trimmed)] + pathErr.Resolved
+			pathErr.Path = path
+		} else if errors.Is(err, ErrInvalidJSONPath) {
+			err = fmt.Errorf("%w: %q", ErrInvalidJSONPath, path)
+		}
+		return nil, err
+	}
+	return decodeJSONValue(raw)
+}
+
+// ExtractJSONField 从 JSON 文档中提取路径对应的字段
+//
+// 路径由 "." 分隔的路径段组成，也可以使用方括号：
+//   - "data.items.0.id" 与 "data.items[0].id" 等价，数字路径段在数组上作为下标，在对象上作为键名
+//   - 包含 "." 的键名可以写成 `data["a.b"]`、`data['a.b']` 或 `data.a\.b`
+//   - 空路径返回整个文档
+//
+// 返回的是字段的原始 JSON 文本，数字不会丢失精度；字段值为 null 时返回 "null"。
+//
+// 参数:
+//   - body: JSON 文档
+//   - path: 字段路径
+//
+// 返回值:
+//   - json.RawMessage: 字段的原始 JSON 值
+//   - error: JSON 解析错误、路径语法错误或 *PathError，如果成功则为 nil
+//
+// 示例:
+//
+//	raw, err := ExtractJSONField([]byte(`{"data":{"name":"test"}}`), "data.name")
+//	name, err := AsString(raw) // "test"
+func ExtractJSONField(body []byte, path string) (json.RawMessage, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var current json.RawMessage
+	if err = json.Unmarshal(body, &current); err != nil {
+		return nil, err
+	}
+
+	resolved := 0
+	for _, segment := range segments {
+		next, ok := jsonChild(current, segment.key)
+		if !ok {
+			return nil, &PathError{Path: path, Resolved: path[:resolved], Segment: segment.key}
+		}
+		current = next
+		resolved = segment.end
+	}
+	return current, nil
+}
+
+// parseJSONPath 将路径解析为路径段
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	var (
+		segments []jsonPathSegment
+		key      strings.Builder
+		started  bool
+		// afterBracket 上一个路径段以方括号结束，其后的 "." 只起分隔作用
+		afterBracket bool
+	)
+	invalid := func() ([]jsonPathSegment, error) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidJSONPath, path)
+	}
+
+	for i := 0; i < len(path); {
+		switch c := path[i]; c {
+		case '\\':
+			if i+1 >= len(path) {
+				return invalid()
+			}
+			key.WriteByte(path[i+1])
+			started = true
+			i += 2
+		case '.':
+			if !started && !afterBracket {
+				return invalid()
+			}
+			if started {
+				segments = append(segments, jsonPathSegment{key: key.String(), end: i})
+				key.Reset()
+				started = false
+			}
+			afterBracket = false
+			i++
+		case '[':
+			if started {
+				segments = append(segments, jsonPathSegment{key: key.String(), end: i})
+				key.Reset()
+				started = false
+			}
+			name, next, ok := parseJSONPathBracket(path, i)
+			if !ok {
+				return invalid()
+			}
+			segments = append(segments, jsonPathSegment{key: name, end: next})
+			afterBracket = true
+			i = next
+		default:
+			if afterBracket {
+				return invalid()
+			}
+			key.WriteByte(c)
+			started = true
+			i++
+		}
+	}
+
+	if started {
+		segments = append(segments, jsonPathSegment{key: key.String(), end: len(path)})
+	} else if len(path) > 0 && !afterBracket {
+		// 以 "." 结尾
+		return invalid()
+	}
+	return segments, nil
+}
+
+// parseJSONPathBracket 解析从 start 开始的方括号路径段，返回键名和 "]" 之后的位置
+func parseJSONPathBracket(path string, start int) (string, int, bool) {
+	i := start + 1
+	if i >= len(path) {
+		return "", 0, false
+	}
+
+	quote := path[i]
+	if quote != '"' && quote != '\'' {
+		end := strings.IndexByte(path[i:], ']')
+		if end <= 0 {
+			return "", 0, false
+		}
+		return path[i : i+end], i + end + 1, true
+	}
+
+	var key strings.Builder
+	for i++; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			if i+1 >= len(path) {
+				return "", 0, false
+			}
+			i++
+			key.WriteByte(path[i])
+		case quote:
+			if i+1 >= len(path) || path[i+1] != ']' {
+				return "", 0, false
+			}
+			return key.String(), i + 2, true
+		default:
+			key.WriteByte(path[i])
+		}
+	}
+	return "", 0, false
+}
+
+// jsonChild 获取 JSON 对象的字段或 JSON 数组的元素
+func jsonChild(raw json.RawMessage, key string) (json.RawMessage, bool) {
+	switch jsonKind(raw) {
+	case "object":
+		var object map[string]json.RawMessage
+		if json.Unmarshal(raw, &object) != nil {
+			return nil, false
+		}
+		value, ok := object[key]
+		return value, ok
+	case "array":
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 {
+			return nil, false
+		}
+		var array []json.RawMessage
+		if json.Unmarshal(raw, &array) != nil || index >= len(array) {
+			return nil, false
+		}
+		return array[index], true
+	}
+	return nil, false
+}
+
+// jsonKind 根据首个非空白字符判断 JSON 值的类型
+func jsonKind(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return "empty"
+	}
+	switch raw[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// AsString 将 JSON 值转换为字符串，值不是 JSON 字符串时返回 ErrJSONTypeMismatch
+func AsString(raw json.RawMessage) (string, error) {
+	if kind := jsonKind(raw); kind != "string" {
+		return "", fmt.Errorf("%w: expected string, got %s", ErrJSONTypeMismatch, kind)
+	}
+	var value string
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+// AsInt64 将 JSON 值转换为 int64，值不是 JSON 整数时返回 ErrJSONTypeMismatch
+func AsInt64(raw json.RawMessage) (int64, error) {
+	if kind := jsonKind(raw); kind != "number" {
+		return 0, fmt.Errorf("%w: expected integer, got %s", ErrJSONTypeMismatch, kind)
+	}
+	value, err := strconv.ParseInt(string(bytes.TrimSpace(raw)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: expected integer, got %s", ErrJSONTypeMismatch, bytes.TrimSpace(raw))
+	}
+	return value, nil
+}
+
+// AsBool 将 JSON 值转换为 bool，值不是 JSON 布尔值时返回 ErrJSONTypeMismatch
+func AsBool(raw json.RawMessage) (bool, error) {
+	if kind := jsonKind(raw); kind != "bool" {
+		return false, fmt.Errorf("%w: expected bool, got %s", ErrJSONTypeMismatch, kind)
+	}
+	var value bool
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
@@ -0,0 +1,189 @@
+package resty_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+const jsonPathDoc = `{
+	"data": {
+		"items": [{"id": 9007199254740993, "tags": ["a", "b"]}, {"id": 2, "deleted": true}],
+		"owner": null,
+		"a.b": {"c": "dotted"},
+		"0": "zero key"
+	},
+	"ok": true
+}`
+
+func TestExtractJSONField(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "array index by dot", path: "data.items.0.id", expected: `9007199254740993`},
+		{name: "array index by bracket", path: "data.items[1].id", expected: `2`},
+		{name: "nested array", path: "data.items[0].tags[1]", expected: `"b"`},
+		{name: "whole array", path: "data.items.0.tags", expected: `["a", "b"]`},
+		{name: "null value", path: "data.owner", expected: `null`},
+		{name: "bool value", path: "ok", expected: `true`},
+		{name: "numeric key on object", path: "data.0", expected: `"zero key"`},
+		{name: "escaped dot", path: `data.a\.b.c`, expected: `"dotted"`},
+		{name: "double quoted key", path: `data["a.b"].c`, expected: `"dotted"`},
+		{name: "single quoted key", path: `data['a.b']["c"]`, expected: `"dotted"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := ExtractJSONField([]byte(jsonPathDoc), tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(raw))
+		})
+	}
+}
+
+func TestExtractJSONFieldNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		resolved string
+		segment  string
+	}{
+		{name: "missing root key", path: "missing", resolved: "", segment: "missing"},
+		{name: "missing nested key", path: "data.items.0.name", resolved: "data.items.0", segment: "name"},
+		{name: "index out of range", path: "data.items[5].id", resolved: "data.items", segment: "5"},
+		{name: "non-numeric index", path: "data.items.first", resolved: "data.items", segment: "first"},
+		{name: "traverse into null", path: "data.owner.name", resolved: "data.owner", segment: "name"},
+		{name: "traverse into scalar", path: "ok.value", resolved: "ok", segment: "value"},
+		// 未转义的 "." 总是作为分隔符，不会匹配到键名 "a.b"
+		{name: "unescaped dotted key", path: "data.a.b", resolved: "data", segment: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExtractJSONField([]byte(jsonPathDoc), tt.path)
+			assert.ErrorIs(t, err, ErrPathNotFound)
+
+			var pathErr *PathError
+			if assert.True(t, errors.As(err, &pathErr)) {
+				assert.Equal(t, tt.path, pathErr.Path)
+				assert.Equal(t, tt.resolved, pathErr.Resolved)
+				assert.Equal(t, tt.segment, pathErr.Segment)
+			}
+		})
+	}
+}
+
+func TestExtractJSONFieldInvalid(t *testing.T) {
+	for _, path := range []string{".data", "data..items", "data.", `data\`, "data[0", `data["a]`, "data[0]x", "data[]"} {
+		_, err := ExtractJSONField([]byte(jsonPathDoc), path)
+		assert.Error(t, err, path)
+		assert.ErrorIs(t, err, ErrInvalidJSONPath, path)
+		assert.NotErrorIs(t, err, ErrPathNotFound, path)
+	}
+
+	_, err := ExtractJSONField([]byte(`{"data":`), "data")
+	assert.Error(t, err)
+
+	// 空路径返回整个文档
+	raw, err := ExtractJSONField([]byte(`{"a":1}`), "")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(raw))
+}
+
+func TestJSONFieldCasts(t *testing.T) {
+	s, err := AsString(json.RawMessage(`"test"`))
+	assert.NoError(t, err)
+	assert.Equal(t, "test", s)
+	_, err = AsString(json.RawMessage(`null`))
+	assert.ErrorIs(t, err, ErrJSONTypeMismatch)
+	_, err = AsString(json.RawMessage(`1`))
+	assert.ErrorIs(t, err, ErrJSONTypeMismatch)
+
+	n, err := AsInt64(json.RawMessage(`9007199254740993`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9007199254740993), n)
+	_, err = AsInt64(json.RawMessage(`1.5`))
+	assert.ErrorIs(t, err, ErrJSONTypeMismatch)
+	_, err = AsInt64(json.RawMessage(`"1"`))
+	assert.ErrorIs(t, err, ErrJSONTypeMismatch)
+
+	b, err := AsBool(json.RawMessage(`true`))
+	assert.NoError(t, err)
+	assert.True(t, b)
+	_, err = AsBool(json.RawMessage(`"true"`))
+	assert.ErrorIs(t, err, ErrJSONTypeMismatch)
+}
+
+func TestGetJSONField(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, jsonPathDoc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	headers := map[string]string{"Authorization": "test-token"}
+	raw, err := GetJSONField(ts.URL, "data.items[1].deleted", headers, 30)
+	assert.NoError(t, err)
+	deleted, err := AsBool(raw)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	_, err = GetJSONField(ts.URL, "data.items[1].name", headers, 30)
+	assert.ErrorIs(t, err, ErrPathNotFound)
+}
+
+func TestGetJSONPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		_, _ = io.WriteString(w, jsonPathDoc)
+	}))
+	defer ts.Close()
+	headers := map[string]string{"Authorization": "test-token"}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{"$.data.items[0].id", json.Number("9007199254740993")},
+		{"data.items[0].tags", []interface{}{"a", "b"}},
+		{"$.data.items[1]", map[string]interface{}{"id": json.Number("2"), "deleted": true}},
+		{`$.data["a.b"].c`, "dotted"},
+		{"$.data.owner", nil},
+		{"$.ok", true},
+	}
+	for _, tt := range tests {
+		value, err := GetJSONPath(ts.URL, headers, tt.path)
+		assert.NoError(t, err, tt.path)
+		assert.Equal(t, tt.expected, value, tt.path)
+	}
+
+	// "$" 返回整个文档
+	value, err := GetJSONPath(ts.URL, headers, "$")
+	assert.NoError(t, err)
+	assert.Contains(t, value, "data")
+
+	// 路径不存在时保留原始路径
+	_, err = GetJSONPath(ts.URL, headers, "$.data.items[5].id")
+	var pathErr *PathError
+	if assert.ErrorAs(t, err, &pathErr) {
+		assert.Equal(t, "$.data.items[5].id", pathErr.Path)
+		assert.Equal(t, "$.data.items", pathErr.Resolved)
+		assert.Equal(t, "5", pathErr.Segment)
+	}
+
+	_, err = GetJSONPath(ts.URL, headers, "$.data[0")
+	assert.ErrorIs(t, err, ErrInvalidJSONPath)
+	assert.EqualError(t, err, `invalid json path: "$.data[0"`)
+}
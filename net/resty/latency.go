@@ -0,0 +1,102 @@
+package resty
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultLatencyReservoirSize 每个 host 保留的最近延迟样本数量
+const DefaultLatencyReservoirSize = 1024
+
+var (
+	latencyEnabled atomic.Bool
+	latencyMutex   sync.Mutex
+	latencyHosts   = make(map[string]*latencyReservoir)
+)
+
+// latencyReservoir 单个 host 的延迟样本，使用环形缓冲区只保留最近的样本
+type latencyReservoir struct {
+	samples []time.Duration
+	next    int
+	count   int64
+}
+
+// add 记录一个样本，缓冲区满时覆盖最旧的样本
+func (r *latencyReservoir) add(d time.Duration) {
+	if len(r.samples) < DefaultLatencyReservoirSize {
+		r.samples = append(r.samples, d)
+	} else {
+		r.samples[r.next] = d
+	}
+	r.next = (r.next + 1) % DefaultLatencyReservoirSize
+	r.count++
+}
+
+// EnableLatencyStats 开启按 host 统计请求延迟
+//
+// 开启后，包内创建的客户端在收到响应时记录延迟，每个 host 只保留最近
+// DefaultLatencyReservoirSize 个样本，内存占用有上限。请求出错时不记录。
+//
+// 示例:
+//
+//	EnableLatencyStats()
+//	p50, p95, p99, count := LatencyStats("api.example.com")
+func EnableLatencyStats() {
+	latencyEnabled.Store(true)
+}
+
+// LatencyStats 获取 host 最近请求的延迟分位数
+//
+// 参数:
+//   - host: 请求地址中的 host，包含非默认端口，如 "api.example.com"、"127.0.0.1:8080"
+//
+// 返回值:
+//   - p50: 最近样本的 50 分位延迟
+//   - p95: 最近样本的 95 分位延迟
+//   - p99: 最近样本的 99 分位延迟
+//   - count: 累计记录的请求数，没有记录时分位数和 count 都为 0
+func LatencyStats(host string) (p50, p95, p99 time.Duration, count int64) {
+	latencyMutex.Lock()
+	reservoir, ok := latencyHosts[host]
+	if !ok {
+		latencyMutex.Unlock()
+		return
+	}
+	samples := append([]time.Duration(nil), reservoir.samples...)
+	count = reservoir.count
+	latencyMutex.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return percentile(samples, 50), percentile(samples, 95), percentile(samples, 99), count
+}
+
+// percentile 使用最近秩法计算已排序样本的分位数
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// recordLatency 在客户端的 OnAfterResponse 阶段记录响应延迟
+func recordLatency(_ *resty.Client, res *resty.Response) error {
+	if !latencyEnabled.Load() || res.Request == nil || res.Request.RawRequest == nil {
+		return nil
+	}
+	host := res.Request.RawRequest.URL.Host
+
+	latencyMutex.Lock()
+	defer latencyMutex.Unlock()
+	reservoir, ok := latencyHosts[host]
+	if !ok {
+		reservoir = &latencyReservoir{}
+		latencyHosts[host] = reservoir
+	}
+	reservoir.add(res.Time())
+	return nil
+}
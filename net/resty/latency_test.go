@@ -0,0 +1,83 @@
+package resty_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestLatencyStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") == "1" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 未开启时不记录
+	_, err = Get(ts.URL)
+	assert.NoError(t, err)
+	_, _, _, count := LatencyStats(u.Host)
+	assert.Equal(t, int64(0), count)
+
+	EnableLatencyStats()
+	for i := 0; i < 98; i++ {
+		_, err = Get(ts.URL)
+		assert.NoError(t, err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err = Get(ts.URL + "?slow=1")
+		assert.NoError(t, err)
+	}
+
+	p50, p95, p99, count := LatencyStats(u.Host)
+	assert.Equal(t, int64(100), count)
+	assert.Less(t, p50, 50*time.Millisecond)
+	assert.Less(t, p95, 50*time.Millisecond)
+	assert.GreaterOrEqual(t, p99, 50*time.Millisecond)
+	assert.LessOrEqual(t, p50, p95)
+
+	// 未请求过的 host
+	p50, _, _, count = LatencyStats("unknown.example.com")
+	assert.Equal(t, time.Duration(0), p50)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestLatencyStatsBounded(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") == "1" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	EnableLatencyStats()
+	// 旧的慢请求样本被最近的样本覆盖
+	for i := 0; i < 5; i++ {
+		_, err = Get(ts.URL + "?slow=1")
+		assert.NoError(t, err)
+	}
+	for i := 0; i < DefaultLatencyReservoirSize; i++ {
+		_, err = Get(ts.URL)
+		assert.NoError(t, err)
+	}
+
+	_, _, p99, count := LatencyStats(u.Host)
+	assert.Equal(t, int64(DefaultLatencyReservoirSize+5), count)
+	assert.Less(t, p99, 20*time.Millisecond)
+}
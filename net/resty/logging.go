@@ -0,0 +1,173 @@
+package resty
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxLoggedBodyBytes 请求体和响应体日志的默认最大字节数
+const DefaultMaxLoggedBodyBytes = 4096
+
+// DefaultLoggedContentTypes 默认记录请求体和响应体的内容类型
+//
+// 以 "/" 结尾的项按主类型前缀匹配（如 "text/"），以 "+" 开头的项按结构化后缀匹配（如 "+json"），
+// 其余项要求完全匹配。application/octet-stream、图片等二进制类型默认不记录。
+var DefaultLoggedContentTypes = []string{
+	ContentTypeJson,
+	ContentTypeForm,
+	ContentTypeNDJSON,
+	"application/xml",
+	"text/",
+	"+json",
+	"+xml",
+}
+
+// BodyLogConfig 请求体和响应体的调试日志配置
+type BodyLogConfig struct {
+	// MaxLoggedBodyBytes 日志中最多记录的字节数，超出部分截断，为 0 时使用 DefaultMaxLoggedBodyBytes
+	MaxLoggedBodyBytes int
+	// ContentTypes 允许记录的内容类型，为 nil 时使用 DefaultLoggedContentTypes
+	ContentTypes []string
+}
+
+// WithBodyLogging 以 Debug 级别记录请求体和响应体
+//
+// 只有内容类型在白名单中的请求体和响应体会被记录，其余只记录内容类型和大小；
+// 超过 MaxLoggedBodyBytes 的部分会被截断，并注明原始大小。
+// 截断只作用于日志中的副本，不会影响发送的请求体和返回给调用方的响应体。
+// 高流量服务可以通过 SetLogSampling 只记录部分请求。
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodPost, "https://api.example.com",
+//	    WithJSONBody(body),
+//	    WithBodyLogging(BodyLogConfig{MaxLoggedBodyBytes: 1024}),
+//	)
+func WithBodyLogging(cfg BodyLogConfig) RequestOption {
+	return func(c *requestConfig) {
+		c.bodyLog = &cfg
+	}
+}
+
+// logRequestBody 记录即将发送的请求体，通过 GetBody 读取副本，不会消费请求体
+func (b *BodyLogConfig) logRequestBody(req *http.Request) {
+	ce := zap.L().Check(zap.DebugLevel, "HTTP Request Body")
+	if ce == nil || req.GetBody == nil {
+		return
+	}
+
+	rc, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	defer rc.Close()
+	body, err := io.ReadAll(rc)
+	if err != nil {
+		return
+	}
+
+	ce.Write(append([]zap.Field{
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.String()),
+	}, b.bodyFields(req.Header.Get(ContentType), body)...)...)
+}
+
+// logResponseBody 记录收到的响应体
+func (b *BodyLogConfig) logResponseBody(res *resty.Response) {
+	ce := zap.L().Check(zap.DebugLevel, "HTTP Response Body")
+	if ce == nil {
+		return
+	}
+
+	ce.Write(append([]zap.Field{
+		zap.String("method", res.Request.Method),
+		zap.String("url", res.Request.URL),
+		zap.Int("status", res.StatusCode()),
+	}, b.bodyFields(res.Header().Get(ContentType), res.Body())...)...)
+}
+
+// logError 记录没有得到完整响应的请求及其错误，如连接失败或读取响应体失败
+func (b *BodyLogConfig) logError(method, url string, err error) {
+	ce := zap.L().Check(zap.DebugLevel, "HTTP Request Error")
+	if ce == nil {
+		return
+	}
+
+	ce.Write(
+		zap.String("method", method),
+		zap.String("url", url),
+		zap.Error(err),
+	)
+}
+
+// logRawResponse 记录交给调用方读取的响应，只记录状态码和 header，不读取响应体
+func (b *BodyLogConfig) logRawResponse(res *http.Response) {
+	ce := zap.L().Check(zap.DebugLevel, "HTTP Response Body")
+	if ce == nil {
+		return
+	}
+
+	ce.Write(
+		zap.String("method", res.Request.Method),
+		zap.String("url", res.Request.URL.String()),
+		zap.Int("status", res.StatusCode),
+		zap.String("content_type", res.Header.Get(ContentType)),
+		zap.Int64("content_length", res.ContentLength),
+		zap.Any("header", res.Header),
+	)
+}
+
+// bodyFields 生成请求体或响应体的日志字段，内容类型不在白名单中时不记录内容
+func (b *BodyLogConfig) bodyFields(contentType string, body []byte) []zap.Field {
+	fields := []zap.Field{
+		zap.String("content_type", contentType),
+		zap.Int("size", len(body)),
+	}
+	if !b.loggable(contentType) {
+		return fields
+	}
+
+	limit := b.MaxLoggedBodyBytes
+	if limit <= 0 {
+		limit = DefaultMaxLoggedBodyBytes
+	}
+	if len(body) <= limit {
+		return append(fields, zap.String("body", string(body)))
+	}
+	return append(fields, zap.String("body", fmt.Sprintf("%s...(truncated, %d bytes total)", body[:limit], len(body))))
+}
+
+// loggable 判断内容类型是否在白名单中
+func (b *BodyLogConfig) loggable(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	allowed := b.ContentTypes
+	if allowed == nil {
+		allowed = DefaultLoggedContentTypes
+	}
+	for _, t := range allowed {
+		t = strings.ToLower(t)
+		switch {
+		case strings.HasSuffix(t, "/"):
+			if strings.HasPrefix(mediaType, t) {
+				return true
+			}
+		case strings.HasPrefix(t, "+"):
+			if strings.HasSuffix(mediaType, t) {
+				return true
+			}
+		case mediaType == t:
+			return true
+		}
+	}
+	return false
+}
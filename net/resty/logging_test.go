@@ -0,0 +1,126 @@
+package resty_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeLogs 将全局 logger 替换为记录 Debug 及以上级别日志的观察者
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	restore := zap.ReplaceGlobals(zap.New(core))
+	t.Cleanup(restore)
+	return logs
+}
+
+func TestWithBodyLoggingTruncate(t *testing.T) {
+	respBody := `{"data":"` + strings.Repeat("a", 100) + `"}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 发送的请求体不受截断影响
+		assert.Equal(t, `{"name":"`+strings.Repeat("b", 100)+`"}`, string(body))
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, err = io.WriteString(w, respBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	logs := observeLogs(t)
+	resp, err := Do(context.Background(), http.MethodPost, ts.URL,
+		WithJSONBody(map[string]string{"name": strings.Repeat("b", 100)}),
+		WithBodyLogging(BodyLogConfig{MaxLoggedBodyBytes: 16}),
+	)
+	assert.NoError(t, err)
+	// 返回给调用方的响应体不受截断影响
+	assert.Equal(t, []byte(respBody), resp.Body)
+
+	reqLogs := logs.FilterMessage("HTTP Request Body").All()
+	if assert.Len(t, reqLogs, 1) {
+		assert.Equal(t, `{"name":"bbbbbbb...(truncated, 111 bytes total)`, reqLogs[0].ContextMap()["body"])
+	}
+	resLogs := logs.FilterMessage("HTTP Response Body").All()
+	if assert.Len(t, resLogs, 1) {
+		assert.Equal(t, `{"data":"aaaaaaa...(truncated, 111 bytes total)`, resLogs[0].ContextMap()["body"])
+		assert.Equal(t, int64(http.StatusOK), resLogs[0].ContextMap()["status"])
+	}
+}
+
+func TestWithBodyLoggingSkipBinary(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n binary")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write(png)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	logs := observeLogs(t)
+	resp, err := Do(context.Background(), http.MethodPost, ts.URL,
+		WithHeaders(map[string]string{"Content-Type": "application/octet-stream"}),
+		WithBody(bytes.Repeat([]byte{0}, 32)),
+		WithBodyLogging(BodyLogConfig{}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, png, resp.Body)
+
+	for _, msg := range []string{"HTTP Request Body", "HTTP Response Body"} {
+		entries := logs.FilterMessage(msg).All()
+		if assert.Len(t, entries, 1, msg) {
+			fields := entries[0].ContextMap()
+			assert.NotContains(t, fields, "body", "binary body should not be logged")
+			assert.Contains(t, fields, "size")
+		}
+	}
+}
+
+func TestWithBodyLoggingContentTypes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := io.WriteString(w, `{"title":"bad"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	// 默认白名单按 +json 后缀匹配
+	logs := observeLogs(t)
+	_, err := Do(context.Background(), http.MethodGet, ts.URL, WithBodyLogging(BodyLogConfig{}))
+	assert.NoError(t, err)
+	entries := logs.FilterMessage("HTTP Response Body").All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, `{"title":"bad"}`, entries[0].ContextMap()["body"])
+	}
+
+	// 自定义白名单
+	logs = observeLogs(t)
+	_, err = Do(context.Background(), http.MethodGet, ts.URL, WithBodyLogging(BodyLogConfig{ContentTypes: []string{"text/"}}))
+	assert.NoError(t, err)
+	entries = logs.FilterMessage("HTTP Response Body").All()
+	if assert.Len(t, entries, 1) {
+		assert.NotContains(t, entries[0].ContextMap(), "body")
+	}
+}
@@ -0,0 +1,126 @@
+package resty
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// MultipartField multipart 请求中的一个字段，Reader 或 FileName 不为空时作为文件发送
+type MultipartField struct {
+	// Name 字段名称
+	Name string
+	// Value 普通字段的值，文件字段忽略
+	Value string
+	// FileName 文件名，不为空时作为文件字段发送
+	FileName string
+	// ContentType 字段的 Content-Type，文件字段为空时使用 application/octet-stream，普通字段为空时不发送
+	ContentType string
+	// Reader 文件内容，不为 nil 时作为文件字段发送
+	Reader io.Reader
+}
+
+// isFile 字段是否作为文件发送
+func (f MultipartField) isFile() bool {
+	return f.Reader != nil || f.FileName != ""
+}
+
+// quoteEscaper 转义 Content-Disposition 参数值中的反斜杠和双引号，与 mime/multipart 一致
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// WithMultipartFields 以 multipart/form-data 格式发送请求体，字段按切片顺序写入
+//
+// 与 WithFormData、WithFileReader 基于 map 的方式不同，字段顺序是确定的，
+// 适用于要求某些字段（如 token）出现在文件之前的接口。同名字段可以出现多次。
+// 请求体在首次发送前完整生成并缓存，文件内容会被读入内存，重试时不会再次读取 Reader。
+// Content-Type 由 multipart 的 boundary 决定，会覆盖请求头中的设置。
+func WithMultipartFields(fields []MultipartField) RequestOption {
+	return func(c *requestConfig) {
+		c.multipart = &multipartBody{fields: fields}
+	}
+}
+
+// multipartBody 有序的 multipart 请求体，生成后缓存以便重试
+type multipartBody struct {
+	fields      []MultipartField
+	data        []byte
+	contentType string
+}
+
+// encode 按顺序生成请求体，只在首次调用时读取文件内容
+func (m *multipartBody) encode() ([]byte, string, error) {
+	if m.data != nil {
+		return m.data, m.contentType, nil
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, field := range m.fields {
+		if err := writeMultipartField(writer, field); err != nil {
+			return nil, "", fmt.Errorf("multipart field %q: %w", field.Name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	m.data, m.contentType = buf.Bytes(), writer.FormDataContentType()
+	return m.data, m.contentType, nil
+}
+
+// writeMultipartField 写入单个字段
+func writeMultipartField(writer *multipart.Writer, field MultipartField) error {
+	disposition := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(field.Name))
+	contentType := field.ContentType
+	if field.isFile() {
+		disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(field.FileName))
+		if contentType == "" {
+			contentType = ContentTypeOctetStream
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", disposition)
+	if contentType != "" {
+		header.Set(ContentType, contentType)
+	}
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	if !field.isFile() {
+		_, err = io.WriteString(part, field.Value)
+		return err
+	}
+	if field.Reader != nil {
+		_, err = io.Copy(part, field.Reader)
+	}
+	return err
+}
+
+// PostMultipartOrdered 发送 multipart/form-data 格式的 POST 请求，字段按切片顺序写入请求体
+//
+// 参数:
+//   - url: 目标请求地址
+//   - fields: 按顺序写入的字段，详见 WithMultipartFields
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - err: 读取文件内容或请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	file, _ := os.Open("report.pdf")
+//	defer file.Close()
+//	resp, err := PostMultipartOrdered("https://legacy.example.com/upload", []MultipartField{
+//	    {Name: "token", Value: token},
+//	    {Name: "file", FileName: "report.pdf", ContentType: "application/pdf", Reader: file},
+//	}, nil)
+func PostMultipartOrdered(url string, fields []MultipartField, header map[string]string) (resp []byte, err error) {
+	return doBody(http.MethodPost, url, WithHeaders(header), WithMultipartFields(fields))
+}
@@ -0,0 +1,104 @@
+package resty_test
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// multipartPart 解析后的单个字段
+type multipartPart struct {
+	name        string
+	fileName    string
+	contentType string
+	content     string
+}
+
+// readMultipartParts 按请求体中的顺序解析所有字段
+func readMultipartParts(t *testing.T, r *http.Request) []multipartPart {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get(ContentType))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	var parts []multipartPart
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if !assert.NoError(t, err) {
+			return parts
+		}
+		content, _ := io.ReadAll(part)
+		parts = append(parts, multipartPart{
+			name:        part.FormName(),
+			fileName:    part.FileName(),
+			contentType: part.Header.Get(ContentType),
+			content:     string(content),
+		})
+	}
+}
+
+func TestPostMultipartOrdered(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, []multipartPart{
+			{name: "token", content: "abc"},
+			{name: "file", fileName: "report.pdf", contentType: "application/pdf", content: "%PDF-1.4"},
+			{name: "tag", content: "a"},
+			{name: "tag", content: "b"},
+			{name: "meta", contentType: ContentTypeJson, content: `{"v":1}`},
+			{name: "blob", fileName: `a "quoted".bin`, contentType: ContentTypeOctetStream, content: "raw"},
+		}, readMultipartParts(t, r))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	resp, err := PostMultipartOrdered(ts.URL, []MultipartField{
+		{Name: "token", Value: "abc"},
+		{Name: "file", FileName: "report.pdf", ContentType: "application/pdf", Reader: strings.NewReader("%PDF-1.4")},
+		{Name: "tag", Value: "a"},
+		{Name: "tag", Value: "b"},
+		{Name: "meta", Value: `{"v":1}`, ContentType: ContentTypeJson},
+		{Name: "blob", FileName: `a "quoted".bin`, Reader: strings.NewReader("raw")},
+	}, map[string]string{"Authorization": "Bearer token", ContentType: ContentTypeJson})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(resp))
+}
+
+func TestWithMultipartFieldsRetry(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 重试时发送的请求体与首次一致
+		assert.Equal(t, []multipartPart{
+			{name: "token", content: "abc"},
+			{name: "file", fileName: "a.txt", contentType: ContentTypeOctetStream, content: "hello"},
+		}, readMultipartParts(t, r))
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodPut, ts.URL,
+		WithMultipartFields([]MultipartField{
+			{Name: "token", Value: "abc"},
+			{Name: "file", FileName: "a.txt", Reader: strings.NewReader("hello")},
+		}),
+		WithRetry(RetryConfig{MaxRetries: 1, WaitTime: time.Millisecond}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), attempts.Load())
+}
@@ -0,0 +1,95 @@
+package resty
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Options GetOpt、PostOpt 的请求配置，零值字段使用默认行为
+//
+// 新增的请求配置项只添加到 Options 中，不再新增 *WithXxx 形式的函数变体。
+type Options struct {
+	// Headers 自定义的 HTTP 请求头
+	Headers map[string]string
+	// Timeout 请求超时时间，为 0 时使用 DefaultTimeout
+	Timeout time.Duration
+	// Query 查询参数
+	Query map[string]string
+	// TLSInsecure 是否跳过 TLS 证书验证
+	TLSInsecure bool
+	// Entity 用于存储 JSON 响应数据的目标对象指针，为 nil 时不解析
+	Entity interface{}
+}
+
+// requestOptions 将 Options 转换为 Do 的请求选项
+func (o *Options) requestOptions() []RequestOption {
+	if o == nil {
+		return nil
+	}
+
+	opts := []RequestOption{WithHeaders(o.Headers), WithQuery(o.Query)}
+	if o.Timeout > 0 {
+		opts = append(opts, WithTimeout(o.Timeout))
+	}
+	if o.TLSInsecure {
+		opts = append(opts, WithTLSInsecure())
+	}
+	if o.Entity != nil {
+		opts = append(opts, WithEntity(o.Entity))
+	}
+	return opts
+}
+
+// GetOpt 发送 GET 请求，所有配置通过 Options 传入
+//
+// 参数:
+//   - url: 目标请求地址
+//   - o: 请求配置，为 nil 时全部使用默认值
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - err: 请求错误或实体解析错误，如果请求成功则为 nil
+//
+// 示例:
+//
+//	var user User
+//	resp, err := GetOpt("https://api.example.com/user", &Options{
+//	    Headers: map[string]string{"Authorization": "Bearer token123"},
+//	    Timeout: 30 * time.Second,
+//	    Query:   map[string]string{"id": "1"},
+//	    Entity:  &user,
+//	})
+func GetOpt(url string, o *Options) (resp []byte, err error) {
+	res, err := Do(context.Background(), http.MethodGet, url, o.requestOptions()...)
+	if res != nil {
+		resp = res.Body
+	}
+	return
+}
+
+// PostOpt 发送 POST 请求，所有配置通过 Options 传入
+//
+// 参数:
+//   - url: 目标请求地址
+//   - body: 请求体内容，可以是任意类型
+//   - o: 请求配置，为 nil 时全部使用默认值
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - err: 请求错误或实体解析错误，如果请求成功则为 nil
+//
+// 示例:
+//
+//	body := map[string]interface{}{"name": "test"}
+//	resp, err := PostOpt("https://api.example.com", body, &Options{
+//	    Headers: map[string]string{"Content-Type": "application/json"},
+//	    Timeout: 30 * time.Second,
+//	})
+func PostOpt(url string, body interface{}, o *Options) (resp []byte, err error) {
+	res, err := Do(context.Background(), http.MethodPost, url, append(o.requestOptions(), WithBody(body))...)
+	if res != nil {
+		resp = res.Body
+	}
+	return
+}
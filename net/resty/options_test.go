@@ -0,0 +1,126 @@
+package resty_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetOptNil(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Empty(t, r.URL.RawQuery)
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := GetOpt(ts.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+}
+
+func TestGetOptFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"ok","data":"`+r.URL.Query().Get("id")+`"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	t.Run("headers", func(t *testing.T) {
+		resp, err := GetOpt(ts.URL, &Options{Headers: map[string]string{"Authorization": "test-token"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`{"status":"ok","data":""}`), resp)
+	})
+
+	t.Run("query", func(t *testing.T) {
+		resp, err := GetOpt(ts.URL, &Options{Query: map[string]string{"id": "42"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`{"status":"ok","data":"42"}`), resp)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := GetOpt(ts.URL+"/slow", &Options{Timeout: 50 * time.Millisecond})
+		assert.Error(t, err)
+	})
+
+	t.Run("entity", func(t *testing.T) {
+		var entity TestResponse
+		_, err := GetOpt(ts.URL, &Options{Entity: &entity})
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", entity.Status)
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		var entity TestResponse
+		resp, err := GetOpt(ts.URL+"/slow", &Options{
+			Headers: map[string]string{"Authorization": "test-token"},
+			Timeout: 5 * time.Second,
+			Query:   map[string]string{"id": "7"},
+			Entity:  &entity,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`{"status":"ok","data":"7"}`), resp)
+		assert.Equal(t, "7", entity.Data)
+	})
+}
+
+func TestGetOptTLSInsecure(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	_, err := GetOpt(ts.URL, &Options{})
+	assert.Error(t, err, "self-signed certificate should be rejected by default")
+
+	_, err = GetOpt(ts.URL, &Options{TLSInsecure: true})
+	assert.NoError(t, err)
+}
+
+func TestPostOpt(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "1", r.URL.Query().Get("page"))
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, `{"name":"test"}`, string(body))
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.WriteString(w, `{"status":"ok","data":"created"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	var entity TestResponse
+	resp, err := PostOpt(ts.URL, map[string]string{"name": "test"}, &Options{
+		Headers: map[string]string{"Authorization": "test-token", "Content-Type": "application/json"},
+		Query:   map[string]string{"page": "1"},
+		Timeout: 30 * time.Second,
+		Entity:  &entity,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok","data":"created"}`), resp)
+	assert.Equal(t, "created", entity.Data)
+}
@@ -0,0 +1,149 @@
+package resty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// 页码分页的默认查询参数名
+const (
+	// DefaultPageParam 默认的页码参数名
+	DefaultPageParam = "page"
+	// DefaultSizeParam 默认的每页数量参数名
+	DefaultSizeParam = "page_size"
+)
+
+// PageConfig 页码分页配置
+type PageConfig struct {
+	// PageParam 页码的查询参数名，为空时使用 DefaultPageParam
+	PageParam string
+	// SizeParam 每页数量的查询参数名，为空时使用 DefaultSizeParam
+	SizeParam string
+	// PageSize 每页数量，为 0 时不发送每页数量参数，此时无法根据总数判断结束
+	PageSize int
+	// TotalPath 响应中总条数的字段路径（语法见 ExtractJSONField），为空时不根据总数判断结束
+	TotalPath string
+	// ItemsPath 响应中当前页数据的字段路径，为空时以整个响应体作为当前页数据
+	ItemsPath string
+	// StartPage 第一页的页码，页码从 1 开始的接口需要设置为 1
+	StartPage int
+}
+
+// PaginateQuery 按页码依次请求分页接口，并将每一页的响应体交给 fn 处理
+//
+// 每次请求将 PageParam 加一，出现以下情况之一时结束：
+//   - fn 返回 stop 为 true 或返回错误
+//   - 当前页为空：响应体为空，或 ItemsPath 指向的（未设置时为整个响应体）值为空数组、空对象或 null
+//   - 设置了 TotalPath 和 PageSize，且已请求的页数乘以 PageSize 达到总条数
+//
+// url 中已有的查询参数会被保留，分页参数会覆盖同名参数。
+//
+// 参数:
+//   - ctx: 请求上下文，取消后停止翻页
+//   - url: 分页接口地址
+//   - cfg: 分页配置
+//   - header: 自定义的 HTTP 请求头
+//   - fn: 处理每一页响应体的回调函数
+//
+// 返回值:
+//   - error: 请求错误、非 2xx 状态码、总条数解析错误或 fn 返回的错误，正常结束时为 nil
+//
+// 示例:
+//
+//	err := PaginateQuery(ctx, "https://api.example.com/users", PageConfig{
+//	    PageSize:  100,
+//	    TotalPath: "total",
+//	    ItemsPath: "items",
+//	    StartPage: 1,
+//	}, nil, func(page []byte) (bool, error) {
+//	    return false, process(page)
+//	})
+func PaginateQuery(ctx context.Context, url string, cfg PageConfig, header map[string]string, fn func(page []byte) (stop bool, err error)) error {
+	if cfg.PageParam == "" {
+		cfg.PageParam = DefaultPageParam
+	}
+	if cfg.SizeParam == "" {
+		cfg.SizeParam = DefaultSizeParam
+	}
+
+	for fetched := 0; ; fetched++ {
+		pageURL, err := pageQueryURL(url, cfg, cfg.StartPage+fetched)
+		if err != nil {
+			return err
+		}
+		res, err := Do(ctx, http.MethodGet, pageURL, WithHeaders(header))
+		if err != nil {
+			return err
+		}
+		if !res.IsSuccess() {
+			return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+		}
+
+		empty, err := emptyPage(res.Body, cfg.ItemsPath)
+		if err != nil || empty {
+			return err
+		}
+		if stop, err := fn(res.Body); stop || err != nil {
+			return err
+		}
+
+		if cfg.TotalPath != "" && cfg.PageSize > 0 {
+			raw, err := ExtractJSONField(res.Body, cfg.TotalPath)
+			if err != nil {
+				return err
+			}
+			total, err := AsInt64(raw)
+			if err != nil {
+				return err
+			}
+			if int64(fetched+1)*int64(cfg.PageSize) >= total {
+				return nil
+			}
+		}
+	}
+}
+
+// pageQueryURL 在 rawURL 上设置页码和每页数量参数
+func pageQueryURL(rawURL string, cfg PageConfig, page int) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set(cfg.PageParam, strconv.Itoa(page))
+	if cfg.PageSize > 0 {
+		query.Set(cfg.SizeParam, strconv.Itoa(cfg.PageSize))
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// emptyPage 判断当前页是否没有数据
+func emptyPage(body []byte, itemsPath string) (bool, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return true, nil
+	}
+	items, err := ExtractJSONField(body, itemsPath)
+	if err != nil {
+		return false, err
+	}
+
+	switch jsonKind(items) {
+	case "null":
+		return true, nil
+	case "array":
+		var values []json.RawMessage
+		err = json.Unmarshal(items, &values)
+		return len(values) == 0, err
+	case "object":
+		var values map[string]json.RawMessage
+		err = json.Unmarshal(items, &values)
+		return len(values) == 0, err
+	}
+	return false, nil
+}
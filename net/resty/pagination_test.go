@@ -0,0 +1,115 @@
+package resty_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// pagedServer 以 1 为起始页码返回三页数据，之后返回空页
+func pagedServer(t *testing.T, requested *[]string) *httptest.Server {
+	pages := [][]int{{1, 2}, {3, 4}, {5}}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "active", r.URL.Query().Get("status"))
+		assert.Equal(t, "2", r.URL.Query().Get("size"))
+		*requested = append(*requested, r.URL.Query().Get("p"))
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		items := "[]"
+		if page >= 1 && page <= len(pages) {
+			items = ""
+			for i, id := range pages[page-1] {
+				if i > 0 {
+					items += ","
+				}
+				items += fmt.Sprintf(`{"id":%d}`, id)
+			}
+			items = "[" + items + "]"
+		}
+		_, _ = fmt.Fprintf(w, `{"total":5,"items":%s}`, items)
+	}))
+}
+
+func TestPaginateQuery(t *testing.T) {
+	headers := map[string]string{"Authorization": "test-token"}
+	cfg := PageConfig{PageParam: "p", SizeParam: "size", PageSize: 2, ItemsPath: "items", StartPage: 1}
+
+	t.Run("total", func(t *testing.T) {
+		var requested []string
+		ts := pagedServer(t, &requested)
+		defer ts.Close()
+
+		cfg := cfg
+		cfg.TotalPath = "total"
+		var ids []string
+		err := PaginateQuery(context.Background(), ts.URL+"?status=active", cfg, headers, func(page []byte) (bool, error) {
+			raw, err := ExtractJSONField(page, "items")
+			ids = append(ids, string(raw))
+			return false, err
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`[{"id":1},{"id":2}]`, `[{"id":3},{"id":4}]`, `[{"id":5}]`}, ids)
+		// 达到总数后不再请求空页
+		assert.Equal(t, []string{"1", "2", "3"}, requested)
+	})
+
+	t.Run("empty page", func(t *testing.T) {
+		var requested []string
+		ts := pagedServer(t, &requested)
+		defer ts.Close()
+
+		pages := 0
+		err := PaginateQuery(context.Background(), ts.URL+"?status=active", cfg, headers, func(page []byte) (bool, error) {
+			pages++
+			return false, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, pages)
+		assert.Equal(t, []string{"1", "2", "3", "4"}, requested)
+	})
+
+	t.Run("zero based", func(t *testing.T) {
+		var requested []string
+		ts := pagedServer(t, &requested)
+		defer ts.Close()
+
+		cfg := cfg
+		cfg.StartPage = 0
+		cfg.TotalPath = "total"
+		pages := 0
+		err := PaginateQuery(context.Background(), ts.URL+"?status=active", cfg, headers, func(page []byte) (bool, error) {
+			pages++
+			return false, nil
+		})
+		assert.NoError(t, err)
+		// 页码 0 在该服务端上是空页
+		assert.Equal(t, 0, pages)
+		assert.Equal(t, []string{"0"}, requested)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		var requested []string
+		ts := pagedServer(t, &requested)
+		defer ts.Close()
+
+		err := PaginateQuery(context.Background(), ts.URL+"?status=active", cfg, headers, func(page []byte) (bool, error) {
+			return true, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1"}, requested)
+
+		boom := errors.New("boom")
+		err = PaginateQuery(context.Background(), ts.URL+"?status=active", cfg, headers, func(page []byte) (bool, error) {
+			return false, boom
+		})
+		assert.ErrorIs(t, err, boom)
+	})
+}
@@ -0,0 +1,111 @@
+package resty
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrInvalidPatchOp JSON Patch 操作不合法，如未知的操作类型或缺少 move、copy 的源位置
+var ErrInvalidPatchOp = errors.New("invalid json patch operation")
+
+// PatchOp JSON Patch（RFC 6902）中的单个操作，与 Operation 是同一类型
+type PatchOp = Operation
+
+// validPatchOps RFC 6902 定义的操作类型
+var validPatchOps = map[string]bool{
+	"add":     true,
+	"remove":  true,
+	"replace": true,
+	"move":    true,
+	"copy":    true,
+	"test":    true,
+}
+
+// validatePatchOps 在本地校验 JSON Patch 操作，避免服务端忽略无法识别的操作
+func validatePatchOps(ops []PatchOp) error {
+	for i, op := range ops {
+		if !validPatchOps[op.Op] {
+			return fmt.Errorf("%w: unknown op %q at index %d", ErrInvalidPatchOp, op.Op, i)
+		}
+		if (op.Op == "move" || op.Op == "copy") && op.From == "" {
+			return fmt.Errorf("%w: %s at index %d requires from", ErrInvalidPatchOp, op.Op, i)
+		}
+	}
+	return nil
+}
+
+// PatchJSONPatch 发送 JSON Patch（RFC 6902）格式的 PATCH 请求，并在发送前校验操作
+//
+// Content-Type 固定为 application/json-patch+json，header 中的 Content-Type 会被覆盖，
+// 操作列表按顺序序列化为 JSON 数组。与 JsonPatch 不同，操作类型不是
+// add、remove、replace、move、copy、test 之一，或 move、copy 缺少 From 时不会发送请求。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - ops: JSON Patch 操作列表
+//   - header: 自定义的 HTTP 请求头
+//   - timeout: 请求超时时间（秒）
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - err: ErrInvalidPatchOp 或请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	resp, err := PatchJSONPatch("https://k8s.example.com/apis/apps/v1/namespaces/default/deployments/web", []PatchOp{
+//	    {Op: "replace", Path: "/spec/replicas", Value: 3},
+//	}, headers, 30)
+func PatchJSONPatch(url string, ops []PatchOp, header map[string]string, timeout int64) (resp []byte, err error) {
+	if err = validatePatchOps(ops); err != nil {
+		return nil, err
+	}
+	if ops == nil {
+		ops = []PatchOp{}
+	}
+	data, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	return doBody(http.MethodPatch, url, WithHeaders(header), WithHeaders(map[string]string{ContentType: ContentTypeJSONPatch}),
+		WithBody(data), WithTimeout(seconds(timeout)))
+}
+
+// PatchMergePatch 发送 JSON Merge Patch（RFC 7396）格式的 PATCH 请求
+//
+// Content-Type 固定为 application/merge-patch+json，header 中的 Content-Type 会被覆盖。
+// patch 为 []byte、string 或 json.RawMessage 时视为已经序列化的文档原样发送，
+// 其他类型序列化为 JSON；合并补丁中值为 null 的字段表示删除该字段。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - patch: 合并补丁文档
+//   - header: 自定义的 HTTP 请求头
+//   - timeout: 请求超时时间（秒）
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - err: 序列化错误或请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	patch := map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"tier": nil}}}
+//	resp, err := PatchMergePatch("https://k8s.example.com/api/v1/namespaces/default/pods/web", patch, headers, 30)
+func PatchMergePatch(url string, patch interface{}, header map[string]string, timeout int64) (resp []byte, err error) {
+	var data []byte
+	switch p := patch.(type) {
+	case []byte:
+		data = p
+	case json.RawMessage:
+		data = p
+	case string:
+		data = []byte(p)
+	default:
+		if data, err = json.Marshal(p); err != nil {
+			return nil, err
+		}
+	}
+	return doBody(http.MethodPatch, url, WithHeaders(header), WithHeaders(map[string]string{ContentType: ContentTypeMergePatch}),
+		WithBody(data), WithTimeout(seconds(timeout)))
+}
@@ -0,0 +1,84 @@
+package resty_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// patchServer 返回收到的 Content-Type 和请求体
+func patchServer(t *testing.T) (*httptest.Server, *string, *string) {
+	var contentType, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		data, _ := io.ReadAll(r.Body)
+		contentType, body = r.Header.Get(ContentType), string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	return ts, &contentType, &body
+}
+
+func TestPatchJSONPatch(t *testing.T) {
+	ts, contentType, body := patchServer(t)
+	defer ts.Close()
+
+	_, err := PatchJSONPatch(ts.URL, []PatchOp{
+		{Op: "replace", Path: "/spec/replicas", Value: 3},
+		{Op: "remove", Path: "/metadata/labels/tier"},
+		{Op: "add", Path: "/metadata/labels/app", Value: nil},
+		{Op: "move", From: "/a", Path: "/b"},
+	}, map[string]string{ContentType: ContentTypeJson}, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentTypeJSONPatch, *contentType)
+	assert.Equal(t, `[{"op":"replace","path":"/spec/replicas","value":3},{"op":"remove","path":"/metadata/labels/tier"},{"op":"add","path":"/metadata/labels/app","value":null},{"op":"move","path":"/b","from":"/a"}]`, *body)
+
+	// 空操作列表序列化为空数组
+	_, err = PatchJSONPatch(ts.URL, nil, nil, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, *body)
+}
+
+func TestPatchJSONPatchInvalidOp(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	_, err := PatchJSONPatch(ts.URL, []PatchOp{{Op: "replace", Path: "/a", Value: 1}, {Op: "update", Path: "/b"}}, nil, 30)
+	assert.ErrorIs(t, err, ErrInvalidPatchOp)
+	assert.EqualError(t, err, `invalid json patch operation: unknown op "update" at index 1`)
+
+	_, err = PatchJSONPatch(ts.URL, []PatchOp{{Op: "copy", Path: "/b"}}, nil, 30)
+	assert.ErrorIs(t, err, ErrInvalidPatchOp)
+	assert.Zero(t, requests)
+}
+
+func TestPatchMergePatch(t *testing.T) {
+	ts, contentType, body := patchServer(t)
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		patch    interface{}
+		expected string
+	}{
+		{"map", map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"tier": nil}}}, `{"metadata":{"labels":{"tier":null}}}`},
+		{"raw bytes", []byte(`{"spec": {"replicas": 3}}`), `{"spec": {"replicas": 3}}`},
+		{"raw message", json.RawMessage(`{"a":null}`), `{"a":null}`},
+		{"string", `{"b":1}`, `{"b":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PatchMergePatch(ts.URL, tt.patch, map[string]string{ContentType: ContentTypeJson}, 30)
+			assert.NoError(t, err)
+			assert.Equal(t, ContentTypeMergePatch, *contentType)
+			assert.Equal(t, tt.expected, *body)
+		})
+	}
+}
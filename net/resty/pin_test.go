@@ -0,0 +1,96 @@
+package resty_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetPinned(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	cert := ts.Certificate()
+	pin := SPKIFingerprint(cert)
+	headers := map[string]string{"Authorization": "test-token"}
+
+	t.Run("hex pin", func(t *testing.T) {
+		resp, err := GetPinned(ts.URL, pin, headers)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	})
+
+	t.Run("base64 pin", func(t *testing.T) {
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		resp, err := GetPinned(ts.URL, "sha256//"+base64.StdEncoding.EncodeToString(sum[:]), headers)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		before := requests.Load()
+		_, err := GetPinned(ts.URL, strings.Repeat("ab", sha256.Size), headers)
+		assert.ErrorIs(t, err, ErrPinMismatch)
+		assert.Contains(t, err.Error(), pin)
+		// 握手失败，请求没有发出
+		assert.Equal(t, before, requests.Load())
+	})
+
+	t.Run("backup pin", func(t *testing.T) {
+		res, err := Do(context.Background(), http.MethodGet, ts.URL, WithHeaders(headers),
+			WithPinnedSPKI(strings.Repeat("ab", sha256.Size), pin))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("invalid pin", func(t *testing.T) {
+		_, err := GetPinned(ts.URL, "not-a-pin", headers)
+		assert.ErrorContains(t, err, "invalid spki sha256 pin")
+	})
+
+	t.Run("plain http", func(t *testing.T) {
+		_, err := GetPinned(strings.Replace(ts.URL, "https://", "http://", 1), pin, headers)
+		assert.ErrorContains(t, err, "requires an https url")
+	})
+}
+
+func TestGetPinnedRedirect(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect to plain http should not be followed")
+	}))
+	defer plain.Close()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/downgrade" {
+			http.Redirect(w, r, plain.URL, http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/final", http.StatusFound)
+			return
+		}
+		_, _ = io.WriteString(w, "final")
+	}))
+	defer ts.Close()
+
+	pin := SPKIFingerprint(ts.Certificate())
+	resp, err := GetPinned(ts.URL+"/moved", pin, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("final"), resp)
+
+	_, err = GetPinned(ts.URL+"/downgrade", pin, nil)
+	assert.ErrorContains(t, err, "refusing redirect to non-https url")
+}
@@ -0,0 +1,103 @@
+package resty
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// ContentTypeProblemJSON RFC 7807 问题详情的 Content-Type 值
+const ContentTypeProblemJSON = "application/problem+json"
+
+// ProblemDetails RFC 7807 定义的问题详情，服务端以 application/problem+json 返回错误时使用
+type ProblemDetails struct {
+	// Type 问题类型的 URI，缺省时为 "about:blank"
+	Type string `json:"type,omitempty"`
+	// Title 问题类型的简短描述
+	Title string `json:"title,omitempty"`
+	// Status HTTP 状态码，响应中缺省时取实际的状态码
+	Status int `json:"status,omitempty"`
+	// Detail 本次问题的具体说明
+	Detail string `json:"detail,omitempty"`
+	// Instance 标识本次问题的 URI
+	Instance string `json:"instance,omitempty"`
+}
+
+// Error 实现 error 接口
+func (p *ProblemDetails) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("problem %d %s: %s", p.Status, p.Title, p.Detail)
+	}
+	return fmt.Sprintf("problem %d %s", p.Status, p.Title)
+}
+
+// PostExpectingProblem 发送 POST 请求，成功时将 JSON 响应解析为 S，失败时解析 RFC 7807 问题详情
+//
+// 2xx 响应的 JSON 解析到 *S 中返回，响应体为空时返回 S 的零值。
+// 非 2xx 且 Content-Type 为 application/problem+json 的响应解析为 *ProblemDetails，
+// 同时作为 error 返回，调用方可以只判断 err，也可以通过 errors.As 取出问题详情；
+// 其他非 2xx 响应返回 "unexpected status code" 错误。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - body: 请求体内容，可以是任意类型
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - *S: 成功时解析的响应
+//   - *ProblemDetails: 服务端返回的问题详情，没有时为 nil
+//   - error: 请求错误、JSON 解析错误、*ProblemDetails 或非 2xx 状态码错误，如果成功则为 nil
+//
+// 示例:
+//
+//	user, problem, err := PostExpectingProblem[User]("https://api.example.com/users", body, nil)
+//	if problem != nil {
+//	    log.Printf("%s: %s", problem.Title, problem.Detail)
+//	}
+func PostExpectingProblem[S any](url string, body interface{}, header map[string]string) (*S, *ProblemDetails, error) {
+	res, err := Do(context.Background(), http.MethodPost, url, WithHeaders(header), WithBody(body))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !res.IsSuccess() {
+		problem, err := parseProblem(res)
+		if err != nil {
+			return nil, nil, err
+		}
+		if problem == nil {
+			return nil, nil, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+		}
+		return nil, problem, problem
+	}
+
+	result := new(S)
+	if len(res.Body) > 0 {
+		if err = json.Unmarshal(res.Body, result); err != nil {
+			return nil, nil, err
+		}
+	}
+	return result, nil, nil
+}
+
+// parseProblem 解析响应中的问题详情，响应不是 application/problem+json 时返回 nil
+func parseProblem(res *Response) (*ProblemDetails, error) {
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get(ContentType))
+	if err != nil || mediaType != ContentTypeProblemJSON {
+		return nil, nil
+	}
+
+	problem := &ProblemDetails{}
+	if err = json.Unmarshal(res.Body, problem); err != nil {
+		return nil, err
+	}
+	if problem.Status == 0 {
+		problem.Status = res.StatusCode
+	}
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	return problem, nil
+}
@@ -0,0 +1,65 @@
+package resty_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestPostExpectingProblem(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		switch r.URL.Path {
+		case "/ok":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"status":"ok","data":"created"}`)
+		case "/problem":
+			w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc"}`)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = io.WriteString(w, "bad gateway")
+		}
+	}))
+	defer ts.Close()
+
+	body := map[string]string{"name": "test"}
+
+	t.Run("success", func(t *testing.T) {
+		resp, problem, err := PostExpectingProblem[TestResponse](ts.URL+"/ok", body, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, problem)
+		if assert.NotNil(t, resp) {
+			assert.Equal(t, "created", resp.Data)
+		}
+	})
+
+	t.Run("problem", func(t *testing.T) {
+		resp, problem, err := PostExpectingProblem[TestResponse](ts.URL+"/problem", body, nil)
+		assert.Nil(t, resp)
+		if assert.NotNil(t, problem) {
+			assert.Equal(t, "https://example.com/probs/out-of-credit", problem.Type)
+			assert.Equal(t, "You do not have enough credit.", problem.Title)
+			assert.Equal(t, http.StatusForbidden, problem.Status, "missing status should default to the response status")
+			assert.Equal(t, "Your current balance is 30, but that costs 50.", problem.Detail)
+			assert.Equal(t, "/account/12345/msgs/abc", problem.Instance)
+		}
+		var target *ProblemDetails
+		assert.True(t, errors.As(err, &target))
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		resp, problem, err := PostExpectingProblem[TestResponse](ts.URL+"/other", body, nil)
+		assert.Nil(t, resp)
+		assert.Nil(t, problem)
+		assert.EqualError(t, err, "unexpected status code: 502")
+	})
+}
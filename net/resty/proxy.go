@@ -0,0 +1,103 @@
+package resty
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrProxyAuthRequired 代理服务器返回 407，要求提供或更新代理认证信息
+var ErrProxyAuthRequired = errors.New("proxy authentication required")
+
+// WithProxy 通过指定的代理服务器发送请求，默认使用环境变量 HTTP_PROXY、HTTPS_PROXY 中的代理
+func WithProxy(proxyURL string) RequestOption {
+	return func(c *requestConfig) {
+		c.proxyURL = proxyURL
+	}
+}
+
+// WithProxyAuth 使用 Basic 认证访问代理服务器
+//
+// 认证信息通过 Proxy-Authorization 发送给代理：HTTPS 请求在 CONNECT 请求中携带，
+// 经代理转发的 HTTP 请求在请求头中携带，不经过代理的请求不会携带。
+// 认证信息与代理地址相互独立，不需要写入代理地址的 userinfo，也不会出现在日志中的代理地址里。
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodGet, "https://api.example.com",
+//	    WithProxy("http://proxy.internal:3128"),
+//	    WithProxyAuth("user", "password"),
+//	)
+func WithProxyAuth(user, pass string) RequestOption {
+	return func(c *requestConfig) {
+		c.proxyAuth = "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	}
+}
+
+// WithProxyToken 使用 Bearer 令牌访问代理服务器，发送方式与 WithProxyAuth 相同
+func WithProxyToken(token string) RequestOption {
+	return func(c *requestConfig) {
+		c.proxyAuth = "Bearer " + token
+	}
+}
+
+// applyProxy 为客户端设置代理地址和 CONNECT 请求的代理认证，设置了 SOCKS5 代理时改为经 SOCKS5 拨号
+func applyProxy(client *resty.Client, cfg *requestConfig) error {
+	if cfg.socks5 != nil {
+		return applySOCKS5(client, cfg.socks5, cfg.dialer)
+	}
+	if cfg.proxyURL != "" {
+		client.SetProxy(cfg.proxyURL)
+	}
+	if cfg.proxyAuth == "" {
+		return nil
+	}
+
+	transport, err := client.Transport()
+	if err != nil {
+		return err
+	}
+	transport.ProxyConnectHeader = http.Header{"Proxy-Authorization": {cfg.proxyAuth}}
+	return nil
+}
+
+// setProxyAuthorization 为经代理转发的 HTTP 请求设置 Proxy-Authorization
+func setProxyAuthorization(client *resty.Client, r *http.Request, auth string) error {
+	// HTTPS 请求的认证信息在 CONNECT 请求中发送
+	if r.URL.Scheme != "http" {
+		return nil
+	}
+	transport, err := client.Transport()
+	if err != nil || transport.Proxy == nil {
+		return err
+	}
+	proxyURL, err := transport.Proxy(r)
+	if err != nil {
+		return err
+	}
+	if proxyURL != nil {
+		r.Header.Set("Proxy-Authorization", auth)
+	}
+	return nil
+}
+
+// proxyAuthError 将代理返回的 407 转换为 ErrProxyAuthRequired
+//
+// HTTPS 请求的 CONNECT 失败时，net/http 只返回状态描述作为错误信息；
+// HTTP 请求则收到状态码为 407 的响应。
+func proxyAuthError(res *resty.Response, err error) error {
+	if err != nil {
+		if strings.Contains(err.Error(), http.StatusText(http.StatusProxyAuthRequired)) {
+			return fmt.Errorf("%w: %w", ErrProxyAuthRequired, err)
+		}
+		return err
+	}
+	if res != nil && res.StatusCode() == http.StatusProxyAuthRequired {
+		return fmt.Errorf("%w: status %d", ErrProxyAuthRequired, res.StatusCode())
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package resty_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// authProxy 启动一个要求 Proxy-Authorization 的本地代理，支持 CONNECT 隧道和 HTTP 转发
+func authProxy(t *testing.T, auth string, connects *int64) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != auth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+
+		if r.Method != http.MethodConnect {
+			// 转发普通 HTTP 请求，认证信息不应转发给目标服务
+			r.RequestURI = ""
+			r.Header.Del("Proxy-Authorization")
+			res, err := http.DefaultTransport.RoundTrip(r)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer res.Body.Close()
+			w.WriteHeader(res.StatusCode)
+			_, _ = io.Copy(w, res.Body)
+			return
+		}
+
+		atomic.AddInt64(connects, 1)
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		go func() {
+			defer upstream.Close()
+			defer conn.Close()
+			go func() { _, _ = io.Copy(upstream, conn) }()
+			_, _ = io.Copy(conn, upstream)
+		}()
+	}))
+}
+
+func TestWithProxyAuth(t *testing.T) {
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Proxy-Authorization"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer target.Close()
+
+	var connects int64
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	proxy := authProxy(t, auth, &connects)
+	defer proxy.Close()
+
+	// HTTPS 请求在 CONNECT 中携带认证信息
+	resp, err := Do(context.Background(), http.MethodGet, target.URL,
+		WithTLSInsecure(),
+		WithProxy(proxy.URL),
+		WithProxyAuth("user", "secret"),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte(`{"status":"ok"}`), resp.Body)
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&connects))
+
+	// 缺少认证信息时返回 ErrProxyAuthRequired
+	_, err = Do(context.Background(), http.MethodGet, target.URL,
+		WithTLSInsecure(),
+		WithProxy(proxy.URL),
+	)
+	assert.ErrorIs(t, err, ErrProxyAuthRequired)
+
+	_, err = Do(context.Background(), http.MethodGet, target.URL,
+		WithTLSInsecure(),
+		WithProxy(proxy.URL),
+		WithProxyAuth("user", "wrong"),
+	)
+	assert.ErrorIs(t, err, ErrProxyAuthRequired)
+}
+
+func TestWithProxyAuthPlainHTTP(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer target.Close()
+
+	var connects int64
+	proxy := authProxy(t, "Bearer proxy-token", &connects)
+	defer proxy.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, target.URL,
+		WithProxy(proxy.URL),
+		WithProxyToken("proxy-token"),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte(`{"status":"ok"}`), resp.Body)
+	}
+
+	_, err = Do(context.Background(), http.MethodGet, target.URL, WithProxy(proxy.URL))
+	assert.ErrorIs(t, err, ErrProxyAuthRequired)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&connects))
+}
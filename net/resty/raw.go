@@ -0,0 +1,100 @@
+package resty
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// DoRaw 使用与 Do 相同的配置发送请求，返回未读取响应体的 *http.Response
+//
+// 适用于包内没有封装的场景，如将响应体直接交给其他库流式解析。
+// TLS、代理、认证、签名、默认查询参数等配置与 Do 完全一致，区别在于：
+//   - 不重试，WithRetry 会被忽略，因为响应体交给调用方之后无法判断是否需要重试
+//   - WithBodyLogging 只记录请求体和响应的状态码、header，不记录响应体
+//   - WithEntity、WithShadow 和严格的 Content-Length 校验都需要读取响应体，不会生效
+//   - 非 2xx 状态码不会作为错误返回
+//
+// 注意:
+//   - 调用方拥有返回的响应体，必须在使用完毕后调用 Close；
+//     Close 会丢弃少量未读取的数据，使连接可以放回连接池复用
+//   - 超时时间 WithTimeout 包含读取响应体的时间，长时间的流式读取需要设置足够大的超时，
+//     或使用 WithTimeout(0) 配合 WithIdleReadTimeout 只限制读取停顿的时间
+//
+// 参数:
+//   - ctx: 请求上下文，取消后请求和响应体读取都会终止
+//   - method: HTTP 方法
+//   - url: 目标请求地址
+//   - opts: 请求选项，按传入顺序生效
+//
+// 返回值:
+//   - *http.Response: 未读取响应体的原始响应，调用方负责关闭 Body
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	res, err := DoRaw(ctx, http.MethodGet, "https://api.example.com/export",
+//	    WithHeaders(map[string]string{"Authorization": "Bearer token123"}),
+//	)
+//	if err != nil {
+//	    return err
+//	}
+//	defer res.Body.Close()
+//	decoder := json.NewDecoder(res.Body)
+func DoRaw(ctx context.Context, method, url string, opts ...RequestOption) (*http.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, done, err := track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	cfg := newRequestConfig(opts...)
+	cfg.raw = true
+	cfg.retry = nil
+	// 看门狗需要一直工作到调用方关闭响应体，因此不能交给 executeOnce 管理
+	idleReadTimeout := cfg.idleReadTimeout
+	cfg.idleReadTimeout = 0
+
+	res, err := executeOnce(ctx, method, url, cfg)
+	if err != nil {
+		cancel()
+		done()
+		return nil, err
+	}
+
+	raw := res.RawResponse
+	var body io.ReadCloser = raw.Body
+	if idleReadTimeout > 0 {
+		body = watchIdle(body, idleReadTimeout, cancel)
+	}
+	raw.Body = &rawBody{body: body, release: func() {
+		cancel()
+		done()
+	}}
+	return raw, nil
+}
+
+// rawBody DoRaw 返回的响应体，关闭时释放请求占用的上下文和进行中请求的登记
+type rawBody struct {
+	body    io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+// Read 实现 io.Reader 接口
+func (b *rawBody) Read(p []byte) (int, error) {
+	return b.body.Read(p)
+}
+
+// Close 丢弃少量剩余数据后关闭响应体，重复调用是安全的
+func (b *rawBody) Close() error {
+	b.once.Do(func() {
+		drainBody(b.body)
+		b.release()
+	})
+	return nil
+}
@@ -0,0 +1,81 @@
+package resty_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestDoRaw(t *testing.T) {
+	states := make(chan http.ConnState, 16)
+	var attempts atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "1", r.URL.Query().Get("page"))
+		w.Header().Set(ContentType, "text/plain")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(strings.Repeat("x", 10000)))
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		states <- state
+	}
+	ts.Start()
+	defer ts.Close()
+
+	res, err := DoRaw(context.Background(), http.MethodGet, ts.URL,
+		WithHeaders(map[string]string{"Authorization": "Bearer token"}),
+		WithQuery(map[string]string{"page": "1"}),
+		WithRetry(RetryConfig{MaxRetries: 3, WaitTime: time.Millisecond}),
+	)
+	assert.NoError(t, err)
+	// 非 2xx 不作为错误返回，也不会重试
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(1), attempts.Load())
+
+	// 调用方手动读取部分响应体后关闭
+	buf := make([]byte, 100)
+	_, err = io.ReadFull(res.Body, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", 100), string(buf))
+	assert.NoError(t, res.Body.Close())
+	assert.NoError(t, res.Body.Close())
+
+	// 关闭后连接回到空闲状态可以复用，而不是被关闭
+	assert.Equal(t, http.StateNew, <-states)
+	assert.Equal(t, http.StateActive, <-states)
+	select {
+	case state := <-states:
+		assert.Equal(t, http.StateIdle, state)
+	case <-time.After(time.Second):
+		t.Fatal("connection was not released")
+	}
+}
+
+func TestDoRawIdleReadTimeout(t *testing.T) {
+	ts := pausingServer("first", 300*time.Millisecond, "second")
+	defer ts.Close()
+
+	res, err := DoRaw(context.Background(), http.MethodGet, ts.URL, WithIdleReadTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	_, err = io.ReadAll(res.Body)
+	var stalled *StalledError
+	assert.ErrorAs(t, err, &stalled)
+}
+
+func TestDoRawError(t *testing.T) {
+	res, err := DoRaw(context.Background(), http.MethodGet, "http://127.0.0.1:0")
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
@@ -0,0 +1,94 @@
+package resty
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RedirectHop 重定向链中的一跳
+type RedirectHop struct {
+	// URL 返回重定向的请求地址
+	URL string
+	// StatusCode 重定向状态码，如 301、302
+	StatusCode int
+	// Location 重定向响应的 Location 头，可能是相对地址
+	Location string
+}
+
+// redirectConfig 单个请求的重定向限制
+type redirectConfig struct {
+	// max 最多跟随的重定向次数，小于 0 时不限制（仍受 net/http 默认的 10 次限制）
+	max int
+	// sameHost 只跟随指向同一主机的重定向
+	sameHost bool
+	// httpsOnly 重定向到非 HTTPS 地址时返回错误，由 WithPinnedSPKI 设置
+	httpsOnly bool
+}
+
+// WithMaxRedirects 最多跟随 n 次重定向，n 为 0 时不跟随重定向
+//
+// 达到上限时不返回错误，而是返回最后一个 3xx 响应，可以通过 Response.RedirectHistory 查看已跟随的重定向。
+func WithMaxRedirects(n int) RequestOption {
+	return func(c *requestConfig) {
+		if c.redirect == nil {
+			c.redirect = &redirectConfig{max: -1}
+		}
+		c.redirect.max = max(n, 0)
+	}
+}
+
+// WithSameHostRedirects 只跟随指向同一主机（含端口）的重定向
+//
+// 遇到跨主机的重定向时不返回错误，而是返回该 3xx 响应，避免 Authorization 等凭据发往其他主机。
+func WithSameHostRedirects() RequestOption {
+	return func(c *requestConfig) {
+		if c.redirect == nil {
+			c.redirect = &redirectConfig{max: -1}
+		}
+		c.redirect.sameHost = true
+	}
+}
+
+// apply 为客户端设置重定向策略
+func (r *redirectConfig) apply(client *resty.Client) {
+	client.SetRedirectPolicy(resty.RedirectPolicyFunc(func(req *http.Request, via []*http.Request) error {
+		if r.max >= 0 && len(via) > r.max {
+			return http.ErrUseLastResponse
+		}
+		if r.sameHost && req.URL.Host != via[0].URL.Host {
+			return http.ErrUseLastResponse
+		}
+		if r.httpsOnly && req.URL.Scheme != "https" {
+			return fmt.Errorf("refusing redirect to non-https url %s", req.URL.Redacted())
+		}
+		// 与 net/http 的默认策略保持一致
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}))
+}
+
+// redirectHistory 沿 http.Request.Response 链还原已跟随的重定向，并返回最终请求的地址
+//
+// 重定向的每一跳都记录在下一次请求的 Response 字段中，因此无论重定向策略如何，
+// 只要拿到了最终响应就能得到完整的重定向链。
+func redirectHistory(res *http.Response) ([]RedirectHop, string) {
+	if res == nil || res.Request == nil {
+		return nil, ""
+	}
+	var hops []RedirectHop
+	for prev := res.Request.Response; prev != nil && prev.Request != nil; prev = prev.Request.Response {
+		hops = append(hops, RedirectHop{
+			URL:        prev.Request.URL.String(),
+			StatusCode: prev.StatusCode,
+			Location:   prev.Header.Get("Location"),
+		})
+	}
+	slices.Reverse(hops)
+	return hops, res.Request.URL.String()
+}
@@ -0,0 +1,101 @@
+package resty_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// redirectChainServer /a -> /b -> /c -> /final 依次重定向
+func redirectChainServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b?step=1", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/c", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("done"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRedirectHistory(t *testing.T) {
+	ts := redirectChainServer()
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL+"/a")
+	assert.NoError(t, err)
+	assert.Equal(t, "done", string(resp.Body))
+	assert.Equal(t, []RedirectHop{
+		{URL: ts.URL + "/a", StatusCode: http.StatusFound, Location: "/b?step=1"},
+		{URL: ts.URL + "/b?step=1", StatusCode: http.StatusMovedPermanently, Location: "/c"},
+		{URL: ts.URL + "/c", StatusCode: http.StatusTemporaryRedirect, Location: "/final"},
+	}, resp.RedirectHistory)
+	assert.Equal(t, ts.URL+"/final", resp.FinalURL)
+
+	// 没有重定向
+	resp, err = Do(context.Background(), http.MethodGet, ts.URL+"/final")
+	assert.NoError(t, err)
+	assert.Empty(t, resp.RedirectHistory)
+	assert.Equal(t, ts.URL+"/final", resp.FinalURL)
+}
+
+func TestWithMaxRedirects(t *testing.T) {
+	ts := redirectChainServer()
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL+"/a", WithMaxRedirects(2))
+	assert.NoError(t, err)
+	// 达到上限时返回最后一个 3xx 响应
+	assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+	assert.Equal(t, []RedirectHop{
+		{URL: ts.URL + "/a", StatusCode: http.StatusFound, Location: "/b?step=1"},
+		{URL: ts.URL + "/b?step=1", StatusCode: http.StatusMovedPermanently, Location: "/c"},
+	}, resp.RedirectHistory)
+	assert.Equal(t, ts.URL+"/c", resp.FinalURL)
+
+	resp, err = Do(context.Background(), http.MethodGet, ts.URL+"/a", WithMaxRedirects(0))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Empty(t, resp.RedirectHistory)
+	assert.Equal(t, ts.URL+"/a", resp.FinalURL)
+}
+
+func TestWithSameHostRedirects(t *testing.T) {
+	other := redirectChainServer()
+	defer other.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/start":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		case "/login":
+			http.Redirect(w, r, other.URL+"/final", http.StatusSeeOther)
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL+"/start", WithSameHostRedirects())
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.Equal(t, []RedirectHop{
+		{URL: ts.URL + "/start", StatusCode: http.StatusFound, Location: "/login"},
+	}, resp.RedirectHistory)
+	assert.Equal(t, ts.URL+"/login", resp.FinalURL)
+
+	// 不限制时跟随到其他主机
+	resp, err = Do(context.Background(), http.MethodGet, ts.URL+"/start")
+	assert.NoError(t, err)
+	assert.Equal(t, "done", string(resp.Body))
+	assert.Len(t, resp.RedirectHistory, 2)
+	assert.Equal(t, other.URL+"/final", resp.FinalURL)
+}
@@ -0,0 +1,426 @@
+package resty
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// RequestOption 用于配置 Do 发起的单个请求
+//
+// 选项按传入顺序依次生效：请求头、查询参数和表单数据会合并，同名时后设置的覆盖先设置的；
+// 请求体、超时时间等单值配置以最后一次设置为准。
+type RequestOption func(*requestConfig)
+
+// requestConfig 单个请求的配置
+type requestConfig struct {
+	header      map[string]string
+	query       map[string]string
+	formData    map[string]string
+	files       []fileReader
+	multipart   *multipartBody
+	body        interface{}
+	entity      interface{}
+	timeout     time.Duration
+	tlsInsecure bool
+	retry       *RetryConfig
+	shadow      *shadowConfig
+	bodyLog     *BodyLogConfig
+	contentMD5  bool
+	proxyURL    string
+	proxyAuth   string
+	socks5      *socks5Config
+	redirect    *redirectConfig
+	host        string
+	dialer      *net.Dialer
+	pins        []string
+
+	maxHeaderBytes  *int64
+	idleReadTimeout time.Duration
+	overwrite       *bool
+	expectContinue  time.Duration
+
+	rawCompression bool
+	acceptEncoding string
+
+	// raw 为 true 时不读取响应体，由 DoRaw 交给调用方
+	raw bool
+}
+
+// fileReader multipart 请求中的单个文件
+type fileReader struct {
+	param    string
+	fileName string
+	reader   io.Reader
+}
+
+// newRequestConfig 按顺序应用所有选项，生成请求配置
+func newRequestConfig(opts ...RequestOption) *requestConfig {
+	cfg := &requestConfig{
+		header:  make(map[string]string),
+		query:   make(map[string]string),
+		timeout: DefaultTimeout * time.Second,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	return cfg
+}
+
+// WithHeaders 设置请求头，多次调用时会合并，后设置的同名 header 覆盖先设置的
+//
+// header 名称按 http.CanonicalHeaderKey 规范化后比较，大小写不同的同名 header 视为同一个。
+func WithHeaders(header map[string]string) RequestOption {
+	return func(c *requestConfig) {
+		for k, v := range header {
+			c.header[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+}
+
+// WithQuery 设置查询参数，多次调用时会合并，后设置的同名参数覆盖先设置的
+func WithQuery(query map[string]string) RequestOption {
+	return func(c *requestConfig) {
+		for k, v := range query {
+			c.query[k] = v
+		}
+	}
+}
+
+// WithBody 设置请求体，可以是 []byte、string、io.Reader 或其他 resty 支持的类型
+func WithBody(body interface{}) RequestOption {
+	return func(c *requestConfig) {
+		c.body = body
+	}
+}
+
+// WithJSONBody 设置 JSON 格式的请求体，并将 Content-Type 设置为 application/json
+func WithJSONBody(body interface{}) RequestOption {
+	return func(c *requestConfig) {
+		c.header[ContentType] = ContentTypeJson
+		c.body = body
+	}
+}
+
+// WithFormData 设置表单数据，并将 Content-Type 设置为 application/x-www-form-urlencoded
+//
+// 同时设置了 WithFileReader 时会以 multipart/form-data 格式发送。
+func WithFormData(formData map[string]string) RequestOption {
+	return func(c *requestConfig) {
+		c.header[ContentType] = ContentTypeForm
+		if c.formData == nil {
+			c.formData = make(map[string]string, len(formData))
+		}
+		for k, v := range formData {
+			c.formData[k] = v
+		}
+	}
+}
+
+// WithFileReader 添加 multipart 文件，请求会以 multipart/form-data 格式发送
+func WithFileReader(param, fileName string, reader io.Reader) RequestOption {
+	return func(c *requestConfig) {
+		c.files = append(c.files, fileReader{param: param, fileName: fileName, reader: reader})
+	}
+}
+
+// WithEntity 将 JSON 响应体解析到 entity 指向的对象中
+//
+// entity 可以指向任意类型，如 *User、*[]User、*string、*int64，
+// 响应的 JSON 类型与 entity 不匹配时返回 ErrJSONTypeMismatch，
+// 读取响应体时连接中途断开、收到的字节数少于 Content-Length 时返回 ErrTruncatedResponse。
+func WithEntity(entity interface{}) RequestOption {
+	return func(c *requestConfig) {
+		c.entity = entity
+	}
+}
+
+// WithTimeout 设置请求超时时间，默认为 DefaultTimeout
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(c *requestConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithTLSInsecure 跳过 TLS 证书验证，主要用于自签名证书或测试环境
+func WithTLSInsecure() RequestOption {
+	return func(c *requestConfig) {
+		c.tlsInsecure = true
+	}
+}
+
+// Do 使用任意 HTTP 方法发送请求，并返回完整的响应信息
+//
+// 包内的便捷函数（Get、Post、Json 等）都是对 Do 的简单封装。
+// 非 2xx 状态码不会作为错误返回，调用方可以通过 Response.StatusCode 自行判断。
+//
+// 参数:
+//   - ctx: 请求上下文，用于取消请求
+//   - method: HTTP 方法，支持 REPORT、PROPFIND 等任意方法
+//   - url: 目标请求地址
+//   - opts: 请求选项，按传入顺序生效
+//
+// 返回值:
+//   - *Response: 完整的响应信息
+//   - err: 请求过程中的错误信息或实体解析错误，如果请求成功则为 nil
+//
+// 示例:
+//
+//	var user User
+//	resp, err := Do(ctx, http.MethodPost, "https://api.example.com/users",
+//	    WithHeaders(map[string]string{"Authorization": "Bearer token123"}),
+//	    WithJSONBody(map[string]interface{}{"name": "test"}),
+//	    WithEntity(&user),
+//	    WithRetry(RetryConfig{MaxRetries: 2}),
+//	)
+func Do(ctx context.Context, method, url string, opts ...RequestOption) (*Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, done, err := track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	cfg := newRequestConfig(opts...)
+
+	var shadowCfg *requestConfig
+	if cfg.shadow != nil {
+		if shadowCfg, err = cfg.shadow.prepare(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := execute(ctx, method, url, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if shadowCfg != nil {
+		cfg.shadow.mirror(method, url, shadowCfg, resp)
+	}
+
+	if cfg.entity != nil {
+		if err = decodeEntity(resp.Body, cfg.entity); err != nil {
+			err = truncatedError(resp.Body, resp.ContentLength, err)
+			zap.L().Error("Json Transform Error", zap.Error(err))
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// doBody 使用默认上下文发送请求，只返回响应体
+func doBody(method, url string, opts ...RequestOption) ([]byte, error) {
+	resp, err := Do(context.Background(), method, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// execute 根据请求配置发送请求，配置了重试时按重试策略重复发送
+//
+// 配置了 OverallTimeout 时，所有请求和重试等待共享同一个总超时上下文；
+// 配置了 PerAttemptTimeout 时，每次请求在其派生的上下文中进行，两者中先到期的生效。
+func execute(ctx context.Context, method, url string, cfg *requestConfig) (*Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	retry := cfg.retry
+	retryCtx, cancel := retry.withOverallTimeout(ctx)
+	defer cancel()
+
+	res, err := executeAttempt(ctx, retryCtx, method, url, cfg)
+	if retry != nil && cfg.replayable() {
+		for attempt := 0; attempt < retry.MaxRetries && retry.shouldRetry(res, err); attempt++ {
+			delay := retry.backoff(attempt)
+			retry.notifyRetry(attempt+1, RequestInfo{Method: method, URL: url}, res, err, delay)
+			if err = sleepContext(retryCtx, delay); err != nil {
+				return nil, retry.budgetError(ctx, retryCtx, retryCtx, err)
+			}
+			if err = cfg.rewind(); err != nil {
+				return nil, err
+			}
+			res, err = executeAttempt(ctx, retryCtx, method, url, cfg)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(res), nil
+}
+
+// executeAttempt 在单次超时的上下文中发送一次请求，并为超时错误标明耗尽的预算
+func executeAttempt(parent, retryCtx context.Context, method, url string, cfg *requestConfig) (*resty.Response, error) {
+	attemptCtx, cancel := cfg.retry.withAttemptTimeout(retryCtx)
+	res, err := executeOnce(attemptCtx, method, url, cfg)
+	err = cfg.retry.budgetError(parent, retryCtx, attemptCtx, err)
+	if cfg.raw && err == nil {
+		// 响应体由调用方读取，单次超时继续限制读取过程，到期后自动取消
+		return res, nil
+	}
+	cancel()
+	return res, err
+}
+
+// executeOnce 根据请求配置发送一次请求
+func executeOnce(ctx context.Context, method, url string, cfg *requestConfig) (*resty.Response, error) {
+	client := newClient(cfg.retry.clientTimeout(cfg.timeout))
+	if cfg.tlsInsecure {
+		client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	}
+	if cfg.redirect != nil {
+		cfg.redirect.apply(client)
+	}
+	if cfg.host != "" {
+		if err := applyHostTLS(client, cfg.host); err != nil {
+			return nil, err
+		}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if cfg.idleReadTimeout > 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	req := client.R().SetContext(ctx).SetHeaders(cfg.header)
+	if cfg.idleReadTimeout > 0 || cfg.raw {
+		req.SetDoNotParseResponse(true)
+	}
+	if len(cfg.query) > 0 {
+		req.SetQueryParams(cfg.query)
+	}
+	if cfg.formData != nil {
+		req.SetFormData(cfg.formData)
+	}
+	for _, file := range cfg.files {
+		req.SetFileReader(file.param, file.fileName, file.reader)
+	}
+	if cfg.contentMD5 {
+		sum, err := cfg.bodyContentMD5()
+		if err != nil {
+			return nil, err
+		}
+		req.SetHeader(ContentMD5, sum)
+	}
+	if cfg.body != nil {
+		req.SetBody(cfg.body)
+	}
+	if cfg.multipart != nil {
+		body, contentType, err := cfg.multipart.encode()
+		if err != nil {
+			return nil, err
+		}
+		req.SetBody(body).SetHeader(ContentType, contentType)
+	}
+	if cfg.rawCompression {
+		if err := applyRawCompression(client, req, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if len(cfg.pins) > 0 {
+		if err := applyPins(client, url, cfg.pins); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.dialer != nil {
+		if err := applyDialer(client, cfg.dialer); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyProxy(client, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.maxHeaderBytes != nil {
+		if err := setMaxResponseHeaderBytes(client, *cfg.maxHeaderBytes); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.expectContinue > 0 {
+		if err := applyExpectContinue(client, req.Header, cfg.expectContinue); err != nil {
+			return nil, err
+		}
+	}
+	sampled := cfg.bodyLog != nil && sampleLog(ctx)
+	if cfg.bodyLog != nil || cfg.proxyAuth != "" || cfg.host != "" {
+		client.SetPreRequestHook(func(c *resty.Client, r *http.Request) error {
+			if cfg.host != "" {
+				r.Host = cfg.host
+			}
+			if cfg.proxyAuth != "" {
+				if err := setProxyAuthorization(c, r, cfg.proxyAuth); err != nil {
+					return err
+				}
+			}
+			if cfg.bodyLog != nil && sampled {
+				cfg.bodyLog.logRequestBody(r)
+			}
+			return nil
+		})
+	}
+
+	res, err := req.Execute(method, url)
+	if err = proxyAuthError(res, contentLengthError(res, headerLimitError(truncatedReadError(res, err)))); err != nil {
+		if cfg.bodyLog != nil && cfg.bodyLog.shouldLogResult(res, err, sampled) {
+			cfg.bodyLog.logError(method, url, err)
+		}
+		if cfg.rawCompression || cfg.idleReadTimeout > 0 || cfg.raw {
+			drainAndClose(res)
+		}
+		return res, err
+	}
+	if cfg.raw {
+		if cfg.bodyLog != nil && cfg.bodyLog.shouldLogResult(res, nil, sampled) {
+			cfg.bodyLog.logRawResponse(res.RawResponse)
+		}
+		return res, nil
+	}
+	switch {
+	case cfg.idleReadTimeout > 0:
+		res, err = readIdleBody(res, cfg.idleReadTimeout, cancel)
+	case cfg.rawCompression:
+		res, err = readRawBody(res)
+	}
+	if cfg.idleReadTimeout > 0 || cfg.rawCompression {
+		// 未经 resty 解析的响应体不会经过 OnAfterResponse 钩子
+		addPayloadSizes(res)
+		if err != nil {
+			err = contentLengthError(res, err)
+		} else if strictContentLength.Load() {
+			err = verifyContentLength(res)
+		}
+	}
+	if cfg.bodyLog != nil && cfg.bodyLog.shouldLogResult(res, err, sampled) {
+		if err != nil {
+			cfg.bodyLog.logError(method, url, err)
+		} else {
+			cfg.bodyLog.logResponseBody(res)
+		}
+	}
+	return res, err
+}
+
+// newClient 创建一个设置了超时时间的 resty 客户端，并注册默认查询参数、请求签名、延迟统计、流量统计和响应体长度校验钩子
+func newClient(timeout time.Duration) *resty.Client {
+	client := resty.New()
+	client.SetTimeout(timeout)
+	_ = setMaxResponseHeaderBytes(client, DefaultMaxResponseHeaderBytes)
+	client.OnBeforeRequest(applyDefaultQuery)
+	client.OnBeforeRequest(signRequest)
+	client.OnAfterResponse(recordLatency)
+	client.OnAfterResponse(recordPayloadSizes)
+	client.OnAfterResponse(checkContentLength)
+	return client
+}
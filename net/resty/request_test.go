@@ -0,0 +1,146 @@
+package resty_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestDo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/test", r.URL.Path)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, `{"name":"test"}`, string(body))
+
+		w.Header().Set("X-Test-Header", "test-value")
+		w.WriteHeader(http.StatusCreated)
+		_, err = io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodPut, ts.URL+"/test",
+		WithHeaders(map[string]string{"Authorization": "test-token"}),
+		WithBody(map[string]string{"name": "test"}),
+		WithTimeout(30*time.Second),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "test-value", resp.Header.Get("X-Test-Header"))
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp.Body)
+	assert.NotNil(t, resp.RawResponse)
+}
+
+func TestDoCanceledContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err := Do(ctx, http.MethodGet, ts.URL)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, resp)
+}
+
+func TestDoCustomMethod(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PROPFIND", r.Method)
+		assert.Equal(t, "1", r.Header.Get("Depth"))
+		assert.Equal(t, "all", r.URL.Query().Get("props"))
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, `<propfind xmlns="DAV:"><allprop/></propfind>`, string(body))
+
+		w.WriteHeader(http.StatusMultiStatus)
+		_, err = io.WriteString(w, `<multistatus xmlns="DAV:"/>`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), "PROPFIND", ts.URL+"/dav",
+		WithHeaders(map[string]string{"Depth": "1", "Content-Type": "application/xml"}),
+		WithQuery(map[string]string{"props": "all"}),
+		WithBody(`<propfind xmlns="DAV:"><allprop/></propfind>`),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	assert.Equal(t, []byte(`<multistatus xmlns="DAV:"/>`), resp.Body)
+}
+
+func TestDoOptionOrder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 后设置的同名 header 和查询参数覆盖先设置的，不同名的合并
+		assert.Equal(t, "second", r.Header.Get("X-Test-Header"))
+		assert.Equal(t, "kept", r.Header.Get("X-Other-Header"))
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+		assert.Equal(t, "10", r.URL.Query().Get("size"))
+
+		// 最后设置的请求体生效
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, `{"name":"json"}`, string(body))
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.WriteString(w, `{"status":"ok","data":"test"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	var entity TestResponse
+	resp, err := Do(context.Background(), http.MethodPost, ts.URL,
+		WithHeaders(map[string]string{"X-Test-Header": "first", "X-Other-Header": "kept"}),
+		WithHeaders(map[string]string{"x-test-header": "second"}),
+		WithQuery(map[string]string{"page": "1", "size": "10"}),
+		WithQuery(map[string]string{"page": "2"}),
+		WithBody("raw body"),
+		WithJSONBody(map[string]string{"name": "json"}),
+		WithTimeout(time.Nanosecond),
+		WithTimeout(30*time.Second),
+		WithEntity(&entity),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", entity.Status)
+	assert.Equal(t, "test", entity.Data)
+}
+
+func TestDoTLSInsecure(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	_, err := Do(context.Background(), http.MethodGet, ts.URL)
+	assert.Error(t, err, "self-signed certificate should be rejected by default")
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL, WithTLSInsecure())
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
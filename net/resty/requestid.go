@@ -0,0 +1,80 @@
+package resty
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/yocover/global-toolkit/net/rpc"
+)
+
+// PostWithRequestID 发送携带请求 ID 的 POST 请求，并返回实际使用的请求 ID
+//
+// 等同于使用 context.Background() 调用 PostWithRequestIDContext，请求 ID 的来源和格式与其一致。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - body: 请求体内容，可以是任意类型
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - string: 本次请求使用的请求 ID
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	resp, requestID, err := PostWithRequestID("https://api.example.com", body, nil)
+//	ctx = rpc.WithRequestID(ctx, requestID)
+func PostWithRequestID(url string, body interface{}, header map[string]string) ([]byte, string, error) {
+	resp, ctx, err := PostWithRequestIDContext(context.Background(), url, body, header)
+	requestID, _ := rpc.RequestIDFromContext(ctx)
+	return resp, requestID, err
+}
+
+// PostWithRequestIDContext 发送携带请求 ID 的 POST 请求，并返回包含该请求 ID 的上下文
+//
+// 请求 ID 按以下顺序确定：
+//  1. header 中的 X-Request-Id（不区分大小写）；
+//  2. 上下文中的请求 ID（rpc.RequestIDFromContext），如 rpc.HTTPMiddleware 从入站请求中提取的值；
+//  3. 通过 rpc.EnsureRequestID 生成，rpc.SetRequestIDGenerator 设置的生成函数同样生效。
+//
+// 返回的上下文通过 rpc.WithRequestID 记录了本次使用的请求 ID，后续的下游调用使用该上下文即可携带同一个请求 ID。
+//
+// 参数:
+//   - ctx: 请求上下文，用于取消请求和读取请求 ID
+//   - url: 目标请求地址
+//   - body: 请求体内容，可以是任意类型
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - context.Context: 包含请求 ID 的上下文，请求失败时同样返回
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	resp, ctx, err := PostWithRequestIDContext(r.Context(), "https://api.example.com", body, nil)
+//	requestID, _ := rpc.RequestIDFromContext(ctx)
+func PostWithRequestIDContext(ctx context.Context, url string, body interface{}, header map[string]string) ([]byte, context.Context, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for k, v := range header {
+		if strings.EqualFold(k, rpc.RequestIDHeader) && v != "" {
+			ctx = rpc.WithRequestID(ctx, v)
+			break
+		}
+	}
+	ctx, requestID := rpc.EnsureRequestID(ctx)
+
+	resp, err := Do(ctx, http.MethodPost, url,
+		WithHeaders(header),
+		WithHeaders(map[string]string{rpc.RequestIDHeader: requestID}),
+		WithBody(body),
+	)
+	if err != nil {
+		return nil, ctx, err
+	}
+	return resp.Body, ctx, nil
+}
@@ -0,0 +1,86 @@
+package resty_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+	"github.com/yocover/global-toolkit/net/rpc"
+)
+
+func TestPostWithRequestID(t *testing.T) {
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Len(t, r.Header.Values(rpc.RequestIDHeader), 1)
+		received = append(received, r.Header.Get(rpc.RequestIDHeader))
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	// 未提供时生成新的请求 ID
+	resp, id, err := PostWithRequestID(ts.URL, `{"name":"test"}`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	assert.Equal(t, id, received[0])
+
+	// 每次生成的请求 ID 不同
+	_, other, err := PostWithRequestID(ts.URL, nil, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, other)
+
+	// 已提供时沿用，header 名称不区分大小写
+	_, id, err = PostWithRequestID(ts.URL, nil, map[string]string{"X-Request-ID": "given-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, "given-id", id)
+	assert.Equal(t, "given-id", received[2])
+}
+
+func TestPostWithRequestIDContext(t *testing.T) {
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(rpc.RequestIDHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	// 沿用上下文中的请求 ID
+	parent := rpc.WithRequestID(context.Background(), "from-ctx")
+	_, ctx, err := PostWithRequestIDContext(parent, ts.URL, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-ctx", received[0])
+	id, ok := rpc.RequestIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "from-ctx", id)
+
+	// header 中的值优先于上下文，返回的上下文记录实际使用的值
+	_, ctx, err = PostWithRequestIDContext(parent, ts.URL, nil, map[string]string{"x-request-id": "from-header"})
+	assert.NoError(t, err)
+	assert.Equal(t, "from-header", received[1])
+	id, _ = rpc.RequestIDFromContext(ctx)
+	assert.Equal(t, "from-header", id)
+
+	// 没有请求 ID 时使用 rpc 的生成函数
+	rpc.SetRequestIDGenerator(func() string { return "generated" })
+	t.Cleanup(func() { rpc.SetRequestIDGenerator(nil) })
+
+	_, ctx, err = PostWithRequestIDContext(context.Background(), ts.URL, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "generated", received[2])
+	id, _ = rpc.RequestIDFromContext(ctx)
+	assert.Equal(t, "generated", id)
+
+	_, id, err = PostWithRequestID(ts.URL, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "generated", id)
+	assert.Equal(t, "generated", received[3])
+}
@@ -0,0 +1,153 @@
+package resty
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Response 完整的 HTTP 响应信息
+type Response struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+	// Header 响应头
+	Header http.Header
+	// Body 响应体
+	Body []byte
+	// ContentEncoding 响应体的原始编码，仅在 WithRawCompression 模式下保留，透明解压后为空
+	ContentEncoding string
+	// ContentLength 服务端声明的响应体长度，未知或已透明解压时为 -1
+	ContentLength int64
+	// Duration 请求耗时
+	Duration time.Duration
+	// RedirectHistory 按顺序记录已跟随的重定向，没有重定向时为空
+	RedirectHistory []RedirectHop
+	// FinalURL 跟随重定向后最终请求的地址，没有重定向时为原始请求地址
+	FinalURL string
+	// TLS 连接协商的 TLS 信息，非 HTTPS 请求时为 nil
+	TLS *TLSInfo
+	// RawResponse resty 原始响应，用于访问未封装的信息
+	RawResponse *resty.Response
+}
+
+// newResponse 根据 resty 响应构建 Response
+func newResponse(res *resty.Response) *Response {
+	resp := &Response{
+		StatusCode:      res.StatusCode(),
+		Header:          res.Header(),
+		Body:            res.Body(),
+		ContentEncoding: res.Header().Get("Content-Encoding"),
+		ContentLength:   -1,
+		Duration:        res.Time(),
+		RawResponse:     res,
+	}
+	if res.RawResponse != nil {
+		resp.ContentLength = res.RawResponse.ContentLength
+		resp.RedirectHistory, resp.FinalURL = redirectHistory(res.RawResponse)
+		resp.TLS = newTLSInfo(res.RawResponse.TLS)
+	}
+	return resp
+}
+
+// Class HTTP 状态码的分类
+type Class int
+
+// HTTP 状态码分类的定义
+const (
+	// ClassUnknown 不在 100-599 范围内的状态码
+	ClassUnknown Class = iota
+	// ClassInformational 1xx 信息响应
+	ClassInformational
+	// ClassSuccess 2xx 成功响应
+	ClassSuccess
+	// ClassRedirect 3xx 重定向
+	ClassRedirect
+	// ClassClientError 4xx 客户端错误
+	ClassClientError
+	// ClassServerError 5xx 服务端错误
+	ClassServerError
+)
+
+// String 返回分类的名称
+func (c Class) String() string {
+	switch c {
+	case ClassInformational:
+		return "informational"
+	case ClassSuccess:
+		return "success"
+	case ClassRedirect:
+		return "redirect"
+	case ClassClientError:
+		return "client error"
+	case ClassServerError:
+		return "server error"
+	default:
+		return "unknown"
+	}
+}
+
+// StatusClass 返回状态码所属的分类
+//
+// 参数:
+//   - code: HTTP 状态码
+//
+// 返回值:
+//   - Class: 状态码分类，不在 100-599 范围内时为 ClassUnknown
+//
+// 示例:
+//
+//	if StatusClass(code) == ClassServerError {
+//	    // 重试
+//	}
+func StatusClass(code int) Class {
+	switch {
+	case code >= 100 && code <= 199:
+		return ClassInformational
+	case code >= 200 && code <= 299:
+		return ClassSuccess
+	case code >= 300 && code <= 399:
+		return ClassRedirect
+	case code >= 400 && code <= 499:
+		return ClassClientError
+	case code >= 500 && code <= 599:
+		return ClassServerError
+	default:
+		return ClassUnknown
+	}
+}
+
+// IsSuccess 状态码是否为 2xx
+func (r *Response) IsSuccess() bool {
+	return StatusClass(r.StatusCode) == ClassSuccess
+}
+
+// IsRedirect 状态码是否为 3xx
+func (r *Response) IsRedirect() bool {
+	return StatusClass(r.StatusCode) == ClassRedirect
+}
+
+// IsClientError 状态码是否为 4xx
+func (r *Response) IsClientError() bool {
+	return StatusClass(r.StatusCode) == ClassClientError
+}
+
+// IsServerError 状态码是否为 5xx
+func (r *Response) IsServerError() bool {
+	return StatusClass(r.StatusCode) == ClassServerError
+}
+
+// Is 状态码是否等于 status
+func (r *Response) Is(status int) bool {
+	return r.StatusCode == status
+}
+
+// OneOf 状态码是否为 statuses 中的任意一个
+func (r *Response) OneOf(statuses ...int) bool {
+	for _, status := range statuses {
+		if r.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package resty_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestStatusClass(t *testing.T) {
+	tests := []struct {
+		code     int
+		expected Class
+	}{
+		{code: 0, expected: ClassUnknown},
+		{code: 99, expected: ClassUnknown},
+		{code: 100, expected: ClassInformational},
+		{code: 199, expected: ClassInformational},
+		{code: 200, expected: ClassSuccess},
+		{code: 226, expected: ClassSuccess},
+		{code: 299, expected: ClassSuccess},
+		{code: 300, expected: ClassRedirect},
+		{code: 308, expected: ClassRedirect},
+		{code: 399, expected: ClassRedirect},
+		{code: 400, expected: ClassClientError},
+		{code: 499, expected: ClassClientError},
+		{code: 500, expected: ClassServerError},
+		{code: 599, expected: ClassServerError},
+		{code: 600, expected: ClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.code)+tt.expected.String(), func(t *testing.T) {
+			assert.Equal(t, tt.expected, StatusClass(tt.code), "class mismatch for %d", tt.code)
+
+			resp := &Response{StatusCode: tt.code}
+			assert.Equal(t, tt.expected == ClassSuccess, resp.IsSuccess())
+			assert.Equal(t, tt.expected == ClassRedirect, resp.IsRedirect())
+			assert.Equal(t, tt.expected == ClassClientError, resp.IsClientError())
+			assert.Equal(t, tt.expected == ClassServerError, resp.IsServerError())
+		})
+	}
+}
+
+func TestResponseIsAndOneOf(t *testing.T) {
+	resp := &Response{StatusCode: http.StatusConflict}
+
+	assert.True(t, resp.Is(http.StatusConflict))
+	assert.False(t, resp.Is(http.StatusOK))
+	assert.True(t, resp.OneOf(http.StatusNotFound, http.StatusConflict))
+	assert.False(t, resp.OneOf(http.StatusNotFound, http.StatusGone))
+	assert.False(t, resp.OneOf())
+}
+
+func TestClassString(t *testing.T) {
+	assert.Equal(t, "success", ClassSuccess.String())
+	assert.Equal(t, "server error", ClassServerError.String())
+	assert.Equal(t, "unknown", Class(100).String())
+}
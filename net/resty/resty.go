@@ -1,6 +1,7 @@
 package resty
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"io"
@@ -8,7 +9,6 @@ import (
 	"time"
 
 	"github.com/go-resty/resty/v2"
-	"go.uber.org/zap"
 )
 
 // DefaultTimeout 默认的 HTTP 请求超时时间（秒）
@@ -24,8 +24,38 @@ const (
 	ContentTypeForm = "application/x-www-form-urlencoded"
 	// ContentTypeMultipartForm 多部分表单格式的 Content-Type 值
 	ContentTypeMultipartForm = "multipart/form-data"
+	// ContentTypeMergePatch JSON Merge Patch（RFC 7396）格式的 Content-Type 值
+	ContentTypeMergePatch = "application/merge-patch+json"
+	// ContentTypeJSONPatch JSON Patch（RFC 6902）格式的 Content-Type 值
+	ContentTypeJSONPatch = "application/json-patch+json"
 )
 
+// Operation JSON Patch（RFC 6902）中的单个操作
+type Operation struct {
+	// Op 操作类型：add、remove、replace、move、copy、test
+	Op string `json:"op"`
+	// Path 目标位置的 JSON Pointer
+	Path string `json:"path"`
+	// From move、copy 操作的源位置
+	From string `json:"from,omitempty"`
+	// Value add、replace、test 操作使用的值
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON 序列化 JSON Patch 操作，remove、move、copy 操作不输出 value 字段
+func (o Operation) MarshalJSON() ([]byte, error) {
+	switch o.Op {
+	case "remove", "move", "copy":
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+			From string `json:"from,omitempty"`
+		}{o.Op, o.Path, o.From})
+	}
+	type operation Operation
+	return json.Marshal(operation(o))
+}
+
 // GetRequest 创建一个基础的 HTTP 请求客户端
 //
 // 参数:
@@ -39,9 +69,12 @@ const (
 //	req := GetRequest(30)
 //	resp, err := req.Get("https://api.example.com")
 func GetRequest(timout int64) *resty.Request {
-	client := resty.New()
-	client.SetTimeout(time.Duration(timout) * time.Second)
-	return client.R()
+	return newClient(seconds(timout)).R().SetContext(rootContext())
+}
+
+// seconds 将以秒为单位的超时时间转换为 time.Duration
+func seconds(timeout int64) time.Duration {
+	return time.Duration(timeout) * time.Second
 }
 
 // GetHttpsRequest 创建一个支持 HTTPS 的 HTTP 请求客户端，会跳过 TLS 证书验证
@@ -57,15 +90,13 @@ func GetRequest(timout int64) *resty.Request {
 //	req := GetHttpsRequest(30)
 //	resp, err := req.Get("https://api.example.com")
 func GetHttpsRequest(timout int64) *resty.Request {
-	// 创建新的resty客户端
-	client := resty.New()
+	// 创建新的resty客户端并设置超时时间
+	client := newClient(seconds(timout))
 	// 配置TLS ，跳过证书验证
 	client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
 
-	// 设置超时时间
-	client.SetTimeout(time.Duration(timout) * time.Second)
 	// 创建请求对象并启用追踪
-	return client.R().EnableTrace()
+	return client.R().SetContext(rootContext()).EnableTrace()
 }
 
 // Get 发送一个简单的 HTTP GET 请求
@@ -85,12 +116,7 @@ func GetHttpsRequest(timout int64) *resty.Request {
 //	}
 //	fmt.Println(string(resp))
 func Get(url string) (resp []byte, err error) {
-	request, err := GetRequest(DefaultTimeout).Get(url)
-	if err != nil {
-		return nil, err
-	}
-	resp = request.Body()
-	return resp, nil
+	return GetOpt(url, nil)
 }
 
 // GetWithHeaders 发送带自定义请求头的 HTTP GET 请求
@@ -111,12 +137,7 @@ func Get(url string) (resp []byte, err error) {
 //	}
 //	resp, err := GetWithHeaders("https://api.example.com", headers)
 func GetWithHeaders(url string, header map[string]string) (resp []byte, err error) {
-	request, err := GetRequest(DefaultTimeout).SetHeaders(header).Get(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return GetOpt(url, &Options{Headers: header})
 }
 
 // HttpsGetWithHeaders 发送带自定义请求头的 HTTPS GET 请求，会跳过 TLS 证书验证
@@ -141,12 +162,7 @@ func GetWithHeaders(url string, header map[string]string) (resp []byte, err erro
 //	}
 //	resp, err := HttpsGetWithHeaders("https://api.example.com", headers)
 func HttpsGetWithHeaders(url string, header map[string]string) (resp []byte, err error) {
-	request, err := GetHttpsRequest(DefaultTimeout).SetHeaders(header).Get(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return GetOpt(url, &Options{Headers: header, TLSInsecure: true})
 }
 
 // HttpsGet 发送一个简单的 HTTPS GET 请求，会跳过 TLS 证书验证
@@ -175,12 +191,7 @@ func HttpsGetWithHeaders(url string, header map[string]string) (resp []byte, err
 //	}
 //	fmt.Println(string(resp))
 func HttpsGet(url string) (resp []byte, err error) {
-	request, err := GetHttpsRequest(DefaultTimeout).Get(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return GetOpt(url, &Options{TLSInsecure: true})
 }
 
 // GetWithEntity 发送 GET 请求并将响应解析为指定的实体对象
@@ -202,17 +213,7 @@ func HttpsGet(url string) (resp []byte, err error) {
 //	headers := map[string]string{"Authorization": "Bearer token123"}
 //	err := GetWithEntity("https://api.example.com/user", &user, headers, 30)
 func GetWithEntity(url string, entity interface{}, header map[string]string, timeout int64) error {
-	request, err := GetRequest(timeout).SetHeaders(header).Get(url)
-	if err != nil {
-		return err
-	}
-	resp := request.Body()
-
-	err = json.Unmarshal(resp, &entity)
-	if err != nil {
-		zap.L().Error("Json Transform Error", zap.Error(err))
-		return err
-	}
+	_, err := GetOpt(url, &Options{Headers: header, Timeout: seconds(timeout), Entity: entity})
 	return err
 }
 
@@ -232,12 +233,7 @@ func GetWithEntity(url string, entity interface{}, header map[string]string, tim
 //	headers := map[string]string{"Authorization": "Bearer token123"}
 //	resp, err := GetWithTimeOut("https://api.example.com", headers, 30)
 func GetWithTimeOut(url string, header map[string]string, timeout int64) (resp []byte, err error) {
-	request, err := GetRequest(timeout).SetHeaders(header).Get(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return GetOpt(url, &Options{Headers: header, Timeout: seconds(timeout)})
 }
 
 // HttpsGetWithTimeOut 发送带超时设置的 HTTPS GET 请求，会跳过 TLS 证书验证
@@ -260,12 +256,7 @@ func GetWithTimeOut(url string, header map[string]string, timeout int64) (resp [
 //	headers := map[string]string{"Authorization": "Bearer token123"}
 //	resp, err := HttpsGetWithTimeOut("https://api.example.com", headers, 30)
 func HttpsGetWithTimeOut(url string, header map[string]string, timeout int64) (resp []byte, err error) {
-	request, err := GetHttpsRequest(timeout).SetHeaders(header).Get(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return GetOpt(url, &Options{Headers: header, Timeout: seconds(timeout), TLSInsecure: true})
 }
 
 // Post 发送一个简单的 HTTP POST 请求
@@ -285,12 +276,7 @@ func HttpsGetWithTimeOut(url string, header map[string]string, timeout int64) (r
 //	headers := map[string]string{"Content-Type": "application/json"}
 //	resp, err := Post("https://api.example.com", body, headers)
 func Post(url string, body interface{}, header map[string]string) (resp []byte, err error) {
-	request, err := GetRequest(DefaultTimeout).SetHeaders(header).SetBody(body).Post(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return PostOpt(url, body, &Options{Headers: header})
 }
 
 // PostWithTimeOut 发送带超时设置的 HTTP POST 请求
@@ -311,12 +297,7 @@ func Post(url string, body interface{}, header map[string]string) (resp []byte,
 //	headers := map[string]string{"Content-Type": "application/json"}
 //	resp, err := PostWithTimeOut("https://api.example.com", body, headers, 30)
 func PostWithTimeOut(url string, body interface{}, header map[string]string, timeout int64) (resp []byte, err error) {
-	request, err := GetRequest(timeout).SetHeaders(header).SetBody(body).Post(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return PostOpt(url, body, &Options{Headers: header, Timeout: seconds(timeout)})
 }
 
 // HttpsPost 发送一个 HTTPS POST 请求，会跳过 TLS 证书验证
@@ -340,12 +321,7 @@ func PostWithTimeOut(url string, body interface{}, header map[string]string, tim
 //	headers := map[string]string{"Content-Type": "application/json"}
 //	resp, err := HttpsPost("https://api.example.com", body, headers)
 func HttpsPost(url string, body interface{}, header map[string]string) (resp []byte, err error) {
-	request, err := GetHttpsRequest(DefaultTimeout).SetHeaders(header).SetBody(body).Post(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return PostOpt(url, body, &Options{Headers: header, TLSInsecure: true})
 }
 
 // HttpsPostWithTimeOut 发送带超时设置的 HTTPS POST 请求，会跳过 TLS 证书验证
@@ -370,12 +346,7 @@ func HttpsPost(url string, body interface{}, header map[string]string) (resp []b
 //	headers := map[string]string{"Content-Type": "application/json"}
 //	resp, err := HttpsPostWithTimeOut("https://api.example.com", body, headers, 30)
 func HttpsPostWithTimeOut(url string, body interface{}, header map[string]string, timeout int64) (resp []byte, err error) {
-	request, err := GetHttpsRequest(timeout).SetHeaders(header).SetBody(body).Post(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return PostOpt(url, body, &Options{Headers: header, Timeout: seconds(timeout), TLSInsecure: true})
 }
 
 // PostWithEntity 发送 POST 请求并将响应解析为指定的实体对象
@@ -399,17 +370,7 @@ func HttpsPostWithTimeOut(url string, body interface{}, header map[string]string
 //	headers := map[string]string{"Content-Type": "application/json"}
 //	err := PostWithEntity("https://api.example.com", body, headers, &response, 30)
 func PostWithEntity(url string, body interface{}, header map[string]string, entity interface{}, timeout int64) error {
-	request, err := GetRequest(timeout).SetHeaders(header).SetBody(body).Post(url)
-	if err != nil {
-		return err
-	}
-	resp := request.Body()
-
-	err = json.Unmarshal(resp, &entity)
-	if err != nil {
-		zap.L().Error("Json Transform Error", zap.Error(err))
-		return err
-	}
+	_, err := PostOpt(url, body, &Options{Headers: header, Timeout: seconds(timeout), Entity: entity})
 	return err
 }
 
@@ -432,12 +393,52 @@ func PostWithEntity(url string, body interface{}, header map[string]string, enti
 //	headers := map[string]string{"Authorization": "Bearer token123"}
 //	resp, err := Json("https://api.example.com", body, headers)
 func Json(url string, body interface{}, header map[string]string) (resp []byte, err error) {
-	request, err := GetRequest(DefaultTimeout).SetHeaders(header).SetHeader(ContentType, ContentTypeJson).SetBody(body).Post(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return doBody(http.MethodPost, url, WithHeaders(header), WithJSONBody(body))
+}
+
+// JsonMergePatch 发送 JSON Merge Patch（RFC 7396）格式的 PATCH 请求
+//
+// 自动设置 Content-Type 为 application/merge-patch+json，用于资源的部分更新。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - body: 合并补丁文档，将被序列化为 JSON
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - err: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	patch := map[string]interface{}{"name": "new-name", "description": nil}
+//	resp, err := JsonMergePatch("https://api.example.com/users/1", patch, nil)
+func JsonMergePatch(url string, body interface{}, header map[string]string) (resp []byte, err error) {
+	return doBody(http.MethodPatch, url, WithHeaders(header), WithHeaders(map[string]string{ContentType: ContentTypeMergePatch}), WithBody(body))
+}
+
+// JsonPatch 发送 JSON Patch（RFC 6902）格式的 PATCH 请求
+//
+// 自动设置 Content-Type 为 application/json-patch+json，操作列表按顺序序列化为 JSON 数组。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - operations: JSON Patch 操作列表
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - err: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	operations := []Operation{
+//	    {Op: "replace", Path: "/name", Value: "new-name"},
+//	    {Op: "remove", Path: "/description"},
+//	}
+//	resp, err := JsonPatch("https://api.example.com/users/1", operations, nil)
+func JsonPatch(url string, operations []Operation, header map[string]string) (resp []byte, err error) {
+	return doBody(http.MethodPatch, url, WithHeaders(header), WithHeaders(map[string]string{ContentType: ContentTypeJSONPatch}), WithBody(operations))
 }
 
 // Form 发送 x-www-form-urlencoded 格式的 POST 请求
@@ -459,12 +460,7 @@ func Json(url string, body interface{}, header map[string]string) (resp []byte,
 //	headers := map[string]string{"Authorization": "Bearer token123"}
 //	resp, err := Form("https://api.example.com", formData, headers)
 func Form(url string, FormData map[string]string, header map[string]string) (resp []byte, err error) {
-	request, err := GetRequest(DefaultTimeout).SetHeaders(header).SetHeader(ContentType, ContentTypeForm).SetFormData(FormData).Post(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return doBody(http.MethodPost, url, WithHeaders(header), WithFormData(FormData))
 }
 
 // File 发送带文件的 POST 请求
@@ -490,12 +486,7 @@ func Form(url string, FormData map[string]string, header map[string]string) (res
 //	headers := map[string]string{"Authorization": "Bearer token123"}
 //	resp, err := File("https://api.example.com", formData, headers, "file", "test.txt", file)
 func File(url string, FormData map[string]string, header map[string]string, param, fileName string, reader io.Reader) (resp []byte, err error) {
-	request, err := GetRequest(DefaultTimeout).SetHeaders(header).SetHeader(ContentType, ContentTypeForm).SetFormData(FormData).SetFileReader(param, fileName, reader).Post(url)
-	if err != nil {
-		return
-	}
-	resp = request.Body()
-	return
+	return doBody(http.MethodPost, url, WithHeaders(header), WithFormData(FormData), WithFileReader(param, fileName, reader))
 }
 
 // HttpsPostWithTimeOutResHeader 发送带超时设置的 HTTPS POST 请求，并返回响应头
@@ -521,11 +512,11 @@ func File(url string, FormData map[string]string, header map[string]string, para
 //	headers := map[string]string{"Content-Type": "application/json"}
 //	resp, resHeaders, err := HttpsPostWithTimeOutResHeader("https://api.example.com", body, headers, 30)
 func HttpsPostWithTimeOutResHeader(url string, body interface{}, header map[string]string, timeout int64) (resp []byte, resHeader http.Header, err error) {
-	res, err := GetHttpsRequest(timeout).SetHeaders(header).SetBody(body).Post(url)
+	res, err := Do(context.Background(), http.MethodPost, url, WithHeaders(header), WithBody(body), WithTimeout(seconds(timeout)), WithTLSInsecure())
 	if err != nil {
 		return
 	}
-	resp = res.Body()
-	resHeader = res.Header()
+	resp = res.Body
+	resHeader = res.Header
 	return
 }
@@ -308,6 +308,65 @@ func TestJson(t *testing.T) {
 	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
 }
 
+func TestJsonMergePatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/test", r.URL.Path)
+		assert.Equal(t, "application/merge-patch+json", r.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.JSONEq(t, `{"name":"test","description":null}`, string(body))
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	body := map[string]interface{}{
+		"name":        "test",
+		"description": nil,
+	}
+	resp, err := JsonMergePatch(ts.URL+"/test", body, map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+}
+
+func TestJsonPatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/test", r.URL.Path)
+		assert.Equal(t, "application/json-patch+json", r.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, `[{"op":"replace","path":"/enabled","value":false},{"op":"remove","path":"/description"},{"op":"move","path":"/b","from":"/a"}]`, string(body))
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	operations := []Operation{
+		{Op: "replace", Path: "/enabled", Value: false},
+		{Op: "remove", Path: "/description"},
+		{Op: "move", Path: "/b", From: "/a"},
+	}
+	resp, err := JsonPatch(ts.URL+"/test", operations, map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+}
+
 func TestForm(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
@@ -0,0 +1,171 @@
+package resty
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PartialDownloadSuffix DownloadResumable 保存未完成下载内容的文件后缀
+const PartialDownloadSuffix = ".part"
+
+// DownloadResumable 下载文件到本地，中断后再次调用时从已下载的位置继续
+//
+// 下载内容先追加写入 destPath+PartialDownloadSuffix，下载完成并校验大小后再重命名为 destPath，
+// 因此 destPath 不会出现写了一半的文件。部分文件存在时发送 "Range: bytes=<已下载大小>-"：
+//   - 服务端返回 206 时校验 Content-Range 的起始位置并追加写入；
+//   - 服务端忽略 Range 返回 200 时清空部分文件重新下载；
+//   - 服务端返回 416 且 Content-Range 的总大小与部分文件一致时视为已下载完成，否则清空后重新下载。
+//
+// 下载中断时保留部分文件并返回错误。下载完成后文件大小必须与 Content-Range（或 200 响应的 Content-Length）
+// 声明的总大小一致，否则返回 ErrContentLengthMismatch 并保留部分文件。
+// 续传不校验远程文件是否已经改变，远程文件可能更新时请在下载完成后自行校验内容。
+// 下载不设置超时，可以通过 WithIdleReadTimeout 在服务端停止发送数据时提前中止。
+//
+// 参数:
+//   - url: 目标文件地址
+//   - destPath: 本地保存路径
+//   - header: 自定义的 HTTP 请求头
+//   - opts: 下载选项，目前只有 WithIdleReadTimeout 和 WithOverwrite 生效
+//
+// 返回值:
+//   - error: 请求错误、非 2xx 状态码、写入错误、*StalledError、ErrContentLengthMismatch 或目标文件已存在的错误，如果成功则为 nil
+//
+// 示例:
+//
+//	for attempt := 0; attempt < 5; attempt++ {
+//	    if err = DownloadResumable("https://example.com/dataset.tar", "/data/dataset.tar", nil); err == nil {
+//	        break
+//	    }
+//	}
+func DownloadResumable(url, destPath string, header map[string]string, opts ...RequestOption) error {
+	cfg := newRequestConfig(opts...)
+	if cfg.existingPolicy(replaceExisting) == rejectExisting {
+		if _, err := os.Lstat(destPath); err == nil {
+			return &os.PathError{Op: "download", Path: destPath, Err: os.ErrExist}
+		}
+	}
+
+	partPath := destPath + PartialDownloadSuffix
+	// 416 时部分文件与远程文件不一致，清空后最多重新下载一次
+	for attempt := 0; attempt < 2; attempt++ {
+		complete, err := resumeDownload(url, partPath, header, cfg)
+		if err != nil {
+			return err
+		}
+		if complete {
+			return os.Rename(partPath, destPath)
+		}
+		if err = os.Truncate(partPath, 0); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("unexpected status code: %d", http.StatusRequestedRangeNotSatisfiable)
+}
+
+// resumeDownload 从部分文件的当前大小继续下载，返回部分文件是否已经完整
+//
+// 服务端返回 416 且部分文件与远程文件不一致时返回 false，由调用方清空部分文件后重试。
+func resumeDownload(url, partPath string, header map[string]string, cfg *requestConfig) (bool, error) {
+	var offset int64
+	if stat, err := os.Stat(partPath); err == nil {
+		offset = stat.Size()
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	ctx, done, err := track(context.Background())
+	if err != nil {
+		return false, err
+	}
+	defer done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rangeHeader := make(map[string]string, len(header)+1)
+	for k, v := range header {
+		rangeHeader[k] = v
+	}
+	if offset > 0 {
+		rangeHeader["Range"] = fmt.Sprintf("bytes=%d-", offset)
+	}
+	res, err := doStream(ctx, newClient(0), http.MethodGet, url, nil, rangeHeader)
+	if err != nil {
+		return false, err
+	}
+	body := res.Body
+	if cfg.idleReadTimeout > 0 {
+		body = watchIdle(body, cfg.idleReadTimeout, cancel)
+	}
+	defer drainBody(body)
+
+	total := int64(-1)
+	flags := os.O_WRONLY | os.O_CREATE
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		start, ok := parseContentRangeStart(res.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return false, fmt.Errorf("unexpected content range %q for offset %d", res.Header.Get("Content-Range"), offset)
+		}
+		if total, ok = parseContentRangeTotal(res.Header.Get("Content-Range")); !ok {
+			total = -1
+		}
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// 服务端不支持或忽略了 Range，从头开始下载
+		total = res.ContentLength
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		size, ok := parseContentRangeTotal(res.Header.Get("Content-Range"))
+		return offset > 0 && ok && size == offset, nil
+	default:
+		return false, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0o644)
+	if err != nil {
+		return false, err
+	}
+	_, err = io.Copy(file, body)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if total >= 0 {
+		stat, err := os.Stat(partPath)
+		if err != nil {
+			return false, err
+		}
+		if stat.Size() != total {
+			return false, fmt.Errorf("%w: expected %d bytes in total, have %d", ErrContentLengthMismatch, total, stat.Size())
+		}
+	}
+	return true, nil
+}
+
+// parseContentRangeStart 解析 Content-Range 头中的起始位置，例如 "bytes 100-199/1234" 返回 100
+func parseContentRangeStart(contentRange string) (int64, bool) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(contentRange), "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(start), 10, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,123 @@
+package resty_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// rangeServer 使用 http.ServeContent 处理范围请求，interrupt 为 true 时第一次请求只发送一半内容后断开
+func rangeServer(t *testing.T, content string, ranges *[]string, interrupt bool) *httptest.Server {
+	var interrupted atomic.Bool
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		if interrupt && interrupted.CompareAndSwap(false, true) {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = io.WriteString(w, content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_ = conn.Close()
+			return
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, strings.NewReader(content))
+	}))
+}
+
+func TestDownloadResumable(t *testing.T) {
+	content := strings.Repeat("0123456789", 1000)
+	var ranges []string
+	ts := rangeServer(t, content, &ranges, true)
+	defer ts.Close()
+	dest := filepath.Join(t.TempDir(), "file.bin")
+
+	// 第一次下载中断，保留部分文件
+	err := DownloadResumable(ts.URL, dest, nil)
+	assert.Error(t, err)
+	_, err = os.Stat(dest)
+	assert.True(t, os.IsNotExist(err))
+	partial, err := os.ReadFile(dest + PartialDownloadSuffix)
+	assert.NoError(t, err)
+	assert.Equal(t, content[:len(partial)], string(partial))
+
+	// 从已下载的位置继续
+	assert.NoError(t, DownloadResumable(ts.URL, dest, nil))
+	data, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	_, err = os.Stat(dest + PartialDownloadSuffix)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, []string{"", "bytes=" + strconv.Itoa(len(partial)) + "-"}, ranges)
+}
+
+func TestDownloadResumableExistingPart(t *testing.T) {
+	content := strings.Repeat("abcdefghij", 100)
+	var ranges []string
+	ts := rangeServer(t, content, &ranges, false)
+	defer ts.Close()
+	dir := t.TempDir()
+
+	// 部分文件已经完整，服务端返回 416
+	dest := filepath.Join(dir, "complete.bin")
+	assert.NoError(t, os.WriteFile(dest+PartialDownloadSuffix, []byte(content), 0o644))
+	assert.NoError(t, DownloadResumable(ts.URL, dest, nil))
+	data, _ := os.ReadFile(dest)
+	assert.Equal(t, content, string(data))
+
+	// 部分文件比远程文件大，清空后重新下载
+	ranges = nil
+	dest = filepath.Join(dir, "stale.bin")
+	assert.NoError(t, os.WriteFile(dest+PartialDownloadSuffix, []byte(content+"stale"), 0o644))
+	assert.NoError(t, DownloadResumable(ts.URL, dest, nil))
+	data, _ = os.ReadFile(dest)
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, []string{"bytes=1005-", ""}, ranges)
+}
+
+func TestDownloadResumableIgnoredRange(t *testing.T) {
+	content := "full content"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bytes=4-", r.Header.Get("Range"))
+		_, _ = io.WriteString(w, content)
+	}))
+	defer ts.Close()
+	dest := filepath.Join(t.TempDir(), "file.txt")
+
+	// 服务端忽略 Range 返回 200 时从头下载
+	assert.NoError(t, os.WriteFile(dest+PartialDownloadSuffix, []byte("junk"), 0o644))
+	assert.NoError(t, DownloadResumable(ts.URL, dest, nil))
+	data, _ := os.ReadFile(dest)
+	assert.Equal(t, content, string(data))
+}
+
+func TestDownloadResumableSizeMismatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 声明的总大小与实际发送的内容不一致
+		w.Header().Set("Content-Range", "bytes 3-5/10")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = io.WriteString(w, "def")
+	}))
+	defer ts.Close()
+	dest := filepath.Join(t.TempDir(), "file.txt")
+
+	assert.NoError(t, os.WriteFile(dest+PartialDownloadSuffix, []byte("abc"), 0o644))
+	err := DownloadResumable(ts.URL, dest, nil)
+	assert.ErrorIs(t, err, ErrContentLengthMismatch)
+	_, err = os.Stat(dest)
+	assert.True(t, os.IsNotExist(err))
+	data, _ := os.ReadFile(dest + PartialDownloadSuffix)
+	assert.Equal(t, "abcdef", string(data))
+}
@@ -0,0 +1,444 @@
+package resty
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// 重试相关的默认值
+const (
+	// DefaultRetryWaitTime 首次重试前的默认等待时间
+	DefaultRetryWaitTime = 100 * time.Millisecond
+	// DefaultRetryMaxWaitTime 单次重试等待时间的默认上限
+	DefaultRetryMaxWaitTime = 2 * time.Second
+)
+
+// RetryConfig 请求重试配置
+type RetryConfig struct {
+	// MaxRetries 最大重试次数，不包含首次请求
+	MaxRetries int
+	// WaitTime 首次重试前的等待时间，之后每次翻倍，为 0 时使用 DefaultRetryWaitTime
+	WaitTime time.Duration
+	// MaxWaitTime 单次等待时间上限，为 0 时使用 DefaultRetryMaxWaitTime
+	MaxWaitTime time.Duration
+	// Condition 判断本次结果是否需要重试，为 nil 时使用 DefaultRetryCondition
+	Condition func(*resty.Response, error) bool
+	// PerAttemptTimeout 单次请求的超时时间，为 0 时使用请求的超时时间（WithTimeout）
+	PerAttemptTimeout time.Duration
+	// OverallTimeout 包含所有重试和等待在内的总超时时间，为 0 时不限制
+	//
+	// 每次请求实际可用的时间为 PerAttemptTimeout 与剩余总时间中的较小值。
+	OverallTimeout time.Duration
+	// OnRetry 每次重试等待之前调用，attempt 从 1 开始，resp 和 err 为上一次请求的结果，
+	// nextDelay 为即将等待的时间；为 nil 时以 Warn 级别记录日志
+	OnRetry func(attempt int, req RequestInfo, resp *resty.Response, err error, nextDelay time.Duration)
+}
+
+// RequestInfo 重试回调中的请求信息
+type RequestInfo struct {
+	// Method HTTP 方法
+	Method string
+	// URL 请求地址
+	URL string
+}
+
+// 超时预算耗尽时返回的错误，可通过 errors.Is 判断是哪一个预算耗尽
+var (
+	// ErrAttemptTimeout 单次请求超过了 PerAttemptTimeout
+	ErrAttemptTimeout = errors.New("per-attempt timeout exceeded")
+	// ErrOverallTimeout 所有重试的总耗时超过了 OverallTimeout
+	ErrOverallTimeout = errors.New("overall retry timeout exceeded")
+)
+
+// WithRetry 为请求启用重试
+//
+// 请求体为不可重放的 io.Reader（未实现 io.Seeker）时不会重试。
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodGet, "https://api.example.com",
+//	    WithRetry(RetryConfig{MaxRetries: 3}),
+//	)
+func WithRetry(retry RetryConfig) RequestOption {
+	return func(c *requestConfig) {
+		c.retry = &retry
+	}
+}
+
+// GetWithAttemptTimeout 发送 GET 请求，每次尝试单独限时，失败时重试
+//
+// attemptTimeout 只限制单次请求的耗时，所有尝试的总耗时由 ctx 控制，
+// 单次超时以及 DefaultRetryCondition 认为可重试的错误和状态码都会触发重试。
+//
+// 参数:
+//   - ctx: 请求上下文，其截止时间为所有尝试的总时间预算
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//   - attemptTimeout: 单次请求的超时时间
+//   - retries: 最大重试次数，不包含首次请求
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - error: 请求过程中的错误信息，单次超时且重试耗尽时可通过 errors.Is(err, ErrAttemptTimeout) 判断
+//
+// 示例:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	resp, err := GetWithAttemptTimeout(ctx, "https://api.example.com", nil, 2*time.Second, 3)
+func GetWithAttemptTimeout(ctx context.Context, url string, header map[string]string, attemptTimeout time.Duration, retries int) ([]byte, error) {
+	res, err := Do(ctx, http.MethodGet, url,
+		WithHeaders(header),
+		WithRetry(RetryConfig{MaxRetries: retries, PerAttemptTimeout: attemptTimeout}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// PostWithRetryFunc 发送 POST 请求，每次请求后由 shouldRetry 决定是否重试
+//
+// 适用于通过响应体（如 {"retryable": true}）而不是状态码表示可重试的接口。
+// shouldRetry 收到的响应已经读取完毕，可以通过 res.Body() 解析响应体；
+// 请求出错时 res 可能为 nil。重试耗尽后返回最后一次请求的响应体。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - body: 请求体内容，可以是任意类型
+//   - header: 自定义的 HTTP 请求头
+//   - shouldRetry: 判断本次结果是否需要重试，为 nil 时使用 DefaultRetryCondition
+//   - maxRetries: 最大重试次数，不包含首次请求
+//
+// 返回值:
+//   - []byte: 最后一次请求的响应体
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	resp, err := PostWithRetryFunc("https://api.example.com/jobs", job, nil, func(res *resty.Response, err error) bool {
+//	    if err != nil {
+//	        return IsRetryableNetworkError(err)
+//	    }
+//	    var result struct {
+//	        Retryable bool `json:"retryable"`
+//	    }
+//	    return json.Unmarshal(res.Body(), &result) == nil && result.Retryable
+//	}, 3)
+func PostWithRetryFunc(url string, body interface{}, header map[string]string, shouldRetry func(*resty.Response, error) bool, maxRetries int) ([]byte, error) {
+	return doBody(http.MethodPost, url,
+		WithHeaders(header),
+		WithBody(body),
+		WithRetry(RetryConfig{MaxRetries: maxRetries, Condition: shouldRetry}),
+	)
+}
+
+// GetWithBackoff 发送 GET 请求，失败时按指数退避重试，直到成功或总耗时超过 maxElapsed
+//
+// 与按次数重试不同，重试次数不设上限，只限制总耗时，适用于需要在限定时间窗口内持续尝试的批处理任务。
+// 等待时间从 DefaultRetryWaitTime 开始每次翻倍，不超过 DefaultRetryMaxWaitTime；
+// 剩余时间不足以完成下一次等待时不再重试，进行中的请求在 maxElapsed 到期时中止。
+// DefaultRetryCondition 认为可重试的错误和状态码会触发重试，其他错误直接返回，
+// 不可重试的状态码（如 4xx）与 Get 一致，返回响应体。
+//
+// 参数:
+//   - ctx: 请求上下文，取消时立即停止重试，包括等待期间
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//   - maxElapsed: 包含所有请求和等待在内的总时间，小于等于 0 时只请求一次
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - error: 超时时可通过 errors.Is(err, ErrOverallTimeout) 判断，并包装最后一次请求的错误；
+//     最后一次是可重试的状态码时为 "unexpected status code" 错误；ctx 结束时为 ctx 的错误
+//
+// 示例:
+//
+//	resp, err := GetWithBackoff(ctx, "https://api.example.com/report", nil, 5*time.Minute)
+//	if errors.Is(err, ErrOverallTimeout) {
+//	    // 5 分钟内都没有成功
+//	}
+func GetWithBackoff(ctx context.Context, url string, header map[string]string, maxElapsed time.Duration) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	backoffCtx, cancel := ctx, context.CancelFunc(func() {})
+	if maxElapsed > 0 {
+		backoffCtx, cancel = context.WithTimeout(ctx, maxElapsed)
+	}
+	defer cancel()
+
+	retry := &RetryConfig{}
+	for attempt := 0; ; attempt++ {
+		res, err := Do(backoffCtx, http.MethodGet, url, WithHeaders(header))
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var raw *resty.Response
+		if res != nil {
+			raw = res.RawResponse
+		}
+		retryable := DefaultRetryCondition(raw, err)
+		if err == nil && !retryable {
+			return res.Body, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("unexpected status code: %d", res.StatusCode)
+		}
+
+		if maxElapsed <= 0 {
+			return nil, err
+		}
+		if backoffCtx.Err() != nil {
+			return nil, fmt.Errorf("%w after %s: %w", ErrOverallTimeout, maxElapsed, err)
+		}
+		if !retryable {
+			return nil, err
+		}
+
+		// 剩余时间不足以等待到下一次请求时直接返回，避免无意义的等待
+		delay := retry.backoff(attempt)
+		if deadline, _ := backoffCtx.Deadline(); time.Until(deadline) < delay {
+			return nil, fmt.Errorf("%w after %s: %w", ErrOverallTimeout, maxElapsed, err)
+		}
+		retry.notifyRetry(attempt+1, RequestInfo{Method: http.MethodGet, URL: url}, raw, err, delay)
+		if sleepErr := sleepContext(backoffCtx, delay); sleepErr != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("%w after %s: %w", ErrOverallTimeout, maxElapsed, err)
+		}
+	}
+}
+
+// DefaultRetryCondition 默认的重试条件
+//
+// 可重试的网络错误（见 IsRetryableNetworkError）、单次请求超时（ErrAttemptTimeout）、
+// 429 和 5xx 状态码会触发重试。
+// 重试会重复发送请求，对非幂等的请求启用重试时需要调用方自行确认安全性。
+func DefaultRetryCondition(res *resty.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, ErrAttemptTimeout) || IsRetryableNetworkError(err)
+	}
+	if res == nil {
+		return false
+	}
+	status := res.StatusCode()
+	return status == http.StatusTooManyRequests || StatusClass(status) == ClassServerError
+}
+
+// IsRetryableNetworkError 判断错误是否为可安全重试的传输层错误
+//
+// 会逐层解开包装后判断：连接被重置（ECONNRESET）、管道断开（EPIPE）、
+// 连接提前关闭（io.EOF、io.ErrUnexpectedEOF、net.ErrClosed）以及 HTTP/2 流被拒绝
+// 等错误可以重试；TLS 证书校验失败等错误重试也不会成功，始终返回 false。
+//
+// 参数:
+//   - err: 请求返回的错误
+//
+// 返回值:
+//   - bool: 可以重试时返回 true
+func IsRetryableNetworkError(err error) bool {
+	if err == nil || isTLSError(err) {
+		return false
+	}
+
+	switch {
+	case errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, net.ErrClosed):
+		return true
+	}
+
+	// net/http 内置的 HTTP/2 实现未导出错误类型，只能通过错误信息识别
+	msg := err.Error()
+	if strings.Contains(msg, "http2: server sent GOAWAY") {
+		return true
+	}
+	if strings.Contains(msg, "stream error:") {
+		return strings.Contains(msg, "REFUSED_STREAM") || strings.Contains(msg, "INTERNAL_ERROR")
+	}
+	return false
+}
+
+// isTLSError 判断错误是否为 TLS 握手或证书校验错误
+func isTLSError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	return errors.As(err, &verifyErr) ||
+		errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr)
+}
+
+// replayable 判断请求体能否在重试时重新发送
+func (c *requestConfig) replayable() bool {
+	if len(c.files) > 0 {
+		return false
+	}
+	if reader, ok := c.body.(io.Reader); ok {
+		_, seekable := reader.(io.Seeker)
+		return seekable
+	}
+	return true
+}
+
+// rewind 在重试前将可 Seek 的请求体重置到开头
+func (c *requestConfig) rewind() error {
+	if seeker, ok := c.body.(io.Seeker); ok {
+		_, err := seeker.Seek(0, io.SeekStart)
+		return err
+	}
+	return nil
+}
+
+// backoff 计算第 attempt 次重试前的等待时间
+func (r *RetryConfig) backoff(attempt int) time.Duration {
+	wait := r.WaitTime
+	if wait <= 0 {
+		wait = DefaultRetryWaitTime
+	}
+	maxWait := r.MaxWaitTime
+	if maxWait <= 0 {
+		maxWait = DefaultRetryMaxWaitTime
+	}
+	for i := 0; i < attempt && wait < maxWait; i++ {
+		wait *= 2
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	return wait
+}
+
+// clientTimeout 计算客户端的超时时间，设置了 PerAttemptTimeout 时由 withAttemptTimeout 的上下文控制
+func (r *RetryConfig) clientTimeout(timeout time.Duration) time.Duration {
+	if r != nil && r.PerAttemptTimeout > 0 {
+		return 0
+	}
+	return timeout
+}
+
+// withOverallTimeout 为所有重试创建共享总超时时间的上下文
+func (r *RetryConfig) withOverallTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r == nil || r.OverallTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return withBudget(ctx, r.OverallTimeout)
+}
+
+// withAttemptTimeout 为单次请求创建带超时时间的上下文，实际可用时间为 PerAttemptTimeout 与剩余总时间中的较小值
+func (r *RetryConfig) withAttemptTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r == nil || r.PerAttemptTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return withBudget(ctx, r.PerAttemptTimeout)
+}
+
+// budgetError 在超时预算耗尽时为错误标明是哪一个预算耗尽
+//
+// parent 为调用方传入的上下文，由它自身取消或超时导致的错误原样返回；
+// 总预算和单次预算同时耗尽时以总预算为准。
+func (r *RetryConfig) budgetError(parent, retryCtx, attemptCtx context.Context, err error) error {
+	if r == nil || err == nil || parent.Err() != nil {
+		return err
+	}
+	if budgetExpired(retryCtx) {
+		return fmt.Errorf("%w after %s: %w", ErrOverallTimeout, r.OverallTimeout, err)
+	}
+	if budgetExpired(attemptCtx) {
+		return fmt.Errorf("%w after %s: %w", ErrAttemptTimeout, r.PerAttemptTimeout, err)
+	}
+	return err
+}
+
+// afterFuncHook 替换重试使用的定时器，为 nil 时使用 time.AfterFunc，测试中用于注入可控的时钟
+var afterFuncHook atomic.Pointer[func(d time.Duration, f func()) (stop func() bool)]
+
+// afterFunc 在 d 之后调用 f，返回停止定时器的函数，重试的等待和超时预算都通过它计时
+func afterFunc(d time.Duration, f func()) (stop func() bool) {
+	if hook := afterFuncHook.Load(); hook != nil {
+		return (*hook)(d, f)
+	}
+	return time.AfterFunc(d, f).Stop
+}
+
+// withBudget 返回在 d 之后以 context.DeadlineExceeded 为原因取消的上下文
+func withBudget(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := afterFunc(d, func() { cancel(context.DeadlineExceeded) })
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+// budgetExpired 判断 withBudget 创建的上下文是否因超时而取消
+func budgetExpired(ctx context.Context) bool {
+	return ctx.Err() != nil && errors.Is(context.Cause(ctx), context.DeadlineExceeded)
+}
+
+// notifyRetry 在重试等待之前调用 OnRetry，未设置时记录 Warn 日志
+func (r *RetryConfig) notifyRetry(attempt int, req RequestInfo, res *resty.Response, err error, delay time.Duration) {
+	if r.OnRetry != nil {
+		r.OnRetry(attempt, req, res, err, delay)
+		return
+	}
+
+	fields := []zap.Field{
+		zap.Int("attempt", attempt),
+		zap.String("method", req.Method),
+		zap.String("url", req.URL),
+		zap.Duration("delay", delay),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	} else if res != nil {
+		fields = append(fields, zap.Int("status", res.StatusCode()))
+	}
+	zap.L().Warn("HTTP Request Retry", fields...)
+}
+
+// shouldRetry 判断本次结果是否需要重试
+func (r *RetryConfig) shouldRetry(res *resty.Response, err error) bool {
+	if r.Condition != nil {
+		return r.Condition(res, err)
+	}
+	return DefaultRetryCondition(res, err)
+}
+
+// sleepContext 等待指定时间，上下文结束时提前返回取消的原因
+func sleepContext(ctx context.Context, d time.Duration) error {
+	fired := make(chan struct{})
+	stop := afterFunc(d, func() { close(fired) })
+	defer stop()
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-fired:
+		return nil
+	}
+}
@@ -0,0 +1,662 @@
+package resty_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+	"go.uber.org/zap/zapcore"
+)
+
+// misbehavingServer 启动一个本地监听器，读取完请求后交由 handle 处理连接
+func misbehavingServer(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = http.ReadRequest(bufio.NewReader(conn))
+				handle(conn)
+			}()
+		}
+	}()
+	return "http://" + ln.Addr().String()
+}
+
+func TestIsRetryableNetworkErrorListeners(t *testing.T) {
+	tests := []struct {
+		name      string
+		handle    func(conn net.Conn)
+		retryable bool
+	}{
+		{
+			name:      "connection closed without response",
+			handle:    func(conn net.Conn) {},
+			retryable: true,
+		},
+		{
+			name: "connection reset by peer",
+			handle: func(conn net.Conn) {
+				// SO_LINGER 为 0 时关闭连接会发送 RST
+				_ = conn.(*net.TCPConn).SetLinger(0)
+			},
+			retryable: true,
+		},
+		{
+			name: "truncated response body",
+			handle: func(conn net.Conn) {
+				_, _ = io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Length: 100\r\n\r\n{\"status\":")
+			},
+			retryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverURL := misbehavingServer(t, tt.handle)
+			_, err := Do(context.Background(), http.MethodGet, serverURL)
+			assert.Error(t, err)
+			assert.Equal(t, tt.retryable, IsRetryableNetworkError(err), "unexpected classification for %v", err)
+		})
+	}
+}
+
+func TestIsRetryableNetworkErrorTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	// 自签名证书校验失败，不应重试
+	_, err := Do(context.Background(), http.MethodGet, ts.URL)
+	assert.Error(t, err)
+	assert.False(t, IsRetryableNetworkError(err))
+}
+
+func TestIsRetryableNetworkError(t *testing.T) {
+	wrap := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://example.com", Err: fmt.Errorf("read: %w", err)}
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "nil", err: nil, retryable: false},
+		{name: "econnreset", err: wrap(&net.OpError{Op: "read", Err: syscall.ECONNRESET}), retryable: true},
+		{name: "broken pipe", err: wrap(&net.OpError{Op: "write", Err: syscall.EPIPE}), retryable: true},
+		{name: "unexpected eof", err: wrap(io.ErrUnexpectedEOF), retryable: true},
+		{name: "net closed", err: wrap(net.ErrClosed), retryable: true},
+		{name: "http2 refused stream", err: wrap(errors.New("stream error: stream ID 3; REFUSED_STREAM")), retryable: true},
+		{name: "http2 protocol error", err: wrap(errors.New("stream error: stream ID 3; PROTOCOL_ERROR")), retryable: false},
+		{name: "canceled", err: wrap(context.Canceled), retryable: false},
+		{name: "plain error", err: errors.New("boom"), retryable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, IsRetryableNetworkError(tt.err))
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&count, 1) < 3 {
+			// 前两次直接断开连接
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL,
+		WithRetry(RetryConfig{MaxRetries: 3, WaitTime: time.Millisecond}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp.Body)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&count))
+}
+
+func TestWithRetryExhausted(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL,
+		WithRetry(RetryConfig{MaxRetries: 2, WaitTime: time.Millisecond}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&count))
+}
+
+// fakeClock 可控的时钟，定时器只在 fireNext 时触发，用于替换重试的等待和超时预算使用的定时器
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Duration
+	timers []*fakeTimer
+}
+
+// fakeTimer fakeClock 中未触发的定时器
+type fakeTimer struct {
+	at time.Duration
+	f  func()
+}
+
+// newFakeClock 创建时钟并替换重试使用的定时器，测试结束时恢复
+func newFakeClock(t *testing.T) *fakeClock {
+	c := &fakeClock{}
+	t.Cleanup(SetRetryAfterFunc(c.afterFunc))
+	return c
+}
+
+// afterFunc 注册在当前时间 d 之后触发的定时器
+func (c *fakeClock) afterFunc(d time.Duration, f func()) func() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{at: c.now + d, f: f}
+	c.timers = append(c.timers, timer)
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		i := slices.Index(c.timers, timer)
+		if i < 0 {
+			return false
+		}
+		c.timers = slices.Delete(c.timers, i, i+1)
+		return true
+	}
+}
+
+// pending 返回未触发的定时器数量
+func (c *fakeClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// elapsed 返回时钟已推进的时间
+func (c *fakeClock) elapsed() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// fireNext 将时间推进到最早的定时器并触发它
+func (c *fakeClock) fireNext() {
+	c.mu.Lock()
+	next := 0
+	for i, timer := range c.timers {
+		if timer.at < c.timers[next].at {
+			next = i
+		}
+	}
+	timer := c.timers[next]
+	c.timers = slices.Delete(c.timers, next, next+1)
+	c.now = max(c.now, timer.at)
+	c.mu.Unlock()
+	timer.f()
+}
+
+// waitPending 等待未触发的定时器数量达到 n
+func (c *fakeClock) waitPending(t *testing.T, n int) {
+	t.Helper()
+	assert.Eventually(t, func() bool { return c.pending() == n }, 5*time.Second, time.Millisecond)
+}
+
+// run 在后台执行 fn，每当有 n 个定时器等待时触发最早的一个，直到 fn 返回
+//
+// 请求阻塞时只剩超时和等待的定时器，因此时钟只会在重试循环等待时推进。
+func (c *fakeClock) run(t *testing.T, n int, fn func() error) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-deadline:
+			t.Fatal("request did not finish")
+		default:
+		}
+		if c.pending() == n {
+			c.fireNext()
+		} else {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestWithRetryAttemptTimeout(t *testing.T) {
+	clock := newFakeClock(t)
+	arrived := make(chan struct{}, 1)
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&count, 1) == 1 {
+			// 首次请求阻塞到单次超时，重试后成功
+			arrived <- struct{}{}
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	done := make(chan error, 1)
+	var resp *Response
+	go func() {
+		var err error
+		resp, err = Do(context.Background(), http.MethodGet, ts.URL,
+			WithRetry(RetryConfig{MaxRetries: 1, WaitTime: time.Millisecond, PerAttemptTimeout: 50 * time.Millisecond}),
+		)
+		done <- err
+	}()
+	<-arrived
+	clock.waitPending(t, 1)
+	clock.fireNext() // 单次超时
+	clock.waitPending(t, 1)
+	clock.fireNext() // 重试等待
+	assert.NoError(t, <-done)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&count))
+	assert.Equal(t, 51*time.Millisecond, clock.elapsed())
+
+	// 重试次数耗尽后返回单次超时错误
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+
+	var attempts int
+	err := clock.run(t, 1, func() error {
+		_, err := Do(context.Background(), http.MethodGet, slow.URL,
+			WithRetry(RetryConfig{
+				MaxRetries:        1,
+				WaitTime:          time.Millisecond,
+				PerAttemptTimeout: 50 * time.Millisecond,
+				OnRetry:           func(int, RequestInfo, *resty.Response, error, time.Duration) { attempts++ },
+			}),
+		)
+		return err
+	})
+	assert.ErrorIs(t, err, ErrAttemptTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrOverallTimeout)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestWithRetryOverallTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	// 单次超时 80ms、首次等待 10ms 并逐次翻倍、总超时 200ms：
+	// 第 1 次请求 0-80ms，等待到 90ms，第 2 次请求 90-170ms，等待到 190ms，第 3 次请求在 200ms 被总超时中止
+	tests := []struct {
+		retries  int
+		attempts int
+		elapsed  time.Duration
+		budget   error
+	}{
+		// 两次请求在总超时前结束，返回的是单次超时错误
+		{retries: 1, attempts: 2, elapsed: 170 * time.Millisecond, budget: ErrAttemptTimeout},
+		{retries: 5, attempts: 3, elapsed: 200 * time.Millisecond, budget: ErrOverallTimeout},
+		{retries: 100, attempts: 3, elapsed: 200 * time.Millisecond, budget: ErrOverallTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("retries=%d", tt.retries), func(t *testing.T) {
+			clock := newFakeClock(t)
+			attempts := 1
+			// 总超时和单次超时（或重试等待）的定时器同时存在时，重试循环处于等待中
+			err := clock.run(t, 2, func() error {
+				_, err := Do(context.Background(), http.MethodGet, ts.URL,
+					WithRetry(RetryConfig{
+						MaxRetries:        tt.retries,
+						WaitTime:          10 * time.Millisecond,
+						PerAttemptTimeout: 80 * time.Millisecond,
+						OverallTimeout:    200 * time.Millisecond,
+						OnRetry:           func(int, RequestInfo, *resty.Response, error, time.Duration) { attempts++ },
+					}),
+				)
+				return err
+			})
+
+			// 无论重试多少次，总耗时都不超过 OverallTimeout
+			assert.LessOrEqual(t, clock.elapsed(), 200*time.Millisecond)
+			assert.Equal(t, tt.elapsed, clock.elapsed())
+			assert.Equal(t, tt.attempts, attempts)
+			assert.ErrorIs(t, err, tt.budget)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			if tt.budget == ErrOverallTimeout {
+				assert.NotErrorIs(t, err, ErrAttemptTimeout)
+			}
+		})
+	}
+}
+
+func TestWithRetryOverallTimeoutCallerContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	// 调用方上下文先到期时，错误原样返回
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := Do(ctx, http.MethodGet, ts.URL,
+		WithRetry(RetryConfig{MaxRetries: 3, OverallTimeout: time.Second}),
+	)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrOverallTimeout)
+	assert.NotErrorIs(t, err, ErrAttemptTimeout)
+}
+
+func TestGetWithAttemptTimeout(t *testing.T) {
+	clock := newFakeClock(t)
+	arrived := make(chan struct{}, 1)
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		if atomic.AddInt64(&count, 1) == 1 {
+			// 首次请求超时，重试成功
+			arrived <- struct{}{}
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	type result struct {
+		body []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		body, err := GetWithAttemptTimeout(context.Background(), ts.URL, map[string]string{"Authorization": "test-token"}, 100*time.Millisecond, 2)
+		done <- result{body, err}
+	}()
+	<-arrived
+	clock.waitPending(t, 1)
+	clock.fireNext() // 单次超时
+	clock.waitPending(t, 1)
+	clock.fireNext() // 重试等待
+	res := <-done
+	assert.NoError(t, res.err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), res.body)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&count))
+}
+
+func TestGetWithBackoff(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		// 前两次返回 503，之后成功
+		if atomic.AddInt64(&count, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	start := time.Now()
+	resp, err := GetWithBackoff(context.Background(), ts.URL, map[string]string{"Authorization": "test-token"}, 5*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&count))
+	// 等待时间按指数增长: 100ms + 200ms
+	assert.GreaterOrEqual(t, time.Since(start), DefaultRetryWaitTime*3)
+}
+
+func TestGetWithBackoffMaxElapsed(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	// 剩余时间不足以完成下一次等待时返回最后一次的错误: 0ms、100ms、300ms 请求，下一次需等到 700ms
+	start := time.Now()
+	resp, err := GetWithBackoff(context.Background(), ts.URL, nil, 500*time.Millisecond)
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, ErrOverallTimeout)
+	assert.ErrorContains(t, err, "unexpected status code: 502")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&count))
+
+	// 不大于 0 时只请求一次
+	atomic.StoreInt64(&count, 0)
+	_, err = GetWithBackoff(context.Background(), ts.URL, nil, 0)
+	assert.ErrorContains(t, err, "unexpected status code: 502")
+	assert.NotErrorIs(t, err, ErrOverallTimeout)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&count))
+}
+
+func TestGetWithBackoffSlowAttempt(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	// 进行中的请求在 maxElapsed 到期时中止
+	start := time.Now()
+	_, err := GetWithBackoff(context.Background(), ts.URL, nil, 100*time.Millisecond)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.ErrorIs(t, err, ErrOverallTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestGetWithBackoffStops(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&count, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":"not found"}`)
+	}))
+	defer ts.Close()
+
+	// 不可重试的状态码直接返回响应体
+	resp, err := GetWithBackoff(context.Background(), ts.URL, nil, 5*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"error":"not found"}`), resp)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&count))
+
+	// 等待期间 ctx 取消时立即返回
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = GetWithBackoff(ctx, unavailable.URL, nil, time.Minute)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrOverallTimeout)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestPostWithRetryFunc(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"job":"export"}`, string(body))
+		// 前两次以 200 返回可重试的错误
+		if attempts.Add(1) < 3 {
+			_, _ = io.WriteString(w, `{"retryable":true,"error":"busy"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"retryable":false,"result":"done"}`)
+	}))
+	defer ts.Close()
+
+	retryable := func(res *resty.Response, err error) bool {
+		if err != nil {
+			return IsRetryableNetworkError(err)
+		}
+		var result struct {
+			Retryable bool `json:"retryable"`
+		}
+		return json.Unmarshal(res.Body(), &result) == nil && result.Retryable
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	resp, err := PostWithRetryFunc(ts.URL, map[string]string{"job": "export"}, headers, retryable, 3)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"retryable":false,"result":"done"}`, string(resp))
+	assert.Equal(t, int32(3), attempts.Load())
+
+	// 重试耗尽后返回最后一次的响应体
+	attempts.Store(0)
+	resp, err = PostWithRetryFunc(ts.URL, map[string]string{"job": "export"}, headers, retryable, 1)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"retryable":true,"error":"busy"}`, string(resp))
+	assert.Equal(t, int32(2), attempts.Load())
+
+	// 为 nil 时使用默认条件，200 不重试
+	attempts.Store(0)
+	_, err = PostWithRetryFunc(ts.URL, map[string]string{"job": "export"}, headers, nil, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestWithRetryOnRetry(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&count, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	type retryCall struct {
+		attempt int
+		req     RequestInfo
+		status  int
+		delay   time.Duration
+		at      time.Time
+	}
+	var calls []retryCall
+	start := time.Now()
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL,
+		WithRetry(RetryConfig{
+			MaxRetries: 3,
+			WaitTime:   20 * time.Millisecond,
+			OnRetry: func(attempt int, req RequestInfo, resp *resty.Response, err error, nextDelay time.Duration) {
+				assert.NoError(t, err)
+				calls = append(calls, retryCall{attempt, req, resp.StatusCode(), nextDelay, time.Now()})
+			},
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	if assert.Len(t, calls, 2) {
+		assert.Equal(t, 1, calls[0].attempt)
+		assert.Equal(t, 2, calls[1].attempt)
+		assert.Equal(t, RequestInfo{Method: http.MethodGet, URL: ts.URL}, calls[0].req)
+		assert.Equal(t, http.StatusServiceUnavailable, calls[0].status)
+		assert.Equal(t, 20*time.Millisecond, calls[0].delay)
+		assert.Equal(t, 40*time.Millisecond, calls[1].delay)
+		// 回调在等待之前触发
+		assert.Less(t, calls[0].at.Sub(start), 20*time.Millisecond)
+	}
+}
+
+func TestWithRetryLogging(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&count, 1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	logs := observeLogs(t)
+	_, err := Do(context.Background(), http.MethodPost, ts.URL,
+		WithRetry(RetryConfig{MaxRetries: 3, WaitTime: time.Millisecond}),
+	)
+	assert.NoError(t, err)
+
+	entries := logs.FilterMessage("HTTP Request Retry").All()
+	if assert.Len(t, entries, 2) {
+		fields := entries[1].ContextMap()
+		assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+		assert.Equal(t, int64(2), fields["attempt"])
+		assert.Equal(t, http.MethodPost, fields["method"])
+		assert.Equal(t, ts.URL, fields["url"])
+		assert.Equal(t, int64(http.StatusBadGateway), fields["status"])
+		assert.Equal(t, 2*time.Millisecond, fields["delay"])
+	}
+
+	// 网络错误时记录错误信息
+	logs = observeLogs(t)
+	addr := misbehavingServer(t, func(conn net.Conn) {})
+	_, err = Do(context.Background(), http.MethodGet, addr,
+		WithRetry(RetryConfig{MaxRetries: 1, WaitTime: time.Millisecond}),
+	)
+	assert.Error(t, err)
+	entries = logs.FilterMessage("HTTP Request Retry").All()
+	if assert.Len(t, entries, 1) {
+		assert.Contains(t, entries[0].ContextMap(), "error")
+		assert.NotContains(t, entries[0].ContextMap(), "status")
+	}
+}
@@ -0,0 +1,121 @@
+package resty
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// logSampling WithBodyLogging 的全局采样配置
+type logSampling struct {
+	rate            float64
+	alwaysLogErrors bool
+	rand            *rand.Rand
+}
+
+// LogSamplingOption SetLogSampling 的配置项
+type LogSamplingOption func(*logSampling)
+
+// AlwaysLogErrors 请求失败或响应状态码为 4xx、5xx 时始终记录日志，不受采样率影响
+func AlwaysLogErrors() LogSamplingOption {
+	return func(s *logSampling) {
+		s.alwaysLogErrors = true
+	}
+}
+
+// LogSamplingSource 使用指定的随机数源决定是否采样，传入固定种子的源（如 rand.NewPCG(1, 2)）时采样结果可复现
+func LogSamplingSource(src rand.Source) LogSamplingOption {
+	return func(s *logSampling) {
+		s.rand = rand.New(src)
+	}
+}
+
+var (
+	logSamplingMutex  sync.Mutex
+	logSamplingConfig = logSampling{rate: 1}
+)
+
+// SetLogSampling 设置 WithBodyLogging 的采样率，适用于高流量服务只记录部分请求的场景
+//
+// rate 为 1 时记录所有请求（默认行为），为 0.01 时大约每 100 个请求记录一个，为 0 时不记录。
+// 每个请求（重试的每次尝试单独计算）在发送前决定是否采样，采样的请求同时记录请求体和响应体。
+// 每次调用都会重置所有配置项，未传入的配置项恢复默认值。
+// 通过 ForceLog 标记的请求始终记录，便于针对性地排查问题。
+//
+// 参数:
+//   - rate: 采样率，取值范围 [0, 1]，超出范围时按边界值处理
+//   - opts: 配置项，如 AlwaysLogErrors、LogSamplingSource
+//
+// 示例:
+//
+//	// 记录 1% 的请求，失败的请求全部记录
+//	SetLogSampling(0.01, AlwaysLogErrors())
+func SetLogSampling(rate float64, opts ...LogSamplingOption) {
+	sampling := logSampling{rate: min(max(rate, 0), 1)}
+	for _, opt := range opts {
+		opt(&sampling)
+	}
+
+	logSamplingMutex.Lock()
+	defer logSamplingMutex.Unlock()
+	logSamplingConfig = sampling
+}
+
+// forceLogKey 请求上下文中强制记录日志标记的键
+type forceLogKey struct{}
+
+// ForceLog 返回标记了强制记录日志的上下文，使用该上下文发送的请求不受 SetLogSampling 的采样率影响
+//
+// 只对配置了 WithBodyLogging 的请求生效。
+//
+// 示例:
+//
+//	if req.Header.Get("X-Debug") == "1" {
+//	    ctx = ForceLog(ctx)
+//	}
+//	resp, err := Do(ctx, http.MethodGet, url, WithBodyLogging(BodyLogConfig{}))
+func ForceLog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceLogKey{}, true)
+}
+
+// sampleLog 决定本次请求是否记录日志
+func sampleLog(ctx context.Context) bool {
+	if forced, _ := ctx.Value(forceLogKey{}).(bool); forced {
+		return true
+	}
+
+	logSamplingMutex.Lock()
+	defer logSamplingMutex.Unlock()
+	switch rate := logSamplingConfig.rate; {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	case logSamplingConfig.rand != nil:
+		return logSamplingConfig.rand.Float64() < rate
+	default:
+		return rand.Float64() < rate
+	}
+}
+
+// shouldLogResult 判断是否记录本次请求的结果：采样的请求始终记录，
+// 未被采样的请求在失败且开启了 AlwaysLogErrors 时记录，并补充记录发送前跳过的请求体
+func (b *BodyLogConfig) shouldLogResult(res *resty.Response, err error, sampled bool) bool {
+	if sampled {
+		return true
+	}
+	logSamplingMutex.Lock()
+	alwaysLogErrors := logSamplingConfig.alwaysLogErrors
+	logSamplingMutex.Unlock()
+
+	failed := err != nil || (res != nil && res.StatusCode() >= 400)
+	if !alwaysLogErrors || !failed {
+		return false
+	}
+	if res != nil && res.Request != nil && res.Request.RawRequest != nil {
+		b.logRequestBody(res.Request.RawRequest)
+	}
+	return true
+}
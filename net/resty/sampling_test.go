@@ -0,0 +1,149 @@
+package resty_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// statusServer 根据查询参数 status 返回对应的状态码
+func statusServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentTypeJson)
+		if r.URL.Query().Get("status") == "500" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+}
+
+func TestSetLogSampling(t *testing.T) {
+	ts := statusServer()
+	defer ts.Close()
+	t.Cleanup(func() { SetLogSampling(1) })
+
+	const requests = 200
+	send := func(ctx context.Context, url string) {
+		_, err := Do(ctx, http.MethodPost, url, WithJSONBody(map[string]int{"n": 1}), WithBodyLogging(BodyLogConfig{}))
+		assert.NoError(t, err)
+	}
+	count := func(rate float64) (int, int) {
+		logs := observeLogs(t)
+		SetLogSampling(rate, LogSamplingSource(rand.NewPCG(1, 2)))
+		for i := 0; i < requests; i++ {
+			send(context.Background(), ts.URL)
+		}
+		return logs.FilterMessage("HTTP Request Body").Len(), logs.FilterMessage("HTTP Response Body").Len()
+	}
+
+	reqLogs, resLogs := count(1)
+	assert.Equal(t, requests, reqLogs)
+	assert.Equal(t, requests, resLogs)
+
+	// 约 10% 的请求被采样，请求体和响应体成对记录
+	reqLogs, resLogs = count(0.1)
+	assert.InDelta(t, requests/10, reqLogs, 12)
+	assert.Equal(t, reqLogs, resLogs)
+
+	// 相同的种子得到相同的采样结果
+	again, _ := count(0.1)
+	assert.Equal(t, reqLogs, again)
+
+	reqLogs, resLogs = count(0)
+	assert.Zero(t, reqLogs)
+	assert.Zero(t, resLogs)
+
+	// ForceLog 不受采样率影响
+	logs := observeLogs(t)
+	send(ForceLog(context.Background()), ts.URL)
+	assert.Equal(t, 1, logs.FilterMessage("HTTP Request Body").Len())
+	assert.Equal(t, 1, logs.FilterMessage("HTTP Response Body").Len())
+}
+
+func TestSetLogSamplingAlwaysLogErrors(t *testing.T) {
+	ts := statusServer()
+	defer ts.Close()
+	t.Cleanup(func() { SetLogSampling(1) })
+
+	logs := observeLogs(t)
+	SetLogSampling(0, AlwaysLogErrors())
+	for i := 0; i < 10; i++ {
+		_, err := Do(context.Background(), http.MethodPost, ts.URL, WithJSONBody(map[string]int{"n": i}), WithBodyLogging(BodyLogConfig{}))
+		assert.NoError(t, err)
+	}
+	for i := 0; i < 5; i++ {
+		_, err := Do(context.Background(), http.MethodPost, ts.URL+"?status=500", WithJSONBody(map[string]int{"n": i}), WithBodyLogging(BodyLogConfig{}))
+		assert.NoError(t, err)
+	}
+	// 成功的请求全部被采样掉，失败的请求全部记录，并补充记录了请求体
+	resLogs := logs.FilterMessage("HTTP Response Body").All()
+	assert.Len(t, resLogs, 5)
+	for _, entry := range resLogs {
+		assert.Equal(t, int64(http.StatusInternalServerError), entry.ContextMap()["status"])
+	}
+	assert.Equal(t, 5, logs.FilterMessage("HTTP Request Body").Len())
+
+	// 连接失败同样记录请求体
+	logs = observeLogs(t)
+	_, err := Do(context.Background(), http.MethodPost, "http://127.0.0.1:0", WithJSONBody(map[string]int{"n": 1}), WithBodyLogging(BodyLogConfig{}))
+	assert.Error(t, err)
+	assert.Equal(t, 1, logs.FilterMessage("HTTP Request Body").Len())
+	assert.Equal(t, 1, logs.FilterMessage("HTTP Request Error").Len())
+
+	// 重新设置时恢复默认配置项
+	logs = observeLogs(t)
+	SetLogSampling(0)
+	_, err = Do(context.Background(), http.MethodPost, ts.URL+"?status=500", WithJSONBody(map[string]int{"n": 1}), WithBodyLogging(BodyLogConfig{}))
+	assert.NoError(t, err)
+	assert.Zero(t, logs.Len())
+}
+
+func TestSetLogSamplingTransportError(t *testing.T) {
+	ts := statusServer()
+	url := ts.URL
+	ts.Close()
+	t.Cleanup(func() { SetLogSampling(1) })
+
+	send := func(ctx context.Context) {
+		_, err := Do(ctx, http.MethodPost, url, WithJSONBody(map[string]int{"n": 1}), WithBodyLogging(BodyLogConfig{}))
+		assert.Error(t, err)
+	}
+	tests := []struct {
+		name   string
+		rate   float64
+		opts   []LogSamplingOption
+		ctx    context.Context
+		logged bool
+	}{
+		{"sampled", 1, nil, context.Background(), true},
+		{"always log errors", 0, []LogSamplingOption{AlwaysLogErrors()}, context.Background(), true},
+		{"force log", 0, nil, ForceLog(context.Background()), true},
+		{"sampled out", 0, nil, context.Background(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := observeLogs(t)
+			SetLogSampling(tt.rate, tt.opts...)
+			send(tt.ctx)
+
+			errLogs := logs.FilterMessage("HTTP Request Error").All()
+			if !tt.logged {
+				assert.Zero(t, logs.Len())
+				return
+			}
+			// 连接失败时记录请求体和错误
+			assert.Equal(t, 1, logs.FilterMessage("HTTP Request Body").Len())
+			if assert.Len(t, errLogs, 1) {
+				fields := errLogs[0].ContextMap()
+				assert.Equal(t, http.MethodPost, fields["method"])
+				assert.Equal(t, url, fields["url"])
+				assert.Contains(t, fields["error"], "connect")
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package resty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// SchemaVersionHeader 响应中标识数据结构版本的响应头
+const SchemaVersionHeader = "X-Schema-Version"
+
+// ErrSchemaVersionMismatch 响应的数据结构版本与期望的版本不一致，具体版本见 *SchemaVersionError
+var ErrSchemaVersionMismatch = errors.New("schema version mismatch")
+
+// SchemaVersionError 数据结构版本不一致时返回的错误，可通过 errors.Is(err, ErrSchemaVersionMismatch) 判断
+type SchemaVersionError struct {
+	// Expected 期望的版本
+	Expected string
+	// Actual 响应中的版本，响应没有 X-Schema-Version 时为空
+	Actual string
+}
+
+// Error 实现 error 接口
+func (e *SchemaVersionError) Error() string {
+	return fmt.Sprintf("%s: expected %q, got %q", ErrSchemaVersionMismatch, e.Expected, e.Actual)
+}
+
+// Unwrap 返回 ErrSchemaVersionMismatch
+func (e *SchemaVersionError) Unwrap() error {
+	return ErrSchemaVersionMismatch
+}
+
+// GetRequireSchemaVersion 发送 HTTP GET 请求，并要求响应头 X-Schema-Version 与 expected 一致
+//
+// 用于在集成测试和服务启动时尽早发现上游不兼容的变更。版本按字符串精确比较，
+// 响应没有 X-Schema-Version 时同样视为不一致。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - expected: 期望的版本，如 "2024-06-01"
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组，版本不一致时为 nil
+//   - error: 版本不一致时为 *SchemaVersionError，否则为请求过程中的错误信息
+//
+// 示例:
+//
+//	resp, err := GetRequireSchemaVersion("https://api.example.com/v2/orders", "2", nil)
+//	var versionErr *SchemaVersionError
+//	if errors.As(err, &versionErr) {
+//	    log.Fatalf("upstream schema changed to %s", versionErr.Actual)
+//	}
+func GetRequireSchemaVersion(url, expected string, header map[string]string) ([]byte, error) {
+	res, err := Do(context.Background(), http.MethodGet, url, WithHeaders(header))
+	if err != nil {
+		return nil, err
+	}
+	if actual := res.Header.Get(SchemaVersionHeader); actual != expected {
+		return nil, &SchemaVersionError{Expected: expected, Actual: actual}
+	}
+	return res.Body, nil
+}
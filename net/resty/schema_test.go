@@ -0,0 +1,41 @@
+package resty_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetRequireSchemaVersion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		if version := r.URL.Query().Get("version"); version != "" {
+			w.Header().Set("X-Schema-Version", version)
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer ts.Close()
+	headers := map[string]string{"Authorization": "test-token"}
+
+	resp, err := GetRequireSchemaVersion(ts.URL+"?version=2", "2", headers)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+
+	resp, err = GetRequireSchemaVersion(ts.URL+"?version=3", "2", headers)
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, ErrSchemaVersionMismatch)
+	assert.EqualError(t, err, `schema version mismatch: expected "2", got "3"`)
+	var versionErr *SchemaVersionError
+	if assert.True(t, errors.As(err, &versionErr)) {
+		assert.Equal(t, "2", versionErr.Expected)
+		assert.Equal(t, "3", versionErr.Actual)
+	}
+
+	// 缺少响应头同样视为不一致
+	_, err = GetRequireSchemaVersion(ts.URL, "2", headers)
+	assert.EqualError(t, err, `schema version mismatch: expected "2", got ""`)
+}
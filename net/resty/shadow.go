@@ -0,0 +1,209 @@
+package resty
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultShadowConcurrency 同时进行中的影子请求数量上限，超出时影子请求会被丢弃
+const DefaultShadowConcurrency = 16
+
+// shadowSemaphore 限制影子请求的并发数量
+var shadowSemaphore = make(chan struct{}, DefaultShadowConcurrency)
+
+// ShadowResult 影子请求与主请求的比对结果
+type ShadowResult struct {
+	// Method HTTP 方法
+	Method string
+	// URL 影子请求的实际地址
+	URL string
+	// PrimaryStatus 主请求的状态码
+	PrimaryStatus int
+	// ShadowStatus 影子请求的状态码，请求失败时为 0
+	ShadowStatus int
+	// StatusMatch 状态码是否一致
+	StatusMatch bool
+	// BodyMatch 响应体的 SHA-256 是否一致
+	BodyMatch bool
+	// Duration 影子请求耗时
+	Duration time.Duration
+	// Err 影子请求的错误信息，仅用于观测，不会影响主请求
+	Err error
+}
+
+// shadowConfig 影子流量配置
+type shadowConfig struct {
+	target   string
+	percent  float64
+	reporter func(ShadowResult)
+}
+
+// WithShadow 将一定比例的请求异步复制到影子地址，用于依赖迁移时的流量比对
+//
+// 主请求返回后，按 percent（0-100）采样，使用相同的请求配置（方法、请求头、查询参数、请求体、
+// 表单和文件、TLS 设置等）异步重放到影子地址：影子地址的 scheme、host 和路径前缀替换主请求的对应部分，
+// 主请求的路径和查询参数保持不变。io.Reader 类型的请求体和文件只能读取一次，
+// 被采样的请求会先将其读入内存，主请求和影子请求各自发送一份副本。
+// 影子请求不会影响主请求的耗时和结果，失败只记录日志；
+// 并发数超过 DefaultShadowConcurrency 时直接丢弃。
+//
+// 参数:
+//   - url: 影子服务地址，例如 "https://new-service.internal"
+//   - percent: 采样百分比，0 表示不复制，100 表示全部复制
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodGet, "https://old-service.internal/v1/items",
+//	    WithShadow("https://new-service.internal", 10),
+//	    WithShadowReporter(func(r ShadowResult) {
+//	        metrics.Record(r.StatusMatch, r.BodyMatch)
+//	    }),
+//	)
+func WithShadow(url string, percent float64) RequestOption {
+	return func(c *requestConfig) {
+		if c.shadow == nil {
+			c.shadow = &shadowConfig{}
+		}
+		c.shadow.target = url
+		c.shadow.percent = percent
+	}
+}
+
+// WithShadowReporter 设置影子请求比对结果的回调，回调在后台 goroutine 中执行
+func WithShadowReporter(reporter func(ShadowResult)) RequestOption {
+	return func(c *requestConfig) {
+		if c.shadow == nil {
+			c.shadow = &shadowConfig{}
+		}
+		c.shadow.reporter = reporter
+	}
+}
+
+// sampled 判断本次请求是否需要复制
+func (s *shadowConfig) sampled() bool {
+	if s.target == "" || s.percent <= 0 {
+		return false
+	}
+	if s.percent >= 100 {
+		return true
+	}
+	return rand.Float64()*100 < s.percent
+}
+
+// prepare 在主请求发送前采样，需要复制时返回影子请求的配置，否则返回 nil
+//
+// 影子请求复制主请求的全部配置，但不解析响应体到 entity，也不再次复制。
+// io.Reader 类型的请求体和文件读入内存后，主请求和影子请求各自从副本读取。
+func (s *shadowConfig) prepare(cfg *requestConfig) (*requestConfig, error) {
+	if !s.sampled() {
+		return nil, nil
+	}
+
+	shadowCfg := *cfg
+	shadowCfg.shadow = nil
+	shadowCfg.entity = nil
+	if reader, ok := cfg.body.(io.Reader); ok {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		cfg.body = bytes.NewReader(data)
+		shadowCfg.body = bytes.NewReader(data)
+	}
+	if len(cfg.files) > 0 {
+		shadowCfg.files = make([]fileReader, len(cfg.files))
+		for i, file := range cfg.files {
+			data, err := io.ReadAll(file.reader)
+			if err != nil {
+				return nil, err
+			}
+			cfg.files[i].reader = bytes.NewReader(data)
+			shadowCfg.files[i] = fileReader{param: file.param, fileName: file.fileName, reader: bytes.NewReader(data)}
+		}
+	}
+	return &shadowCfg, nil
+}
+
+// mirror 在后台按 prepare 生成的配置重放主请求到影子地址，并上报比对结果
+func (s *shadowConfig) mirror(method, primaryURL string, shadowCfg *requestConfig, primary *Response) {
+	shadowURL, err := rewriteShadowURL(primaryURL, s.target)
+	if err != nil {
+		zap.L().Warn("Shadow URL Error", zap.String("target", s.target), zap.Error(err))
+		return
+	}
+
+	select {
+	case shadowSemaphore <- struct{}{}:
+	default:
+		zap.L().Warn("Shadow Request Dropped", zap.String("url", shadowURL))
+		return
+	}
+
+	// 在主流程中计算摘要，避免调用方修改响应体后影响比对
+	primaryStatus := primary.StatusCode
+	primaryHash := sha256.Sum256(primary.Body)
+
+	go func() {
+		defer func() { <-shadowSemaphore }()
+
+		ctx, done, err := track(context.Background())
+		if err != nil {
+			return
+		}
+		defer done()
+		if shadowCfg.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, shadowCfg.timeout)
+			defer cancel()
+		}
+
+		start := time.Now()
+		result := ShadowResult{
+			Method:        method,
+			URL:           shadowURL,
+			PrimaryStatus: primaryStatus,
+		}
+		resp, err := execute(ctx, method, shadowURL, shadowCfg)
+		result.Duration = time.Since(start)
+		if err != nil {
+			zap.L().Warn("Shadow Request Error", zap.String("url", shadowURL), zap.Error(err))
+			result.Err = err
+		} else {
+			result.ShadowStatus = resp.StatusCode
+			result.StatusMatch = resp.StatusCode == primaryStatus
+			result.BodyMatch = sha256.Sum256(resp.Body) == primaryHash
+		}
+
+		if s.reporter != nil {
+			s.reporter(result)
+		}
+	}()
+}
+
+// rewriteShadowURL 将主请求地址的 scheme、host 替换为影子地址，并拼接影子地址的路径前缀
+func rewriteShadowURL(primaryURL, target string) (string, error) {
+	primary, err := url.Parse(primaryURL)
+	if err != nil {
+		return "", err
+	}
+	shadow, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+
+	rewritten := *primary
+	rewritten.Scheme = shadow.Scheme
+	rewritten.Host = shadow.Host
+	rewritten.User = shadow.User
+	rewritten.Path = strings.TrimRight(shadow.Path, "/") + primary.Path
+	rewritten.RawPath = ""
+	return rewritten.String(), nil
+}
@@ -0,0 +1,241 @@
+package resty_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestWithShadow(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer primary.Close()
+
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 影子请求应保留原始路径、查询参数和请求头
+		assert.Equal(t, "/v2/items", r.URL.Path)
+		assert.Equal(t, "id=1", r.URL.RawQuery)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"changed"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer shadow.Close()
+
+	results := make(chan ShadowResult, 1)
+	resp, err := Do(context.Background(), http.MethodGet, primary.URL+"/items?id=1",
+		WithHeaders(map[string]string{"Authorization": "test-token"}),
+		WithShadow(shadow.URL+"/v2", 100),
+		WithShadowReporter(func(r ShadowResult) { results <- r }),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp.Body)
+
+	select {
+	case result := <-results:
+		assert.NoError(t, result.Err)
+		assert.Equal(t, shadow.URL+"/v2/items?id=1", result.URL)
+		assert.True(t, result.StatusMatch, "status should match")
+		assert.False(t, result.BodyMatch, "body should not match")
+	case <-time.After(5 * time.Second):
+		t.Fatal("shadow result not reported")
+	}
+}
+
+func TestWithShadowSampling(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer primary.Close()
+
+	var count int64
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer shadow.Close()
+
+	// 0% 不应复制任何请求
+	for i := 0; i < 10; i++ {
+		_, err := Do(context.Background(), http.MethodGet, primary.URL, WithShadow(shadow.URL, 0))
+		assert.NoError(t, err)
+	}
+
+	// 100% 应复制全部请求
+	reported := make(chan struct{}, 10)
+	for i := 0; i < 10; i++ {
+		_, err := Do(context.Background(), http.MethodGet, primary.URL,
+			WithShadow(shadow.URL, 100),
+			WithShadowReporter(func(ShadowResult) { reported <- struct{}{} }),
+		)
+		assert.NoError(t, err)
+	}
+	for i := 0; i < 10; i++ {
+		select {
+		case <-reported:
+		case <-time.After(5 * time.Second):
+			t.Fatal("shadow result not reported")
+		}
+	}
+	assert.Equal(t, int64(10), atomic.LoadInt64(&count))
+}
+
+func TestWithShadowAsync(t *testing.T) {
+	body := `{"status":"ok","data":[1,2,3]}`
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer primary.Close()
+
+	release := make(chan struct{})
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 阻塞影子请求，验证主请求不受影响
+		<-release
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer shadow.Close()
+
+	without, err := Do(context.Background(), http.MethodGet, primary.URL)
+	assert.NoError(t, err)
+
+	results := make(chan ShadowResult, 1)
+	done := make(chan *Response, 1)
+	go func() {
+		with, err := Do(context.Background(), http.MethodGet, primary.URL,
+			WithShadow(shadow.URL, 100),
+			WithShadowReporter(func(r ShadowResult) { results <- r }),
+		)
+		assert.NoError(t, err)
+		done <- with
+	}()
+
+	select {
+	case with := <-done:
+		// 开启影子流量后主请求结果应完全一致
+		assert.Equal(t, without.StatusCode, with.StatusCode)
+		assert.Equal(t, without.Body, with.Body)
+	case <-time.After(5 * time.Second):
+		t.Fatal("primary request blocked by shadow request")
+	}
+
+	close(release)
+	select {
+	case result := <-results:
+		assert.False(t, result.StatusMatch, "status should not match")
+		assert.Equal(t, http.StatusInternalServerError, result.ShadowStatus)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shadow result not reported")
+	}
+}
+
+// capturedRequest 测试服务收到的请求
+type capturedRequest struct {
+	query string
+	body  string
+	form  map[string]string
+}
+
+// captureServer 启动将收到的请求发送到 ch 的测试服务，multipart 请求会解析表单和文件内容
+func captureServer(t *testing.T, response string, ch chan<- capturedRequest) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured := capturedRequest{query: r.URL.RawQuery}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") && r.ParseMultipartForm(1<<20) == nil {
+			captured.form = map[string]string{}
+			for name, values := range r.MultipartForm.Value {
+				captured.form[name] = values[0]
+			}
+			for name, files := range r.MultipartForm.File {
+				f, err := files[0].Open()
+				if assert.NoError(t, err) {
+					data, _ := io.ReadAll(f)
+					f.Close()
+					captured.form[name] = files[0].Filename + ":" + string(data)
+				}
+			}
+		} else {
+			data, _ := io.ReadAll(r.Body)
+			captured.body = string(data)
+		}
+		ch <- captured
+		_, _ = io.WriteString(w, response)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestWithShadowReplaysFullRequest(t *testing.T) {
+	primaryReqs := make(chan capturedRequest, 1)
+	shadowReqs := make(chan capturedRequest, 1)
+	primary := captureServer(t, `{"status":"ok"}`, primaryReqs)
+	shadow := captureServer(t, `{"status":"ok"}`, shadowReqs)
+
+	// io.Reader 类型的请求体只能读取一次，主请求和影子请求都应收到完整内容
+	var entity map[string]string
+	results := make(chan ShadowResult, 1)
+	_, err := Do(context.Background(), http.MethodPost, primary.URL+"/items",
+		WithQuery(map[string]string{"page": "2"}),
+		WithBody(strings.NewReader("payload")),
+		WithEntity(&entity),
+		WithShadow(shadow.URL, 100),
+		WithShadowReporter(func(r ShadowResult) { results <- r }),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"status": "ok"}, entity)
+	assert.Equal(t, capturedRequest{query: "page=2", body: "payload"}, <-primaryReqs)
+
+	select {
+	case result := <-results:
+		assert.NoError(t, result.Err)
+		assert.True(t, result.StatusMatch)
+		assert.True(t, result.BodyMatch)
+		assert.Equal(t, capturedRequest{query: "page=2", body: "payload"}, <-shadowReqs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shadow result not reported")
+	}
+}
+
+func TestWithShadowReplaysMultipart(t *testing.T) {
+	primaryReqs := make(chan capturedRequest, 1)
+	shadowReqs := make(chan capturedRequest, 1)
+	primary := captureServer(t, `ok`, primaryReqs)
+	shadow := captureServer(t, `ok`, shadowReqs)
+
+	results := make(chan ShadowResult, 1)
+	_, err := Do(context.Background(), http.MethodPost, primary.URL+"/upload",
+		WithFormData(map[string]string{"kind": "avatar"}),
+		WithFileReader("file", "a.txt", strings.NewReader("file content")),
+		WithShadow(shadow.URL, 100),
+		WithShadowReporter(func(r ShadowResult) { results <- r }),
+	)
+	assert.NoError(t, err)
+	want := capturedRequest{form: map[string]string{"kind": "avatar", "file": "a.txt:file content"}}
+	assert.Equal(t, want, <-primaryReqs)
+
+	select {
+	case result := <-results:
+		assert.NoError(t, result.Err)
+		assert.Equal(t, want, <-shadowReqs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shadow result not reported")
+	}
+}
@@ -0,0 +1,85 @@
+package resty
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrShutdown 调用 Shutdown 之后发起的请求返回的错误
+var ErrShutdown = errors.New("http client is shut down")
+
+var (
+	shutdownMutex sync.RWMutex
+	shutdownDone  bool
+	inFlight      sync.WaitGroup
+
+	rootCtx, rootCancel = context.WithCancel(context.Background())
+)
+
+// Shutdown 取消所有进行中的请求，并等待它们结束
+//
+// 包内的请求都派生自同一个根上下文，Shutdown 取消根上下文后，进行中的请求会以
+// context.Canceled 错误尽快返回，之后发起的请求直接返回 ErrShutdown。
+// GetRequest、GetHttpsRequest 返回的请求同样派生自根上下文，但不会被等待。
+//
+// 参数:
+//   - ctx: 等待的上下文，到期后不再等待，剩余的请求仍会在取消后自行结束
+//
+// 返回值:
+//   - error: 所有请求在 ctx 到期前结束时为 nil，否则为 ctx.Err()
+//
+// 示例:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := Shutdown(ctx); err != nil {
+//	    log.Println("requests still running:", err)
+//	}
+func Shutdown(ctx context.Context) error {
+	shutdownMutex.Lock()
+	shutdownDone = true
+	rootCancel()
+	shutdownMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// track 将请求上下文关联到根上下文，并登记为进行中的请求
+//
+// 请求结束后必须调用返回的 done。
+func track(ctx context.Context) (context.Context, func(), error) {
+	shutdownMutex.RLock()
+	defer shutdownMutex.RUnlock()
+
+	if shutdownDone {
+		return nil, nil, ErrShutdown
+	}
+	inFlight.Add(1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(rootCtx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		inFlight.Done()
+	}, nil
+}
+
+// rootContext 返回所有请求共享的根上下文
+func rootContext() context.Context {
+	shutdownMutex.RLock()
+	defer shutdownMutex.RUnlock()
+	return rootCtx
+}
@@ -0,0 +1,85 @@
+package resty_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestShutdown(t *testing.T) {
+	t.Cleanup(ResetShutdown)
+
+	started := make(chan struct{}, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		// 模拟很慢的上游，只有请求被取消时才返回
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := Get(ts.URL)
+		errs <- err
+	}()
+	go func() {
+		_, err := Do(context.Background(), http.MethodGet, ts.URL, WithTimeout(time.Minute))
+		errs <- err
+	}()
+	<-started
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	assert.NoError(t, Shutdown(ctx))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// 进行中的请求被取消
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, <-errs, context.Canceled)
+	}
+
+	// 之后的请求直接失败
+	_, err := Get(ts.URL)
+	assert.ErrorIs(t, err, ErrShutdown)
+	_, err = GetRequest(DefaultTimeout).Get(ts.URL)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestShutdownIdle(t *testing.T) {
+	t.Cleanup(ResetShutdown)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, Shutdown(ctx))
+	// 重复调用
+	assert.NoError(t, Shutdown(ctx))
+}
+
+func TestShutdownRejectsBodyHelpers(t *testing.T) {
+	t.Cleanup(ResetShutdown)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, Shutdown(ctx))
+
+	// GetBody 和 RemoteFileInfo 与 Do 一样受 Shutdown 管理
+	_, err := GetBody(ts.URL, nil)
+	assert.ErrorIs(t, err, ErrShutdown)
+	_, err = RemoteFileInfo(ts.URL, nil)
+	assert.ErrorIs(t, err, ErrShutdown)
+}
@@ -0,0 +1,135 @@
+package resty
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RequestSigner 请求签名函数，可以修改请求头、查询参数等，返回错误时请求不会发送
+type RequestSigner func(*resty.Request) error
+
+var (
+	requestSignerMutex sync.RWMutex
+	requestSigner      RequestSigner
+)
+
+// SetRequestSigner 设置全局的请求签名函数，传入 nil 时取消签名
+//
+// 签名函数对包内创建的所有客户端生效（包括 GetRequest 返回的请求），
+// 在 resty 的 OnBeforeRequest 阶段执行。执行顺序为：
+//  1. 签名函数，执行前请求体已经序列化为最终发送的字节（[]byte），JSON 和 XML 请求体按 Content-Type 序列化；
+//  2. 调用方在返回的客户端上注册的 OnBeforeRequest 钩子；
+//  3. resty 内置中间件（生成 http.Request）和 SetPreRequestHook 设置的钩子（如 WithBodyLogging）。
+//
+// 表单和 multipart 请求体由 resty 在签名之后生成，签名函数需要自行从 FormData 计算。
+// PostChannel、DownloadVerified、UploadFile 等流式接口不经过 resty 的请求流程，同样会签名，
+// 但请求体无法提前读取，签名函数看到的 Body 为 nil，只能对请求方法、地址、请求头和查询参数签名。
+//
+// 示例:
+//
+//	SetRequestSigner(func(r *resty.Request) error {
+//	    body, _ := r.Body.([]byte)
+//	    sum := sha256.Sum256(body)
+//	    r.SetHeader("X-Content-Sha256", hex.EncodeToString(sum[:]))
+//	    return nil
+//	})
+func SetRequestSigner(signer RequestSigner) {
+	requestSignerMutex.Lock()
+	defer requestSignerMutex.Unlock()
+	requestSigner = signer
+}
+
+// loadRequestSigner 返回当前设置的签名函数
+func loadRequestSigner() RequestSigner {
+	requestSignerMutex.RLock()
+	defer requestSignerMutex.RUnlock()
+	return requestSigner
+}
+
+// signRequest 在客户端的 OnBeforeRequest 阶段调用当前设置的签名函数
+func signRequest(_ *resty.Client, r *resty.Request) error {
+	signer := loadRequestSigner()
+	if signer == nil {
+		return nil
+	}
+	if err := finalizeBody(r); err != nil {
+		return err
+	}
+	return signer(r)
+}
+
+// signStreamRequest 对不经过 resty 请求流程的流式请求调用当前设置的签名函数
+//
+// 签名函数收到的 resty.Request 包含 req 的方法、完整地址和请求头，Body 为 nil；
+// 签名函数修改的请求头、查询参数和地址会写回 req。
+func signStreamRequest(client *resty.Client, req *http.Request) error {
+	signer := loadRequestSigner()
+	if signer == nil {
+		return nil
+	}
+
+	r := client.R().SetContext(req.Context())
+	r.Method = req.Method
+	r.URL = req.URL.String()
+	r.Header = req.Header.Clone()
+	if err := signer(r); err != nil {
+		return err
+	}
+
+	if r.URL != req.URL.String() {
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			return err
+		}
+		req.URL = u
+		req.Host = u.Host
+	}
+	if len(r.QueryParam) > 0 {
+		// 与 resty 一致，签名函数设置的查询参数替换地址中的同名参数
+		query := req.URL.Query()
+		for k, v := range r.QueryParam {
+			query[k] = v
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+	req.Header = r.Header
+	return nil
+}
+
+// finalizeBody 将请求体序列化为最终发送的字节，使签名函数看到的与实际发送的一致
+func finalizeBody(r *resty.Request) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch body := r.Body.(type) {
+	case nil, []byte:
+		return nil
+	case string:
+		data = []byte(body)
+	case io.Reader:
+		data, err = io.ReadAll(body)
+	default:
+		contentType := r.Header.Get(ContentType)
+		if strings.Contains(contentType, "xml") {
+			data, err = xml.Marshal(body)
+			break
+		}
+		if contentType == "" {
+			r.SetHeader(ContentType, ContentTypeJson)
+		}
+		data, err = json.Marshal(body)
+	}
+	if err != nil {
+		return err
+	}
+	r.SetBody(data)
+	return nil
+}
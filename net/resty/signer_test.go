@@ -0,0 +1,130 @@
+package resty_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+// bodyHashSigner 将最终请求体的 SHA-256 写入 X-Content-Sha256 请求头
+func bodyHashSigner(r *resty.Request) error {
+	body, ok := r.Body.([]byte)
+	if !ok && r.Body != nil {
+		return errors.New("body is not finalized")
+	}
+	sum := sha256.Sum256(body)
+	r.SetHeader("X-Content-Sha256", hex.EncodeToString(sum[:]))
+	return nil
+}
+
+func TestSetRequestSigner(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 签名使用的字节与实际发送的一致
+		sum := sha256.Sum256(body)
+		assert.Equal(t, hex.EncodeToString(sum[:]), r.Header.Get("X-Content-Sha256"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	SetRequestSigner(bodyHashSigner)
+	t.Cleanup(func() { SetRequestSigner(nil) })
+
+	// JSON 请求体在签名前完成序列化
+	_, err := Do(context.Background(), http.MethodPost, ts.URL, WithJSONBody(map[string]string{"name": "test"}))
+	assert.NoError(t, err)
+
+	// 字符串请求体
+	_, err = Post(ts.URL, `{"name":"test"}`, nil)
+	assert.NoError(t, err)
+
+	// 没有请求体
+	_, err = Get(ts.URL)
+	assert.NoError(t, err)
+
+	// GetRequest 返回的请求同样签名
+	_, err = GetRequest(DefaultTimeout).SetBody(map[string]int{"id": 1}).Post(ts.URL)
+	assert.NoError(t, err)
+}
+
+func TestSetRequestSignerError(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	signErr := errors.New("missing credentials")
+	SetRequestSigner(func(*resty.Request) error { return signErr })
+	t.Cleanup(func() { SetRequestSigner(nil) })
+
+	_, err := Get(ts.URL)
+	assert.ErrorIs(t, err, signErr)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&count), "request should not be sent")
+
+	// 取消签名后恢复正常
+	SetRequestSigner(nil)
+	_, err = Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&count))
+}
+
+func TestSetRequestSignerStream(t *testing.T) {
+	var count int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		assert.Equal(t, "POST /stream?a=1", r.Header.Get("X-Signature"))
+		assert.Equal(t, "1", r.URL.Query().Get("a"))
+		assert.Equal(t, "key-1", r.URL.Query().Get("key"))
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "line1\nline2\n", string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	// 流式请求体无法提前读取，签名函数只能对方法、地址和请求头签名
+	SetRequestSigner(func(r *resty.Request) error {
+		if r.Body != nil {
+			return errors.New("unexpected stream body")
+		}
+		if r.Header.Get("Authorization") == "" {
+			return errors.New("missing credentials")
+		}
+		r.SetHeader("X-Signature", r.Method+" "+strings.TrimPrefix(r.URL, ts.URL))
+		r.SetQueryParam("key", "key-1")
+		return nil
+	})
+	t.Cleanup(func() { SetRequestSigner(nil) })
+
+	lines := make(chan []byte, 2)
+	lines <- []byte("line1")
+	lines <- []byte("line2")
+	close(lines)
+	_, err := PostChannel(context.Background(), ts.URL+"/stream?a=1", lines, map[string]string{"Authorization": "test-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&count))
+
+	// 签名失败时请求不会发送
+	lines = make(chan []byte)
+	close(lines)
+	_, err = PostChannel(context.Background(), ts.URL+"/stream?a=1", lines, nil)
+	assert.EqualError(t, err, "missing credentials")
+	assert.Equal(t, int64(1), atomic.LoadInt64(&count), "request should not be sent")
+}
@@ -0,0 +1,81 @@
+package resty
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// payloadSizesKey 请求上下文中 payloadCounter 的键
+type payloadSizesKey struct{}
+
+// payloadCounter 累计一次调用（包括所有重试）发送和接收的消息体字节数
+type payloadCounter struct {
+	requestBytes  atomic.Int64
+	responseBytes atomic.Int64
+}
+
+// withPayloadCounter 返回携带新计数器的上下文，经过该上下文发送的请求都会累计到计数器中
+func withPayloadCounter(ctx context.Context) (context.Context, *payloadCounter) {
+	counter := &payloadCounter{}
+	return context.WithValue(ctx, payloadSizesKey{}, counter), counter
+}
+
+// recordPayloadSizes 在客户端的 OnAfterResponse 阶段累计请求体和响应体的字节数
+func recordPayloadSizes(_ *resty.Client, res *resty.Response) error {
+	addPayloadSizes(res)
+	return nil
+}
+
+// addPayloadSizes 将单次请求的消息体大小累计到请求上下文中的计数器，上下文中没有计数器时忽略
+func addPayloadSizes(res *resty.Response) {
+	if res == nil || res.Request == nil || res.Request.RawRequest == nil {
+		return
+	}
+	counter, ok := res.Request.Context().Value(payloadSizesKey{}).(*payloadCounter)
+	if !ok {
+		return
+	}
+	// 长度未知的流式请求体（ContentLength 为 -1）无法统计
+	if n := res.Request.RawRequest.ContentLength; n > 0 {
+		counter.requestBytes.Add(n)
+	}
+	counter.responseBytes.Add(int64(len(res.Body())))
+}
+
+// GetWithSizes 发送 HTTP GET 请求，并返回本次请求发送和接收的消息体字节数，用于按租户统计流量成本
+//
+// 统计的是消息体（body）大小，不包括请求行、header 和 TLS 等协议开销；
+// 配置了重试时，所有尝试的字节数都会累计，因为失败的请求同样产生了流量。
+//
+// 注意:
+//   - 响应体默认被透明解压，respBytes 是解压后的大小，通常大于实际在网络上传输的字节数；
+//     需要统计压缩后的大小时请使用 Do 配合 WithRawCompression，此时 respBytes 与网络传输的消息体一致
+//   - 请求体按实际发送的字节统计，如果请求体在发送前被压缩，统计的是压缩后的大小
+//
+// 参数:
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头，如租户标识
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - reqBytes: 发送的请求体字节数
+//   - respBytes: 接收的响应体字节数
+//   - err: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	resp, reqBytes, respBytes, err := GetWithSizes("https://api.example.com/reports", map[string]string{
+//	    "X-Tenant-ID": tenantID,
+//	})
+//	egress.Add(tenantID, reqBytes+respBytes)
+func GetWithSizes(url string, header map[string]string) (resp []byte, reqBytes, respBytes int64, err error) {
+	ctx, counter := withPayloadCounter(context.Background())
+	res, err := Do(ctx, http.MethodGet, url, WithHeaders(header))
+	if err == nil {
+		resp = res.Body
+	}
+	return resp, counter.requestBytes.Load(), counter.responseBytes.Load(), err
+}
@@ -0,0 +1,78 @@
+package resty_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetWithSizes(t *testing.T) {
+	payload := strings.Repeat("a", 4096)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tenant-1", r.Header.Get("X-Tenant-ID"))
+		if r.URL.Path == "/gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			_, _ = zw.Write([]byte(payload))
+			_ = zw.Close()
+			return
+		}
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer ts.Close()
+	header := map[string]string{"X-Tenant-ID": "tenant-1"}
+
+	resp, reqBytes, respBytes, err := GetWithSizes(ts.URL, header)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, string(resp))
+	assert.Equal(t, int64(0), reqBytes)
+	assert.Equal(t, int64(len(payload)), respBytes)
+
+	// 透明解压后统计的是解压后的大小
+	resp, _, respBytes, err = GetWithSizes(ts.URL+"/gzip", header)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, string(resp))
+	assert.Equal(t, int64(len(payload)), respBytes)
+}
+
+func TestPayloadSizesAcrossRetries(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("busy"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	ctx, sizes := WithPayloadCounter(context.Background())
+	_, err := Do(ctx, http.MethodPost, ts.URL,
+		WithBody("0123456789"),
+		WithRetry(RetryConfig{MaxRetries: 1, WaitTime: time.Millisecond}),
+	)
+	assert.NoError(t, err)
+	// 失败的尝试同样计入
+	reqBytes, respBytes := sizes()
+	assert.Equal(t, int64(20), reqBytes)
+	assert.Equal(t, int64(len("busy")+len("ok")), respBytes)
+}
+
+func TestGetWithSizesError(t *testing.T) {
+	resp, reqBytes, respBytes, err := GetWithSizes("http://127.0.0.1:0", nil)
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, int64(0), reqBytes)
+	assert.Equal(t, int64(0), respBytes)
+}
@@ -0,0 +1,114 @@
+package resty
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// SnapshotUpdateEnv 控制 GetAndSnapshot 工作模式的环境变量
+//
+// 值为 strconv.ParseBool 认可的真值（如 "1"、"true"）时为记录模式，将响应体写入快照文件；
+// 未设置或为其他值时为校验模式，将响应体与快照文件比较。
+const SnapshotUpdateEnv = "RESTY_UPDATE_SNAPSHOTS"
+
+// ErrSnapshotMismatch 响应体与快照文件的内容不一致，具体差异见 *SnapshotMismatchError
+var ErrSnapshotMismatch = errors.New("snapshot mismatch")
+
+// SnapshotMismatchError 响应体与快照不一致时返回的错误，可通过 errors.Is(err, ErrSnapshotMismatch) 判断
+type SnapshotMismatchError struct {
+	// Path 快照文件路径
+	Path string
+	// Line 第一处不一致所在的行号，从 1 开始
+	Line int
+	// Expected 快照中该行的内容，快照在此之前已结束时为空
+	Expected string
+	// Actual 响应体中该行的内容，响应体在此之前已结束时为空
+	Actual string
+}
+
+// Error 实现 error 接口
+func (e *SnapshotMismatchError) Error() string {
+	return fmt.Sprintf("%s: %s: line %d: expected %q, got %q", ErrSnapshotMismatch, e.Path, e.Line, e.Expected, e.Actual)
+}
+
+// Unwrap 返回 ErrSnapshotMismatch
+func (e *SnapshotMismatchError) Unwrap() error {
+	return ErrSnapshotMismatch
+}
+
+// GetAndSnapshot 发送 HTTP GET 请求，并将响应体与快照文件比较，用于契约测试
+//
+// 工作模式由环境变量 SnapshotUpdateEnv 控制：
+//   - 记录模式: 将响应体写入 snapshotPath，必要时创建上级目录，已有的快照会被覆盖
+//   - 校验模式: 按字节比较响应体与 snapshotPath 的内容，不一致时返回 *SnapshotMismatchError
+//
+// 响应体按原样比较，不做 JSON 等格式的规范化，不同状态码的响应体同样会被记录和比较。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//   - snapshotPath: 快照文件路径，如 testdata/orders.golden.json
+//
+// 返回值:
+//   - []byte: 响应体的字节数组，与快照不一致时为 nil
+//   - error: 与快照不一致时为 *SnapshotMismatchError，校验模式下快照文件不存在时错误满足 errors.Is(err, fs.ErrNotExist)，
+//     否则为请求过程或读写快照文件的错误信息
+//
+// 示例:
+//
+//	// 更新快照: RESTY_UPDATE_SNAPSHOTS=1 go test ./...
+//	_, err := GetAndSnapshot(server.URL+"/v1/orders/1", nil, "testdata/order.golden.json")
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+func GetAndSnapshot(url string, header map[string]string, snapshotPath string) ([]byte, error) {
+	body, err := doBody(http.MethodGet, url, WithHeaders(header))
+	if err != nil {
+		return nil, err
+	}
+
+	if update, _ := strconv.ParseBool(os.Getenv(SnapshotUpdateEnv)); update {
+		if err := os.MkdirAll(filepath.Dir(snapshotPath), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(snapshotPath, body, 0o644); err != nil {
+			return nil, err
+		}
+		return body, nil
+	}
+
+	expected, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("snapshot %s not recorded, set %s=1 to record it: %w", snapshotPath, SnapshotUpdateEnv, err)
+		}
+		return nil, err
+	}
+	if !bytes.Equal(expected, body) {
+		return nil, snapshotDiff(snapshotPath, expected, body)
+	}
+	return body, nil
+}
+
+// snapshotDiff 找出快照与响应体第一处不一致的行
+func snapshotDiff(path string, expected, actual []byte) *SnapshotMismatchError {
+	expectedLines := bytes.Split(expected, []byte("\n"))
+	actualLines := bytes.Split(actual, []byte("\n"))
+	for i := 0; ; i++ {
+		var want, got []byte
+		if i < len(expectedLines) {
+			want = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			got = actualLines[i]
+		}
+		if !bytes.Equal(want, got) || i >= len(expectedLines) || i >= len(actualLines) {
+			return &SnapshotMismatchError{Path: path, Line: i + 1, Expected: string(want), Actual: string(got)}
+		}
+	}
+}
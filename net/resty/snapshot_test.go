@@ -0,0 +1,80 @@
+package resty_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestGetAndSnapshot(t *testing.T) {
+	body := "{\n  \"id\": 1,\n  \"status\": \"paid\"\n}\n"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "Bearer token123", r.Header.Get("Authorization"))
+		_, _ = io.WriteString(w, body)
+	}))
+	defer ts.Close()
+	header := map[string]string{"Authorization": "Bearer token123"}
+	path := filepath.Join(t.TempDir(), "testdata", "order.golden.json")
+
+	// 校验模式下快照不存在
+	t.Setenv(SnapshotUpdateEnv, "")
+	_, err := GetAndSnapshot(ts.URL, header, path)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Contains(t, err.Error(), SnapshotUpdateEnv)
+
+	// 记录模式下写入快照，自动创建目录
+	t.Setenv(SnapshotUpdateEnv, "1")
+	resp, err := GetAndSnapshot(ts.URL, header, path)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(body), resp)
+	saved, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(saved))
+
+	// 校验模式下内容一致
+	t.Setenv(SnapshotUpdateEnv, "false")
+	resp, err = GetAndSnapshot(ts.URL, header, path)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(body), resp)
+
+	// 上游变更后返回第一处差异
+	body = "{\n  \"id\": 1,\n  \"status\": \"refunded\"\n}\n"
+	resp, err = GetAndSnapshot(ts.URL, header, path)
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, ErrSnapshotMismatch)
+	var mismatch *SnapshotMismatchError
+	if assert.True(t, errors.As(err, &mismatch)) {
+		assert.Equal(t, &SnapshotMismatchError{
+			Path:     path,
+			Line:     3,
+			Expected: `  "status": "paid"`,
+			Actual:   `  "status": "refunded"`,
+		}, mismatch)
+	}
+
+	// 响应体多出内容
+	body = "{\n  \"id\": 1,\n  \"status\": \"paid\"\n}\n{}"
+	_, err = GetAndSnapshot(ts.URL, header, path)
+	if assert.True(t, errors.As(err, &mismatch)) {
+		assert.Equal(t, 5, mismatch.Line)
+		assert.Equal(t, "", mismatch.Expected)
+		assert.Equal(t, "{}", mismatch.Actual)
+	}
+
+	// 重新记录后校验通过
+	t.Setenv(SnapshotUpdateEnv, "true")
+	_, err = GetAndSnapshot(ts.URL, header, path)
+	assert.NoError(t, err)
+	t.Setenv(SnapshotUpdateEnv, "")
+	_, err = GetAndSnapshot(ts.URL, header, path)
+	assert.NoError(t, err)
+}
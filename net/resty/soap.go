@@ -0,0 +1,154 @@
+package resty
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// SOAP 1.1 相关的常量定义
+const (
+	// ContentTypeSOAP SOAP 1.1 请求的 Content-Type 值
+	ContentTypeSOAP = "text/xml; charset=utf-8"
+	// SOAPEnvelopeNamespace SOAP 1.1 信封的命名空间
+	SOAPEnvelopeNamespace = "http://schemas.xmlsoap.org/soap/envelope/"
+)
+
+// SOAPFault SOAP 1.1 响应中的 soap:Fault 元素，作为 PostSOAP 的错误返回
+type SOAPFault struct {
+	// FaultCode 错误码，如 soap:Client、soap:Server
+	FaultCode string `xml:"faultcode"`
+	// FaultString 错误描述
+	FaultString string `xml:"faultstring"`
+	// FaultActor 产生错误的节点，可能为空
+	FaultActor string `xml:"faultactor"`
+	// Detail 错误详情的原始 XML
+	Detail struct {
+		Content string `xml:",innerxml"`
+	} `xml:"detail"`
+}
+
+// Error 实现 error 接口
+func (f *SOAPFault) Error() string {
+	return fmt.Sprintf("soap fault %s: %s", f.FaultCode, f.FaultString)
+}
+
+// soapRequestEnvelope 请求信封，请求体按其自身的 XML 名称序列化在 soap:Body 中
+type soapRequestEnvelope struct {
+	XMLName   xml.Name `xml:"soap:Envelope"`
+	Namespace string   `xml:"xmlns:soap,attr"`
+	Body      struct {
+		Content interface{}
+	} `xml:"soap:Body"`
+}
+
+// soapResponseEnvelope 响应信封
+type soapResponseEnvelope struct {
+	Body soapResponseBody `xml:"Body"`
+}
+
+// soapResponseBody 响应信封的 Body，Fault 元素解析为 SOAPFault，其余第一个元素解析到 content
+type soapResponseBody struct {
+	fault   *SOAPFault
+	content interface{}
+}
+
+// UnmarshalXML 实现 xml.Unmarshaler 接口
+func (b *soapResponseBody) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	decoded := false
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "Fault":
+				b.fault = &SOAPFault{}
+				err = d.DecodeElement(b.fault, &t)
+			case b.content != nil && !decoded:
+				decoded = true
+				err = d.DecodeElement(b.content, &t)
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// PostSOAP 发送 SOAP 1.1 请求，并将响应 Body 中的内容解析到 responseBody
+//
+// requestBody 序列化后放入标准的 soap:Envelope/soap:Body 中，请求头设置
+// Content-Type: text/xml; charset=utf-8 和 SOAPAction。
+// 响应中包含 soap:Fault 时返回 *SOAPFault 错误，可通过 errors.As 获取错误码和错误描述。
+//
+// 参数:
+//   - url: 服务地址
+//   - action: SOAPAction 的值
+//   - requestBody: 请求内容，按 encoding/xml 规则序列化
+//   - responseBody: 用于存储响应内容的目标对象指针，为 nil 时不解析
+//   - header: 自定义的 HTTP 请求头
+//   - timeout: 请求超时时间（秒）
+//
+// 返回值:
+//   - error: 请求错误、XML 解析错误、*SOAPFault 或非 2xx 状态码，如果成功则为 nil
+//
+// 示例:
+//
+//	type GetPrice struct {
+//	    XMLName xml.Name `xml:"http://example.com/stock GetPrice"`
+//	    Item    string   `xml:"Item"`
+//	}
+//	type GetPriceResponse struct {
+//	    Price float64 `xml:"Price"`
+//	}
+//	var resp GetPriceResponse
+//	err := PostSOAP("https://example.com/stock", "http://example.com/GetPrice", GetPrice{Item: "apple"}, &resp, nil, 30)
+//	var fault *SOAPFault
+//	if errors.As(err, &fault) {
+//	    log.Println(fault.FaultCode, fault.FaultString)
+//	}
+func PostSOAP(url string, action string, requestBody interface{}, responseBody interface{}, header map[string]string, timeout int64) error {
+	envelope := soapRequestEnvelope{Namespace: SOAPEnvelopeNamespace}
+	envelope.Body.Content = requestBody
+	body, err := xml.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	res, err := Do(context.Background(), http.MethodPost, url,
+		WithHeaders(header),
+		WithHeaders(map[string]string{
+			ContentType:  ContentTypeSOAP,
+			"SOAPAction": `"` + action + `"`,
+		}),
+		WithBody(append([]byte(xml.Header), body...)),
+		WithTimeout(seconds(timeout)),
+	)
+	if err != nil {
+		return err
+	}
+
+	// SOAP 1.1 的 Fault 响应通常使用 500 状态码，需要先解析信封
+	var result soapResponseEnvelope
+	result.Body.content = responseBody
+	if len(res.Body) > 0 {
+		if err = xml.Unmarshal(res.Body, &result); err != nil && res.IsSuccess() {
+			return err
+		}
+	}
+	if result.Body.fault != nil {
+		return result.Body.fault
+	}
+	if !res.IsSuccess() {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package resty_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+type getPrice struct {
+	XMLName xml.Name `xml:"http://example.com/stock GetPrice"`
+	Item    string   `xml:"Item"`
+}
+
+type getPriceResponse struct {
+	Price    float64 `xml:"Price"`
+	Currency string  `xml:"Currency"`
+}
+
+const soapPriceResponse = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:m="http://example.com/stock">
+  <soap:Header/>
+  <soap:Body>
+    <m:GetPriceResponse>
+      <m:Price>1.25</m:Price>
+      <m:Currency>USD</m:Currency>
+    </m:GetPriceResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+const soapFaultResponse = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Client</faultcode>
+      <faultstring>Unknown item</faultstring>
+      <detail><code>404</code></detail>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+func soapServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "text/xml; charset=utf-8", r.Header.Get("Content-Type"))
+		assert.Equal(t, `"http://example.com/GetPrice"`, r.Header.Get("SOAPAction"))
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+
+		var envelope struct {
+			XMLName xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Envelope"`
+			Body    struct {
+				Request getPrice
+			} `xml:"http://schemas.xmlsoap.org/soap/envelope/ Body"`
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = xml.Unmarshal(body, &envelope); err != nil {
+			t.Fatal(err)
+		}
+
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		if envelope.Body.Request.Item != "apple" {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err = io.WriteString(w, soapFaultResponse)
+		} else {
+			w.WriteHeader(http.StatusOK)
+			_, err = io.WriteString(w, soapPriceResponse)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+}
+
+func TestPostSOAP(t *testing.T) {
+	ts := soapServer(t)
+	defer ts.Close()
+
+	headers := map[string]string{"Authorization": "test-token"}
+	var resp getPriceResponse
+	err := PostSOAP(ts.URL, "http://example.com/GetPrice", getPrice{Item: "apple"}, &resp, headers, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.25, resp.Price)
+	assert.Equal(t, "USD", resp.Currency)
+}
+
+func TestPostSOAPFault(t *testing.T) {
+	ts := soapServer(t)
+	defer ts.Close()
+
+	headers := map[string]string{"Authorization": "test-token"}
+	var resp getPriceResponse
+	err := PostSOAP(ts.URL, "http://example.com/GetPrice", getPrice{Item: "pear"}, &resp, headers, 30)
+
+	var fault *SOAPFault
+	if assert.True(t, errors.As(err, &fault)) {
+		assert.Equal(t, "soap:Client", fault.FaultCode)
+		assert.Equal(t, "Unknown item", fault.FaultString)
+		assert.Equal(t, "<code>404</code>", fault.Detail.Content)
+	}
+	assert.EqualError(t, err, "soap fault soap:Client: Unknown item")
+}
+
+func TestPostSOAPUnexpectedStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, err := io.WriteString(w, "<html>bad gateway</html>")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	err := PostSOAP(ts.URL, "http://example.com/GetPrice", getPrice{Item: "apple"}, nil, nil, 30)
+	assert.EqualError(t, err, "unexpected status code: 502")
+}
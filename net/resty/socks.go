@@ -0,0 +1,84 @@
+package resty
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/net/proxy"
+)
+
+// socks5Config SOCKS5 代理配置
+type socks5Config struct {
+	addr string
+	auth *proxy.Auth
+}
+
+// WithSOCKS5 通过 SOCKS5 代理发送请求
+//
+// 目标地址的域名原样发送给代理服务器，由代理负责解析，本地不会发起 DNS 查询，
+// 适用于只能通过 SOCKS 出网、且内部域名只能在代理侧解析的环境。
+// 设置后忽略 WithProxy 和环境变量中的 HTTP 代理。
+//
+// 示例:
+//
+//	resp, err := Do(ctx, http.MethodGet, "https://api.example.com",
+//	    WithSOCKS5("127.0.0.1:1080", &proxy.Auth{User: "user", Password: "password"}),
+//	)
+func WithSOCKS5(addr string, auth *proxy.Auth) RequestOption {
+	return func(c *requestConfig) {
+		c.socks5 = &socks5Config{addr: addr, auth: auth}
+	}
+}
+
+// GetViaSOCKS5 通过 SOCKS5 代理发送 GET 请求
+//
+// 参数:
+//   - url: 目标请求地址
+//   - socksAddr: SOCKS5 代理地址，如 "127.0.0.1:1080"
+//   - auth: 用户名密码认证信息，为 nil 时不认证
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	auth := &proxy.Auth{User: "user", Password: "password"}
+//	resp, err := GetViaSOCKS5("https://api.example.com", "127.0.0.1:1080", auth, nil)
+func GetViaSOCKS5(url, socksAddr string, auth *proxy.Auth, header map[string]string) ([]byte, error) {
+	return doBody(http.MethodGet, url, WithHeaders(header), WithSOCKS5(socksAddr, auth))
+}
+
+// applySOCKS5 将客户端的拨号替换为经过 SOCKS5 代理的拨号，forward 不为 nil 时用于连接代理
+func applySOCKS5(client *resty.Client, cfg *socks5Config, forward *net.Dialer) error {
+	transport, err := client.Transport()
+	if err != nil {
+		return err
+	}
+	if forward == nil {
+		forward = &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+	}
+	dialer, err := proxy.SOCKS5("tcp", cfg.addr, cfg.auth, forward)
+	if err != nil {
+		return err
+	}
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return errors.New("socks5 dialer does not support context")
+	}
+
+	// 连接由 SOCKS5 代理建立，不能再叠加 HTTP 代理
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return contextDialer.DialContext(ctx, network, addr)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package resty_test
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+	"golang.org/x/net/proxy"
+)
+
+// socks5Server 最小化的 SOCKS5 代理，只支持 CONNECT，用于测试
+type socks5Server struct {
+	listener net.Listener
+	user     string
+	password string
+	// hosts 代理侧的域名解析表
+	hosts map[string]string
+
+	mu        sync.Mutex
+	requested []string
+}
+
+func newSOCKS5Server(t *testing.T, user, password string, hosts map[string]string) *socks5Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &socks5Server{listener: listener, user: user, password: password, hosts: hosts}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { _ = listener.Close() })
+	return s
+}
+
+func (s *socks5Server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	// 协商认证方式
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	if s.user == "" {
+		_, _ = conn.Write([]byte{5, 0})
+	} else {
+		_, _ = conn.Write([]byte{5, 2})
+		if !s.authenticate(conn) {
+			return
+		}
+	}
+
+	// CONNECT 请求
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		_, _ = io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		length := make([]byte, 1)
+		_, _ = io.ReadFull(conn, length)
+		name := make([]byte, length[0])
+		_, _ = io.ReadFull(conn, name)
+		host = string(name)
+	default:
+		return
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+	s.mu.Lock()
+	s.requested = append(s.requested, addr)
+	s.mu.Unlock()
+
+	if mapped, ok := s.hosts[host]; ok {
+		addr = mapped
+	}
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 4, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+	_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go func() { _, _ = io.Copy(target, conn) }()
+	_, _ = io.Copy(conn, target)
+}
+
+// authenticate 处理用户名密码认证（RFC 1929）
+func (s *socks5Server) authenticate(conn net.Conn) bool {
+	version := make([]byte, 2)
+	if _, err := io.ReadFull(conn, version); err != nil {
+		return false
+	}
+	user := make([]byte, version[1])
+	_, _ = io.ReadFull(conn, user)
+	length := make([]byte, 1)
+	_, _ = io.ReadFull(conn, length)
+	password := make([]byte, length[0])
+	_, _ = io.ReadFull(conn, password)
+
+	if string(user) != s.user || string(password) != s.password {
+		_, _ = conn.Write([]byte{1, 1})
+		return false
+	}
+	_, _ = conn.Write([]byte{1, 0})
+	return true
+}
+
+func TestGetViaSOCKS5(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+	// 域名只能在代理侧解析
+	socks := newSOCKS5Server(t, "user", "secret", map[string]string{"service.internal": ts.Listener.Addr().String()})
+	url := "http://service.internal:" + port
+	headers := map[string]string{"Authorization": "test-token"}
+
+	resp, err := GetViaSOCKS5(url, socks.listener.Addr().String(), &proxy.Auth{User: "user", Password: "secret"}, headers)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	assert.Equal(t, []string{"service.internal:" + port}, socks.requested)
+
+	_, err = GetViaSOCKS5(url, socks.listener.Addr().String(), &proxy.Auth{User: "user", Password: "wrong"}, headers)
+	assert.Error(t, err)
+}
+
+func TestGetViaSOCKS5NoAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	socks := newSOCKS5Server(t, "", "", nil)
+	resp, err := GetViaSOCKS5(ts.URL, socks.listener.Addr().String(), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	assert.Len(t, socks.requested, 1)
+}
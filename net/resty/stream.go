@@ -0,0 +1,163 @@
+package resty
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// ContentTypeNDJSON JSON Lines 格式的 Content-Type 值
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// PostChannel 将 channel 中逐条产生的数据作为请求体流式发送
+//
+// channel 通过 io.Pipe 接入请求体，使用分块传输编码发送，每收到一行就立即写出并刷新，
+// 生产速度受网络写入速度约束（背压）。channel 关闭表示请求体结束。
+// ctx 取消或请求提前结束（如服务端中途关闭连接）后，剩余的数据会被读取并丢弃，
+// 生产者不需要 select ctx.Done() 也不会阻塞，但仍必须在结束时关闭 channel。
+// 未以换行符结尾的行会自动补充换行符；未设置 Content-Type 时默认使用 application/x-ndjson。
+// 流式请求的时长不确定，因此不设置整体超时，请通过 ctx 控制请求的生命周期。
+//
+// 参数:
+//   - ctx: 请求上下文，取消后请求体写入和请求都会终止
+//   - url: 目标请求地址
+//   - lines: 逐行产生的请求体数据
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - resp: 响应体的字节数组
+//   - err: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	lines := make(chan []byte)
+//	go func() {
+//	    defer close(lines)
+//	    for _, record := range records {
+//	        data, _ := json.Marshal(record)
+//	        lines <- data
+//	    }
+//	}()
+//	resp, err := PostChannel(ctx, "https://api.example.com/export", lines, nil)
+func PostChannel(ctx context.Context, url string, lines <-chan []byte, header map[string]string) (resp []byte, err error) {
+	ctx, done, err := track(ctx)
+	if err != nil {
+		return
+	}
+	defer done()
+
+	pr, pw := io.Pipe()
+	// 请求结束后关闭读端，使仍在写入的 goroutine 退出
+	defer pr.Close()
+
+	go pipeLines(ctx, lines, pw)
+
+	streamHeader := map[string]string{ContentType: ContentTypeNDJSON}
+	for k, v := range header {
+		streamHeader[k] = v
+	}
+	res, err := doStream(ctx, newClient(0), http.MethodPost, url, pr, streamHeader)
+	if err != nil {
+		return
+	}
+	defer drainBody(res.Body)
+	resp, err = io.ReadAll(res.Body)
+	return
+}
+
+// GetBody 发送 GET 请求并返回未读取的响应体，供调用方流式消费
+//
+// 请求通过 DoRaw 发送，响应体不会被读入内存，适合直接转交给其他读取方，
+// 避免完整缓冲带来的额外拷贝。与其他便捷函数一致，非 2xx 状态码不会作为错误返回。
+//
+// 注意:
+//   - 调用方拥有返回的 io.ReadCloser，必须在使用完毕后调用 Close，否则连接无法复用并会泄漏
+//   - 超时时间 DefaultTimeout 包含读取响应体的时间，读取过慢时会返回超时错误
+//
+// 参数:
+//   - url: 目标请求地址
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - io.ReadCloser: 响应体，调用方负责关闭
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 示例:
+//
+//	body, err := GetBody("https://example.com/large.json", nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer body.Close()
+//	_, err = io.Copy(w, body)
+func GetBody(url string, header map[string]string) (io.ReadCloser, error) {
+	res, err := DoRaw(context.Background(), http.MethodGet, url, WithHeaders(header))
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// doStream 使用 resty 客户端底层的 http.Client 发送流式请求体
+//
+// resty 会将 io.Reader 请求体完整读入内存以支持重放，无法满足流式发送的需求，
+// 因此流式请求绕过 resty 的请求流程，但仍复用客户端的 Transport 等配置，并调用 SetRequestSigner 设置的签名函数。
+func doStream(ctx context.Context, client *resty.Client, method, url string, body io.Reader, header map[string]string) (*http.Response, error) {
+	req, err := newStreamRequest(ctx, method, url, body, header)
+	if err != nil {
+		return nil, err
+	}
+	if err = signStreamRequest(client, req); err != nil {
+		return nil, err
+	}
+	return client.GetClient().Do(req)
+}
+
+// newStreamRequest 创建流式请求并补充默认查询参数，调用方可以在发送前调整 ContentLength 等字段
+func newStreamRequest(ctx context.Context, method, url string, body io.Reader, header map[string]string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	applyDefaultQueryToRequest(req)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+// pipeLines 将 channel 中的数据逐行写入管道，channel 关闭时结束写入
+//
+// ctx 取消或写入失败（如服务端提前关闭连接）时停止写入，但会继续读取并丢弃 channel 中剩余的数据直到其关闭，
+// 避免没有 select ctx.Done() 的生产者永远阻塞。写入失败且 ctx 未取消时记录 Warn 日志。
+func pipeLines(ctx context.Context, lines <-chan []byte, pw *io.PipeWriter) {
+	defer func() {
+		for range lines {
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			pw.CloseWithError(ctx.Err())
+			return
+		case line, ok := <-lines:
+			if !ok {
+				pw.Close()
+				return
+			}
+			if len(line) == 0 || line[len(line)-1] != '\n' {
+				line = append(line[:len(line):len(line)], '\n')
+			}
+			if _, err := pw.Write(line); err != nil {
+				if ctx.Err() == nil {
+					zap.L().Warn("Stream Request Body Write Error", zap.Error(err))
+				}
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package resty_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestPostChannel(t *testing.T) {
+	received := make(chan string, 3)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			received <- scanner.Text()
+		}
+		close(received)
+
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	lines := make(chan []byte)
+	go func() {
+		defer close(lines)
+		for _, line := range []string{`{"id":1}`, `{"id":2}`, "{\"id\":3}\n"} {
+			lines <- []byte(line)
+			// 每一行都应在下一行产生之前到达服务端
+			select {
+			case got := <-received:
+				assert.JSONEq(t, line, got)
+			case <-time.After(5 * time.Second):
+				t.Error("line was not flushed")
+				return
+			}
+		}
+	}()
+
+	headers := map[string]string{
+		"Authorization": "test-token",
+	}
+	resp, err := PostChannel(context.Background(), ts.URL+"/test", lines, headers)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+}
+
+func TestPostChannelCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan []byte)
+	go func() {
+		lines <- []byte(`{"id":1}`)
+		cancel()
+	}()
+
+	_, err := PostChannel(ctx, ts.URL+"/test", lines, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// produce 在后台不经 select 地逐条发送 n 行数据，发送完毕并关闭 channel 后关闭返回的 channel
+func produce(lines chan<- []byte, n int) <-chan struct{} {
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer close(lines)
+		for i := 0; i < n; i++ {
+			lines <- []byte(`{"id":1}`)
+		}
+	}()
+	return finished
+}
+
+func TestPostChannelServerAbort(t *testing.T) {
+	// 服务端收到部分请求体后关闭连接
+	url := misbehavingServer(t, func(conn net.Conn) {
+		_, _ = conn.Read(make([]byte, 64))
+	})
+
+	lines := make(chan []byte)
+	finished := produce(lines, 10000)
+	_, err := PostChannel(context.Background(), url, lines, nil)
+	assert.Error(t, err)
+
+	// 请求结束后生产者不会阻塞
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("producer blocked after the request failed")
+	}
+}
+
+func TestPostChannelCanceledProducerFinishes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	lines := make(chan []byte)
+	finished := produce(lines, 100)
+	_, err := PostChannel(ctx, ts.URL, lines, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("producer blocked after ctx was canceled")
+	}
+}
+
+func TestGetBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, `{"status":"ok"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	headers := map[string]string{
+		"Authorization": "test-token",
+	}
+	body, err := GetBody(ts.URL+"/test", headers)
+	assert.NoError(t, err)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":"ok"}`, string(data))
+}
@@ -0,0 +1,133 @@
+package resty
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// TLSInfo 响应所在连接协商的 TLS 信息
+type TLSInfo struct {
+	// Version 协商的 TLS 版本，如 "TLS 1.3"
+	Version string
+	// CipherSuite 协商的加密套件，如 "TLS_AES_128_GCM_SHA256"
+	CipherSuite string
+	// ServerName 客户端在 SNI 中发送的服务器名称
+	ServerName string
+	// NegotiatedProtocol ALPN 协商的应用层协议，如 "h2"，未协商时为空
+	NegotiatedProtocol string
+	// Resumed 是否复用了之前的 TLS 会话
+	Resumed bool
+	// PeerCertificates 服务端发送的证书链，第一个为叶子证书
+	PeerCertificates []CertificateSummary
+}
+
+// CertificateSummary 证书的摘要信息
+type CertificateSummary struct {
+	// Subject 证书主体，如 "CN=api.example.com,O=Example"
+	Subject string
+	// Issuer 证书签发者
+	Issuer string
+	// DNSNames 证书包含的 DNS 名称
+	DNSNames []string
+	// SerialNumber 十进制的证书序列号
+	SerialNumber string
+	// NotBefore 证书生效时间
+	NotBefore time.Time
+	// NotAfter 证书过期时间
+	NotAfter time.Time
+}
+
+// newTLSInfo 根据 tls.ConnectionState 构建 TLSInfo，非 TLS 连接返回 nil
+func newTLSInfo(state *tls.ConnectionState) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+	info := &TLSInfo{
+		Version:            tls.VersionName(state.Version),
+		CipherSuite:        tls.CipherSuiteName(state.CipherSuite),
+		ServerName:         state.ServerName,
+		NegotiatedProtocol: state.NegotiatedProtocol,
+		Resumed:            state.DidResume,
+	}
+	for _, cert := range state.PeerCertificates {
+		info.PeerCertificates = append(info.PeerCertificates, CertificateSummary{
+			Subject:      cert.Subject.String(),
+			Issuer:       cert.Issuer.String(),
+			DNSNames:     cert.DNSNames,
+			SerialNumber: cert.SerialNumber.String(),
+			NotBefore:    cert.NotBefore,
+			NotAfter:     cert.NotAfter,
+		})
+	}
+	return info
+}
+
+// CertificateExpiry 获取目标地址当前提供的叶子证书的过期时间，用于证书过期监控
+//
+// 发送一次 HEAD 请求并读取连接的 TLS 信息，响应状态码不影响结果。
+// 证书默认会被校验，已过期或不受信任的证书会返回错误；
+// 需要读取这类证书的过期时间时可以传入 WithTLSInsecure。
+//
+// 参数:
+//   - url: 目标 HTTPS 地址
+//   - opts: 请求选项，如 WithTimeout、WithTLSInsecure
+//
+// 返回值:
+//   - time.Time: 叶子证书的过期时间
+//   - error: 请求失败或目标不是 HTTPS 地址时的错误
+//
+// 示例:
+//
+//	expiry, err := CertificateExpiry("https://partner.example.com")
+//	if err == nil && time.Until(expiry) < 14*24*time.Hour {
+//	    alert("certificate expires soon")
+//	}
+func CertificateExpiry(url string, opts ...RequestOption) (time.Time, error) {
+	resp, err := Do(context.Background(), http.MethodHead, url, opts...)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return time.Time{}, errors.New("no tls certificate presented")
+	}
+	return resp.TLS.PeerCertificates[0].NotAfter, nil
+}
+
+// HttpsGetWithTLSState 发送 HTTPS GET 请求，并返回连接协商的 TLS 状态，会跳过 TLS 证书验证
+//
+// 用于审计连接使用的 TLS 版本和加密套件是否符合要求，只需要摘要信息时也可以使用 Response.TLS。
+// 请求地址为普通 HTTP 时不返回错误，TLS 状态为 nil。
+//
+// 参数:
+//   - url: 目标 HTTPS 请求地址
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - *tls.ConnectionState: 连接的 TLS 状态，非 TLS 连接时为 nil
+//   - error: 请求过程中的错误信息，如果请求成功则为 nil
+//
+// 注意:
+//   - 与其他 Https 开头的函数一致，此方法会跳过 TLS 证书验证，需要校验证书时请使用 Do 和 Response.TLS
+//
+// 示例:
+//
+//	resp, state, err := HttpsGetWithTLSState("https://api.example.com", nil)
+//	if err == nil && state != nil {
+//	    zap.L().Info("tls", zap.String("version", tls.VersionName(state.Version)),
+//	        zap.String("cipher", tls.CipherSuiteName(state.CipherSuite)))
+//	}
+func HttpsGetWithTLSState(url string, header map[string]string) ([]byte, *tls.ConnectionState, error) {
+	res, err := Do(context.Background(), http.MethodGet, url, WithHeaders(header), WithTLSInsecure())
+	if err != nil {
+		return nil, nil, err
+	}
+	var state *tls.ConnectionState
+	if res.RawResponse != nil && res.RawResponse.RawResponse != nil {
+		state = res.RawResponse.RawResponse.TLS
+	}
+	return res.Body, state, nil
+}
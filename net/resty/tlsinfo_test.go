@@ -0,0 +1,93 @@
+package resty_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestResponseTLSInfo(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	leaf := ts.Certificate()
+
+	resp, err := Do(context.Background(), http.MethodGet, ts.URL, WithTLSInsecure())
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp.TLS) {
+		assert.Equal(t, "TLS 1.3", resp.TLS.Version)
+		assert.NotEmpty(t, resp.TLS.CipherSuite)
+		assert.False(t, resp.TLS.Resumed)
+		if assert.Len(t, resp.TLS.PeerCertificates, 1) {
+			cert := resp.TLS.PeerCertificates[0]
+			// httptest 使用的自签名证书
+			assert.Equal(t, "O=Acme Co", cert.Subject)
+			assert.Equal(t, cert.Subject, cert.Issuer)
+			assert.Equal(t, leaf.NotAfter, cert.NotAfter)
+			assert.Contains(t, cert.DNSNames, "example.com")
+		}
+	}
+
+	// 普通 HTTP 请求没有 TLS 信息
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+	resp, err = Do(context.Background(), http.MethodGet, plain.URL)
+	assert.NoError(t, err)
+	assert.Nil(t, resp.TLS)
+}
+
+func TestCertificateExpiry(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	expiry, err := CertificateExpiry(ts.URL, WithTLSInsecure())
+	assert.NoError(t, err)
+	assert.Equal(t, ts.Certificate().NotAfter, expiry)
+
+	// 默认校验证书，自签名证书不受信任
+	_, err = CertificateExpiry(ts.URL)
+	assert.Error(t, err)
+
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+	_, err = CertificateExpiry(plain.URL)
+	assert.EqualError(t, err, "no tls certificate presented")
+}
+
+func TestHttpsGetWithTLSState(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer ts.Close()
+
+	resp, state, err := HttpsGetWithTLSState(ts.URL, map[string]string{"Authorization": "test-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+	if assert.NotNil(t, state) {
+		assert.True(t, state.HandshakeComplete)
+		assert.Equal(t, uint16(tls.VersionTLS13), state.Version)
+		assert.NotEmpty(t, tls.CipherSuiteName(state.CipherSuite))
+		if assert.NotEmpty(t, state.PeerCertificates) {
+			assert.Equal(t, ts.Certificate().Raw, state.PeerCertificates[0].Raw)
+		}
+	}
+
+	// 普通 HTTP 连接没有 TLS 状态
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("plain"))
+	}))
+	defer plain.Close()
+	resp, state, err = HttpsGetWithTLSState(plain.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("plain"), resp)
+	assert.Nil(t, state)
+}
@@ -0,0 +1,94 @@
+package resty
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// UploadFile 以 multipart/form-data 格式流式上传本地文件，并设置准确的 Content-Length
+//
+// 文件内容不会被读入内存，multipart 的头部和结尾在发送前预先生成，
+// 与文件大小相加得到请求体的总长度，因此请求不会使用分块传输编码，
+// 可以用于拒绝分块请求（返回 411 Length Required）的上传接口。
+// 上传的文件名为 filePath 的最后一个路径元素。上传不设置超时，适合大文件。
+// 与其他便捷函数一致，非 2xx 状态码不会作为错误返回。
+// 上传接口可能因认证等原因拒绝请求时，可以使用 WithExpectContinue 避免发送被拒绝的文件内容。
+//
+// 参数:
+//   - url: 目标请求地址
+//   - filePath: 本地文件路径
+//   - fieldName: multipart 表单中文件字段的名称
+//   - header: 自定义的 HTTP 请求头，Content-Type 会被 multipart 的值覆盖
+//   - opts: 上传选项，目前只有 WithExpectContinue 生效
+//
+// 返回值:
+//   - []byte: 响应体的字节数组
+//   - error: 文件打开错误或请求错误，如果成功则为 nil
+//
+// 示例:
+//
+//	resp, err := UploadFile("https://api.example.com/upload", "/tmp/report.pdf", "file", nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func UploadFile(url, filePath, fieldName string, header map[string]string, opts ...RequestOption) ([]byte, error) {
+	cfg := newRequestConfig(opts...)
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// 预先生成 multipart 的头部和结尾，文件内容在两者之间流式发送
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if _, err = writer.CreateFormFile(fieldName, filepath.Base(filePath)); err != nil {
+		return nil, err
+	}
+	head := append([]byte(nil), buf.Bytes()...)
+	buf.Reset()
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	tail := buf.Bytes()
+
+	ctx, done, err := track(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	body := io.MultiReader(bytes.NewReader(head), io.LimitReader(file, stat.Size()), bytes.NewReader(tail))
+	req, err := newStreamRequest(ctx, http.MethodPost, url, body, header)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(ContentType, writer.FormDataContentType())
+	req.ContentLength = int64(len(head)) + stat.Size() + int64(len(tail))
+
+	client := newClient(0)
+	if err = signStreamRequest(client, req); err != nil {
+		return nil, err
+	}
+	if cfg.expectContinue > 0 {
+		if err = applyExpectContinue(client, req.Header, cfg.expectContinue); err != nil {
+			return nil, err
+		}
+	}
+	res, err := client.GetClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer drainBody(res.Body)
+	return io.ReadAll(res.Body)
+}
@@ -0,0 +1,56 @@
+package resty_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestUploadFile(t *testing.T) {
+	content := strings.Repeat("upload-content\n", 1000)
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 拒绝分块传输的上传接口
+		if r.ContentLength < 0 || len(r.TransferEncoding) > 0 {
+			w.WriteHeader(http.StatusLengthRequired)
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+
+		file, fileHeader, err := r.FormFile("file")
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(data))
+		assert.Equal(t, "report.txt", fileHeader.Filename)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	resp, err := UploadFile(ts.URL, path, "file", map[string]string{"Authorization": "test-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"status":"ok"}`), resp)
+}
+
+func TestUploadFileNotFound(t *testing.T) {
+	_, err := UploadFile("http://127.0.0.1:0", filepath.Join(t.TempDir(), "missing"), "file", nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,74 @@
+package resty
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// BuildURL 在 base 之后追加路径段并合并查询参数
+//
+// 每个路径段会先去除首尾的 "/"，再按路径段转义（包括段内的 "/"、"?"、"#" 和空格），
+// 空路径段被忽略，base 与路径段之间、路径段之间只保留一个 "/"。
+// query 与 base 中已有的查询参数合并，同名参数以 query 中的值为准。base 中的 fragment 保留。
+//
+// 参数:
+//   - base: 带 scheme 和 host 的基础地址，可以包含路径和查询参数
+//   - segments: 追加的路径段，为未转义的原始值
+//   - query: 追加的查询参数，可以为 nil
+//
+// 返回值:
+//   - string: 拼接后的地址
+//   - error: base 无法解析或缺少 scheme、host 时返回错误
+//
+// 示例:
+//
+//	u, err := BuildURL("https://api.example.com/v1/", []string{"users", "a b/c"}, url.Values{"page": {"2"}})
+//	// https://api.example.com/v1/users/a%20b%2Fc?page=2
+func BuildURL(base string, segments []string, query url.Values) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base url %q: %w", base, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid base url %q: missing scheme or host", base)
+	}
+
+	escaped := u.EscapedPath()
+	for _, segment := range segments {
+		if segment = strings.Trim(segment, "/"); segment != "" {
+			escaped = joinSlash(escaped, url.PathEscape(segment))
+		}
+	}
+	if u.Path, err = url.PathUnescape(escaped); err != nil {
+		return "", err
+	}
+	u.RawPath = escaped
+
+	if len(query) > 0 {
+		values := u.Query()
+		for k, v := range query {
+			values[k] = v
+		}
+		u.RawQuery = values.Encode()
+	}
+	return u.String(), nil
+}
+
+// JoinPath 在 base 之后追加路径段，规则与 BuildURL 相同
+//
+// 示例:
+//
+//	u, err := JoinPath("https://api.example.com/v1", "users", "42")
+//	// https://api.example.com/v1/users/42
+func JoinPath(base string, segments ...string) (string, error) {
+	return BuildURL(base, segments, nil)
+}
+
+// joinSlash 拼接两段路径，保证两者之间只有一个 "/"
+func joinSlash(base, path string) string {
+	if path == "" {
+		return base
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
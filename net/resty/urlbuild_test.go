@@ -0,0 +1,55 @@
+package resty_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+)
+
+func TestBuildURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		segments []string
+		query    url.Values
+		expected string
+	}{
+		{"simple", "https://api.example.com", []string{"v1", "users"}, nil, "https://api.example.com/v1/users"},
+		{"trailing slash", "https://api.example.com/", []string{"/v1/", "users/"}, nil, "https://api.example.com/v1/users"},
+		{"base path", "https://api.example.com/api/", []string{"v1"}, nil, "https://api.example.com/api/v1"},
+		{"empty segments", "https://api.example.com", []string{"", "v1", "/", "users"}, nil, "https://api.example.com/v1/users"},
+		{"no segments", "https://api.example.com/v1/", nil, nil, "https://api.example.com/v1/"},
+		{"escaping", "https://api.example.com", []string{"files", "a b/c?d#e"}, nil, "https://api.example.com/files/a%20b%2Fc%3Fd%23e"},
+		{"unicode", "https://api.example.com", []string{"users", "张三"}, nil, "https://api.example.com/users/%E5%BC%A0%E4%B8%89"},
+		{"escaped base path", "https://api.example.com/a%2Fb", []string{"c"}, nil, "https://api.example.com/a%2Fb/c"},
+		{"query", "https://api.example.com", []string{"users"}, url.Values{"page": {"2"}, "tag": {"a", "b"}}, "https://api.example.com/users?page=2&tag=a&tag=b"},
+		{"merge query", "https://api.example.com/users?page=1&sort=name", nil, url.Values{"page": {"2"}, "q": {"x y"}}, "https://api.example.com/users?page=2&q=x+y&sort=name"},
+		{"keep query without merge", "https://api.example.com/search?q=a%26b", []string{"v2"}, nil, "https://api.example.com/search/v2?q=a%26b"},
+		{"fragment", "https://api.example.com/docs#top", []string{"intro"}, nil, "https://api.example.com/docs/intro#top"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := BuildURL(tt.base, tt.segments, tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestBuildURLInvalidBase(t *testing.T) {
+	for _, base := range []string{"", "/v1/users", "api.example.com/v1", "http://[::1", "https://"} {
+		_, err := BuildURL(base, []string{"users"}, nil)
+		assert.Error(t, err, base)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	actual, err := JoinPath("https://api.example.com/v1/", "/users/", "42")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/v1/users/42", actual)
+
+	_, err = JoinPath("not a url", "users")
+	assert.Error(t, err)
+}
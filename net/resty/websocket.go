@@ -0,0 +1,54 @@
+package resty
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/yocover/global-toolkit/net/rpc"
+)
+
+// DialWebSocket 发起 WebSocket 握手，请求头与 HTTP 便捷函数的约定一致
+//
+// 握手请求携带上下文中的 RPC headers 和 header 中的自定义请求头，同名时 header 优先。
+// 握手超时时间为 DefaultTimeout，ctx 取消时握手中止。
+//
+// 参数:
+//   - ctx: 握手的上下文
+//   - url: WebSocket 地址，如 ws://example.com/ws 或 wss://example.com/ws
+//   - header: 自定义的 HTTP 请求头
+//
+// 返回值:
+//   - *websocket.Conn: 建立的连接，调用方负责关闭
+//   - *http.Response: 握手响应，握手失败时可用于查看状态码和响应头
+//   - error: 握手失败时的错误，服务端未返回 101 时包含实际状态码
+//
+// 示例:
+//
+//	headers := map[string]string{"Authorization": "Bearer token123"}
+//	conn, _, err := DialWebSocket(ctx, "wss://api.example.com/stream", headers)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer conn.Close()
+func DialWebSocket(ctx context.Context, url string, header map[string]string) (*websocket.Conn, *http.Response, error) {
+	requestHeader := http.Header{}
+	for k, v := range rpc.GetRPCHeaders(ctx) {
+		requestHeader.Set(k, v)
+	}
+	for k, v := range header {
+		requestHeader.Set(k, v)
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: DefaultTimeout * time.Second,
+	}
+	conn, res, err := dialer.DialContext(ctx, url, requestHeader)
+	if err != nil && res != nil && res.StatusCode != http.StatusSwitchingProtocols {
+		return nil, res, fmt.Errorf("websocket handshake failed with status %d: %w", res.StatusCode, err)
+	}
+	return conn, res, err
+}
@@ -0,0 +1,73 @@
+package resty_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	. "github.com/yocover/global-toolkit/net/resty"
+	"github.com/yocover/global-toolkit/net/rpc"
+)
+
+func TestDialWebSocket(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "trace-1", r.Header.Get("X-Trace-Id"))
+		// 同名时自定义请求头优先
+		assert.Equal(t, "from-header", r.Header.Get("X-Tenant"))
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.WriteMessage(messageType, data)
+	}))
+	defer ts.Close()
+
+	ctx := rpc.SetRPCHeaders(context.Background(), map[string]string{
+		"x-trace-id": "trace-1",
+		"x-tenant":   "from-rpc",
+	})
+	headers := map[string]string{
+		"Authorization": "test-token",
+		"X-Tenant":      "from-header",
+	}
+	conn, res, err := DialWebSocket(ctx, "ws"+strings.TrimPrefix(ts.URL, "http"), headers)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("ping")))
+	_, data, err := conn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(data))
+}
+
+func TestDialWebSocketRejected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	conn, res, err := DialWebSocket(context.Background(), "ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	assert.Nil(t, conn)
+	assert.True(t, errors.Is(err, websocket.ErrBadHandshake))
+	assert.EqualError(t, err, "websocket handshake failed with status 401: websocket: bad handshake")
+	if assert.NotNil(t, res) {
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	}
+}
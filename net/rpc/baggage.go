@@ -0,0 +1,176 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// BaggageHeader W3C Baggage 的 header 名称
+const BaggageHeader = "baggage"
+
+// W3C Baggage 规范中的大小限制
+const (
+	// MaxBaggageMembers baggage 中最多包含的条目数
+	MaxBaggageMembers = 64
+	// MaxBaggageBytes baggage header 的最大字节数
+	MaxBaggageBytes = 8192
+)
+
+// Baggage 相关的错误
+var (
+	// ErrInvalidBaggageKey baggage 的键名不是合法的 HTTP token
+	ErrInvalidBaggageKey = errors.New("invalid baggage key")
+	// ErrBaggageTooLarge 设置后的 baggage 超过了条目数或字节数限制
+	ErrBaggageTooLarge = errors.New("baggage too large")
+)
+
+// baggageMember baggage 中的单个条目
+type baggageMember struct {
+	key   string
+	value string
+	// properties 条目的属性，原样保留（包含开头的 ";"）
+	properties string
+}
+
+// SetBaggage 在上下文的 baggage header 中设置一个条目
+//
+// 所有条目按 W3C Baggage 规范编码在同一个 baggage header 中，值会进行百分号编码，
+// 同名条目会被覆盖，其他条目及其属性保持不变。
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - key: 条目的键名，必须是合法的 HTTP token
+//   - value: 条目的值，可以包含任意字符
+//
+// 返回值:
+//   - context.Context: 新的上下文；出错时返回原始上下文
+//   - error: 键名不合法时为 ErrInvalidBaggageKey，超过规范限制时为 ErrBaggageTooLarge
+//
+// 示例:
+//
+//	ctx, err := SetBaggage(ctx, "tenant", "acme corp")
+//	// baggage: tenant=acme%20corp
+func SetBaggage(ctx context.Context, key, value string) (context.Context, error) {
+	if !isToken(key) {
+		return ctx, fmt.Errorf("%w: %q", ErrInvalidBaggageKey, key)
+	}
+
+	header, _ := GetRPCHeader(ctx, BaggageHeader)
+	members := parseBaggage(header)
+
+	replaced := false
+	for i := range members {
+		if members[i].key == key {
+			members[i].value = value
+			replaced = true
+		}
+	}
+	if !replaced {
+		members = append(members, baggageMember{key: key, value: value})
+	}
+
+	if len(members) > MaxBaggageMembers {
+		return ctx, fmt.Errorf("%w: more than %d members", ErrBaggageTooLarge, MaxBaggageMembers)
+	}
+	encoded := encodeBaggage(members)
+	if len(encoded) > MaxBaggageBytes {
+		return ctx, fmt.Errorf("%w: more than %d bytes", ErrBaggageTooLarge, MaxBaggageBytes)
+	}
+	return SetRPCHeader(ctx, BaggageHeader, encoded), nil
+}
+
+// GetBaggage 从上下文的 baggage header 中获取一个条目的值
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: 条目的键名
+//
+// 返回值:
+//   - string: 解码后的值
+//   - bool: 是否存在该条目
+func GetBaggage(ctx context.Context, key string) (string, bool) {
+	header, ok := GetRPCHeader(ctx, BaggageHeader)
+	if !ok {
+		return "", false
+	}
+	for _, member := range parseBaggage(header) {
+		if member.key == key {
+			return member.value, true
+		}
+	}
+	return "", false
+}
+
+// parseBaggage 解析 baggage header，格式不合法的条目会被忽略
+func parseBaggage(header string) []baggageMember {
+	var members []baggageMember
+	for _, item := range strings.Split(header, ",") {
+		item, properties, _ := strings.Cut(item, ";")
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isToken(key) {
+			continue
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		if properties != "" {
+			properties = ";" + strings.TrimSpace(properties)
+		}
+		members = append(members, baggageMember{key: key, value: decoded, properties: properties})
+	}
+	return members
+}
+
+// encodeBaggage 将条目编码为 baggage header
+func encodeBaggage(members []baggageMember) string {
+	var b strings.Builder
+	for i, member := range members {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(member.key)
+		b.WriteByte('=')
+		b.WriteString(escapeBaggageValue(member.value))
+		b.WriteString(member.properties)
+	}
+	return b.String()
+}
+
+// escapeBaggageValue 对 baggage-octet 之外的字节以及 "%" 进行百分号编码
+func escapeBaggageValue(value string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c > 0x20 && c < 0x7f && c != '"' && c != ',' && c != ';' && c != '\\' && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+// isToken 判断字符串是否为 RFC 7230 定义的 token
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaggage(t *testing.T) {
+	ctx, err := SetBaggage(context.Background(), "tenant", "acme corp")
+	assert.NoError(t, err)
+	ctx, err = SetBaggage(ctx, "user", "a,b;c=d%")
+	assert.NoError(t, err)
+
+	// 所有条目编码在同一个 header 中
+	header, ok := GetRPCHeader(ctx, BaggageHeader)
+	assert.True(t, ok)
+	assert.Equal(t, "tenant=acme%20corp,user=a%2Cb%3Bc=d%25", header)
+
+	value, ok := GetBaggage(ctx, "user")
+	assert.True(t, ok)
+	assert.Equal(t, "a,b;c=d%", value)
+
+	// 覆盖已有条目
+	ctx, err = SetBaggage(ctx, "tenant", "其他")
+	assert.NoError(t, err)
+	value, _ = GetBaggage(ctx, "tenant")
+	assert.Equal(t, "其他", value)
+
+	_, ok = GetBaggage(ctx, "missing")
+	assert.False(t, ok)
+	_, ok = GetBaggage(context.Background(), "tenant")
+	assert.False(t, ok)
+}
+
+func TestBaggageParse(t *testing.T) {
+	// 从上游收到的 baggage，包含空白、属性和不合法的条目
+	ctx := SetRPCHeader(context.Background(), BaggageHeader,
+		" userId = alice%40example.com , serverNode=DF%2028;prop=1, bad entry,=novalue,isProduction=false")
+
+	tests := []struct {
+		key      string
+		expected string
+		found    bool
+	}{
+		{key: "userId", expected: "alice@example.com", found: true},
+		{key: "serverNode", expected: "DF 28", found: true},
+		{key: "isProduction", expected: "false", found: true},
+		{key: "bad entry", found: false},
+	}
+	for _, tt := range tests {
+		value, ok := GetBaggage(ctx, tt.key)
+		assert.Equal(t, tt.found, ok, tt.key)
+		assert.Equal(t, tt.expected, value, tt.key)
+	}
+
+	// 设置新条目时保留已有条目的属性
+	ctx, err := SetBaggage(ctx, "userId", "bob")
+	assert.NoError(t, err)
+	header, _ := GetRPCHeader(ctx, BaggageHeader)
+	assert.Equal(t, "userId=bob,serverNode=DF%2028;prop=1,isProduction=false", header)
+}
+
+func TestBaggageLimits(t *testing.T) {
+	ctx := context.Background()
+
+	// 不合法的键名
+	newCtx, err := SetBaggage(ctx, "bad key", "value")
+	assert.ErrorIs(t, err, ErrInvalidBaggageKey)
+	assert.Equal(t, ctx, newCtx)
+
+	// 条目数限制
+	for i := 0; i < MaxBaggageMembers; i++ {
+		ctx, err = SetBaggage(ctx, "k"+strings.Repeat("x", i), "v")
+		assert.NoError(t, err)
+	}
+	_, err = SetBaggage(ctx, "overflow", "v")
+	assert.ErrorIs(t, err, ErrBaggageTooLarge)
+	// 覆盖已有条目不增加条目数
+	_, err = SetBaggage(ctx, "k", "v2")
+	assert.NoError(t, err)
+
+	// 字节数限制
+	_, err = SetBaggage(context.Background(), "big", strings.Repeat("a", MaxBaggageBytes))
+	assert.ErrorIs(t, err, ErrBaggageTooLarge)
+}
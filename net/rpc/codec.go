@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidHeadersJSON UnmarshalHeaders 的输入不是 JSON 对象，或对象的值不是字符串或非空的字符串数组
+var ErrInvalidHeadersJSON = errors.New("invalid headers json")
+
+// MarshalHeaders 将上下文中的所有 headers 序列化为 JSON 对象
+//
+// 适用于通过消息队列等异步通道传递调用上下文，例如作为消息属性发送，
+// 消费端使用 UnmarshalHeaders 恢复。键名按字典序输出，相同的 headers 总是得到相同的结果。
+// 只有一个值的 header 编码为字符串，有多个值的 header 编码为按添加顺序排列的字符串数组。
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - []byte: JSON 对象，没有 header 时为 {}
+//   - error: 序列化失败时的错误
+//
+// 示例:
+//
+//	data, err := MarshalHeaders(ctx)
+//	// {"x-forwarded-for":["10.0.0.1","10.0.0.2"],"x-request-id":"req-1"}
+//	msg.Attributes["rpc-headers"] = string(data)
+func MarshalHeaders(ctx context.Context) ([]byte, error) {
+	current := headersFrom(ctx)
+	headers := make(map[string]interface{}, len(current))
+	for key, values := range current {
+		if len(values) == 1 {
+			headers[key] = values[0]
+		} else {
+			headers[key] = values
+		}
+	}
+	return json.Marshal(headers)
+}
+
+// UnmarshalHeaders 解析 MarshalHeaders 生成的 JSON，并将其中的 headers 设置到上下文中
+//
+// 与 SetRPCHeaders 一致，同名 header 在上下文中已有的所有值都会被替换。
+// data 为空或为 JSON null 时直接返回 ctx。
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - data: JSON 对象，值必须是字符串或非空的字符串数组
+//
+// 返回值:
+//   - context.Context: 包含解析出的 headers 的上下文；出错时返回原始上下文
+//   - error: data 不是合法的 headers JSON 时为 ErrInvalidHeadersJSON
+//
+// 示例:
+//
+//	ctx, err := UnmarshalHeaders(context.Background(), []byte(msg.Attributes["rpc-headers"]))
+func UnmarshalHeaders(ctx context.Context, data []byte) (context.Context, error) {
+	if len(data) == 0 {
+		return ctx, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ctx, fmt.Errorf("%w: %v", ErrInvalidHeadersJSON, err)
+	}
+	if len(raw) == 0 {
+		return ctx, nil
+	}
+
+	headers := copyHeaders(headersFrom(ctx), len(raw))
+	for key, value := range raw {
+		// null 可以解析为任意类型的零值，需要单独排除
+		if string(value) == "null" {
+			return ctx, fmt.Errorf("%w: value of %q is null", ErrInvalidHeadersJSON, key)
+		}
+		var single string
+		if err := json.Unmarshal(value, &single); err == nil {
+			headers[key] = []string{single}
+			continue
+		}
+		var values []string
+		if err := json.Unmarshal(value, &values); err != nil || len(values) == 0 {
+			return ctx, fmt.Errorf("%w: value of %q must be a string or a non-empty string array", ErrInvalidHeadersJSON, key)
+		}
+		headers[key] = values
+	}
+	return context.WithValue(ctx, headersKey{}, headers), nil
+}
@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalHeaders(t *testing.T) {
+	ctx := SetRPCHeaders(context.Background(), map[string]string{
+		"x-tenant":     "acme",
+		"x-request-id": "req-1",
+		"x-unicode":    "租户 \"a\"\n",
+		"x-empty":      "",
+	})
+
+	data, err := MarshalHeaders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"x-empty":"","x-request-id":"req-1","x-tenant":"acme","x-unicode":"租户 \"a\"\n"}`, string(data))
+
+	// 往返后保留所有键值
+	restored, err := UnmarshalHeaders(context.Background(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, GetRPCHeaders(ctx), GetRPCHeaders(restored))
+
+	// 没有 header 时输出空对象
+	data, err = MarshalHeaders(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+	data, err = MarshalHeaders(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+}
+
+func TestUnmarshalHeaders(t *testing.T) {
+	base := SetRPCHeaders(context.Background(), map[string]string{"x-a": "1", "x-b": "2"})
+
+	// 同名的 header 被覆盖，其他 header 保留
+	ctx, err := UnmarshalHeaders(base, []byte(`{"x-b":"3","x-c":"4"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"x-a": "1", "x-b": "3", "x-c": "4"}, GetRPCHeaders(ctx))
+
+	// 空输入和 null 不修改上下文
+	for _, data := range []string{"", "null", "{}"} {
+		ctx, err = UnmarshalHeaders(base, []byte(data))
+		assert.NoError(t, err)
+		assert.Equal(t, base, ctx)
+	}
+
+	// 非法输入返回原始上下文
+	for _, data := range []string{`[]`, `{"x-a":1}`, `{"x-a":`, `"x-a"`, `{"x-a":null}`, `{"x-a":[]}`, `{"x-a":["1",2]}`} {
+		ctx, err = UnmarshalHeaders(base, []byte(data))
+		assert.ErrorIs(t, err, ErrInvalidHeadersJSON, data)
+		assert.Equal(t, base, ctx)
+	}
+}
+
+func TestMarshalHeadersMultiValue(t *testing.T) {
+	ctx := SetRPCHeader(context.Background(), "x-request-id", "req-1")
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.1")
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.2")
+
+	data, err := MarshalHeaders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"x-forwarded-for":["10.0.0.1","10.0.0.2"],"x-request-id":"req-1"}`, string(data))
+
+	// 多个值往返后保持顺序，并替换已有的值
+	base := AddRPCHeader(context.Background(), "x-forwarded-for", "192.168.0.1")
+	restored, err := UnmarshalHeaders(base, data)
+	assert.NoError(t, err)
+	assert.Equal(t, GetRPCHeadersMulti(ctx), GetRPCHeadersMulti(restored))
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+)
+
+// DedupKeyHeader 去重键的 header 名称
+//
+// 去重键以普通 RPC header 的形式存储在上下文中，会随其他 headers 一起透传到下游调用，
+// 经过 MarshalHeaders 和 UnmarshalHeaders 往返后保持不变，HTTPMiddleware 默认提取该请求头。
+const DedupKeyHeader = "x-dedup-key"
+
+// SetDedupKey 在上下文中设置去重键，用于跨服务对消息处理去重
+//
+// 同一条消息在重试、重复投递时应使用相同的去重键，下游据此保证只处理一次。
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - key: 去重键，忽略首尾空白，为空时删除已有的去重键
+//
+// 返回值:
+//   - context.Context: 新的上下文，包含去重键 header
+//
+// 示例:
+//
+//	ctx = SetDedupKey(ctx, "order-created:"+orderID)
+//	data, _ := MarshalHeaders(ctx) // 随消息一起写入队列
+func SetDedupKey(ctx context.Context, key string) context.Context {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return DeleteRPCHeader(ctx, DedupKeyHeader)
+	}
+	return SetRPCHeader(ctx, DedupKeyHeader, key)
+}
+
+// GetDedupKey 从上下文中获取去重键
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - string: 去重键，忽略首尾空白
+//   - bool: 是否设置了非空的去重键
+//
+// 示例:
+//
+//	if key, ok := GetDedupKey(ctx); ok && processed(key) {
+//	    return nil // 已处理过，直接确认
+//	}
+func GetDedupKey(ctx context.Context) (string, bool) {
+	value, ok := GetRPCHeader(ctx, DedupKeyHeader)
+	value = strings.TrimSpace(value)
+	return value, ok && value != ""
+}
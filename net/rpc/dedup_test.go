@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestDedupKey(t *testing.T) {
+	// 未设置
+	_, ok := GetDedupKey(context.Background())
+	assert.False(t, ok)
+
+	ctx := SetDedupKey(context.Background(), " order-created:42 ")
+	key, ok := GetDedupKey(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "order-created:42", key)
+	assert.Equal(t, map[string]string{DedupKeyHeader: "order-created:42"}, GetRPCHeaders(ctx))
+
+	// 空值删除已有的去重键
+	ctx = SetDedupKey(ctx, "  ")
+	_, ok = GetDedupKey(ctx)
+	assert.False(t, ok)
+	assert.False(t, HasAnyRPCHeaders(ctx))
+
+	// 对端传入的空值视为未设置
+	ctx = SetRPCHeader(context.Background(), DedupKeyHeader, "")
+	_, ok = GetDedupKey(ctx)
+	assert.False(t, ok)
+}
+
+func TestDedupKeyJSONRoundTrip(t *testing.T) {
+	ctx := SetDedupKey(context.Background(), "消息:42 \"a\"")
+	data, err := MarshalHeaders(ctx)
+	assert.NoError(t, err)
+
+	restored, err := UnmarshalHeaders(context.Background(), data)
+	assert.NoError(t, err)
+	key, ok := GetDedupKey(restored)
+	assert.True(t, ok)
+	assert.Equal(t, "消息:42 \"a\"", key)
+}
+
+func TestDedupKeyBridges(t *testing.T) {
+	// HTTPMiddleware 默认提取
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-Dedup-Key", "msg-1")
+	var key string
+	HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, _ = GetDedupKey(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "msg-1", key)
+
+	// 注入到 HTTP 请求头
+	ctx := SetDedupKey(context.Background(), "msg-2")
+	h := http.Header{}
+	InjectHTTPHeaders(ctx, h)
+	assert.Equal(t, "msg-2", h.Get("X-Dedup-Key"))
+
+	// gRPC metadata 往返
+	md := ToGRPCMetadata(ctx)
+	assert.Equal(t, []string{"msg-2"}, md.Get("x-dedup-key"))
+	key, _ = GetDedupKey(NewContextFromGRPCMetadata(context.Background(), metadata.Pairs("x-dedup-key", "msg-3")))
+	assert.Equal(t, "msg-3", key)
+}
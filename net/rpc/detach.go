@@ -0,0 +1,50 @@
+package rpc
+
+import "context"
+
+// DetachRPCHeaders 返回一个只携带 ctx 中 RPC headers 的新上下文，不继承 ctx 的取消、截止时间和其他值
+//
+// 用于在处理请求时启动后台 goroutine：请求结束后请求上下文会被取消，
+// 直接使用 context.Background() 又会丢失 request-id 等 headers。
+// 返回的上下文保存的是调用时 headers 的快照，之后在任意一方设置或删除 header 都不会影响另一方。
+//
+// 参数:
+//   - ctx: 原始上下文，通常是请求上下文
+//
+// 返回值:
+//   - context.Context: 以 context.Background() 为父上下文、包含 ctx 中所有 headers 的新上下文
+//
+// 示例:
+//
+//	go func(ctx context.Context) {
+//	    // 请求结束后仍可以继续执行，并携带原请求的 headers
+//	    _ = sendAuditLog(ctx, record)
+//	}(DetachRPCHeaders(r.Context()))
+func DetachRPCHeaders(ctx context.Context) context.Context {
+	return DetachRPCHeadersWithParent(ctx, context.Background())
+}
+
+// DetachRPCHeadersWithParent 将 ctx 中的 RPC headers 复制到 parent 上，不继承 ctx 的取消、截止时间和其他值
+//
+// 与 DetachRPCHeaders 相同，但可以指定父上下文，例如为后台任务设置独立的超时时间，
+// 或挂在服务的生命周期上下文上以便在关闭时统一取消。
+// parent 中已有的 headers 会被保留，与 ctx 中同名的 header 被 ctx 中的值替换。
+//
+// 参数:
+//   - ctx: 提供 headers 的上下文
+//   - parent: 新的父上下文
+//
+// 返回值:
+//   - context.Context: 继承 parent、包含 ctx 中所有 headers 的新上下文，ctx 中没有 headers 时直接返回 parent
+//
+// 示例:
+//
+//	bgCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	go func() {
+//	    defer cancel()
+//	    _ = reindex(DetachRPCHeadersWithParent(r.Context(), bgCtx))
+//	}()
+func DetachRPCHeadersWithParent(ctx, parent context.Context) context.Context {
+	// headers 写时复制、从不原地修改，因此直接共享即为快照
+	return mergeHeaders(parent, headersFrom(ctx))
+}
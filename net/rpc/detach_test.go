@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetachRPCHeaders(t *testing.T) {
+	type ctxKey struct{}
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "value"), time.Minute)
+	ctx = SetRPCHeader(ctx, "x-request-id", "req-1")
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.1")
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.2")
+
+	detached := DetachRPCHeaders(ctx)
+	cancel()
+
+	// 原上下文取消后，新上下文不受影响，headers 仍然可用
+	assert.Error(t, ctx.Err())
+	assert.NoError(t, detached.Err())
+	_, hasDeadline := detached.Deadline()
+	assert.False(t, hasDeadline)
+	assert.Nil(t, detached.Done())
+	assert.Equal(t, map[string][]string{
+		"x-request-id":    {"req-1"},
+		"x-forwarded-for": {"10.0.0.1", "10.0.0.2"},
+	}, GetRPCHeadersMulti(detached))
+
+	// 不继承其他值
+	assert.Nil(t, detached.Value(ctxKey{}))
+
+	// 快照互不影响
+	ctx = SetRPCHeader(ctx, "x-request-id", "changed")
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.3")
+	detached2 := DeleteRPCHeader(detached, "x-forwarded-for")
+	detached2 = SetRPCHeader(detached2, "x-tenant", "acme")
+	assert.Equal(t, map[string][]string{
+		"x-request-id":    {"req-1"},
+		"x-forwarded-for": {"10.0.0.1", "10.0.0.2"},
+	}, GetRPCHeadersMulti(detached))
+	assert.Equal(t, map[string][]string{
+		"x-request-id":    {"changed"},
+		"x-forwarded-for": {"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+	}, GetRPCHeadersMulti(ctx))
+
+	// 没有 headers 时返回空的上下文
+	assert.False(t, HasAnyRPCHeaders(DetachRPCHeaders(context.Background())))
+}
+
+func TestDetachRPCHeadersWithParent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = SetRPCHeaders(ctx, map[string]string{"x-request-id": "req-1", "x-tenant": "acme"})
+
+	parent, parentCancel := context.WithTimeout(context.Background(), time.Minute)
+	defer parentCancel()
+	parent = SetRPCHeaders(parent, map[string]string{"x-tenant": "parent", "x-worker": "indexer"})
+
+	detached := DetachRPCHeadersWithParent(ctx, parent)
+	cancel()
+
+	// 继承新的父上下文，不受原上下文取消的影响
+	assert.NoError(t, detached.Err())
+	_, hasDeadline := detached.Deadline()
+	assert.True(t, hasDeadline)
+
+	// 保留父上下文的 headers，同名的被替换
+	assert.Equal(t, map[string]string{
+		"x-request-id": "req-1",
+		"x-tenant":     "acme",
+		"x-worker":     "indexer",
+	}, GetRPCHeaders(detached))
+	assert.Equal(t, map[string]string{"x-tenant": "parent", "x-worker": "indexer"}, GetRPCHeaders(parent))
+
+	// 父上下文取消时新上下文随之取消
+	parentCancel()
+	assert.ErrorIs(t, detached.Err(), context.Canceled)
+
+	// ctx 中没有 headers 时直接返回 parent
+	assert.Equal(t, parent, DetachRPCHeadersWithParent(context.Background(), parent))
+}
@@ -0,0 +1,37 @@
+package rpc
+
+import "context"
+
+// ExperimentBucketHeader A/B 实验分桶的 header 名称
+//
+// 分桶以普通 RPC header 的形式存储在上下文中，
+// 因此会随其他 headers 一起被 GetRPCHeaders 导出并透传到下游调用，HTTPMiddleware 默认提取该请求头。
+const ExperimentBucketHeader = "x-experiment-bucket"
+
+// SetExperimentBucket 在上下文中设置 A/B 实验分桶
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - bucket: 实验分桶标识
+//
+// 返回值:
+//   - context.Context: 新的上下文，包含实验分桶 header
+//
+// 示例:
+//
+//	ctx = SetExperimentBucket(ctx, "checkout-v2")
+func SetExperimentBucket(ctx context.Context, bucket string) context.Context {
+	return SetRPCHeader(ctx, ExperimentBucketHeader, bucket)
+}
+
+// GetExperimentBucket 从上下文中获取 A/B 实验分桶
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - string: 实验分桶标识
+//   - bool: 是否设置了实验分桶
+func GetExperimentBucket(ctx context.Context) (string, bool) {
+	return GetRPCHeader(ctx, ExperimentBucketHeader)
+}
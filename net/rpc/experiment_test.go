@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExperimentBucket(t *testing.T) {
+	// 未设置
+	bucket, ok := GetExperimentBucket(context.Background())
+	assert.False(t, ok, "bucket should not be set")
+	assert.Empty(t, bucket)
+
+	ctx := SetExperimentBucket(context.Background(), "checkout-v2")
+	bucket, ok = GetExperimentBucket(ctx)
+	assert.True(t, ok, "bucket should be set")
+	assert.Equal(t, "checkout-v2", bucket)
+
+	// 作为普通 header 随其他 headers 一起导出
+	ctx = SetRPCHeader(ctx, "x-user-id", "42")
+	assert.Equal(t, map[string]string{
+		ExperimentBucketHeader: "checkout-v2",
+		"x-user-id":            "42",
+	}, GetRPCHeaders(ctx))
+
+	// 覆盖
+	ctx = SetExperimentBucket(ctx, "control")
+	bucket, _ = GetExperimentBucket(ctx)
+	assert.Equal(t, "control", bucket)
+}
+
+func TestExperimentBucketHTTPRoundTrip(t *testing.T) {
+	// 上游将分桶注入请求头，下游的 HTTPMiddleware 默认提取
+	ctx := SetExperimentBucket(context.Background(), "checkout-v2")
+	ctx = SetRPCHeader(ctx, "x-user-id", "42")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	InjectHTTPHeaders(ctx, req.Header)
+	assert.Equal(t, "checkout-v2", req.Header.Get("X-Experiment-Bucket"))
+
+	var bucket string
+	var ok bool
+	HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket, ok = GetExperimentBucket(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, ok, "bucket should be propagated")
+	assert.Equal(t, "checkout-v2", bucket)
+}
@@ -0,0 +1,179 @@
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// binarySuffix gRPC 中二进制 metadata 键名的后缀
+const binarySuffix = "-bin"
+
+// ToGRPCMetadata 将上下文中的 headers 转换为 gRPC metadata
+//
+// 转换遵循 gRPC 的 metadata 规则：
+//   - 键名转换为小写，只能包含数字、小写字母和 "-"、"_"、"."，不符合的 header 会被跳过
+//   - 以 "-bin" 结尾的 header 的值按 base64 解码为二进制，解码失败的 header 会被跳过
+//   - 其他 header 的值只能包含可打印的 ASCII 字符，否则跳过该 header
+//   - 每个 header 的所有值按添加顺序保留；大小写不同的同名 header 合并为一个键，按键名排序依次追加
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - metadata.MD: 转换后的 metadata，没有可转换的 header 时为空 MD
+//
+// 示例:
+//
+//	md := ToGRPCMetadata(ctx)
+//	ctx = metadata.NewOutgoingContext(ctx, md)
+func ToGRPCMetadata(ctx context.Context) metadata.MD {
+	return toGRPCMetadata(headersFrom(ctx))
+}
+
+// NewContextFromGRPCMetadata 将 gRPC metadata 中的所有键值设置到上下文的 headers 中
+//
+// 与 SetRPCHeaders 一致，同名 header 在上下文中已有的所有值都会被替换，每个键的多个值按顺序保留。
+// 以 "-bin" 结尾的键的值按标准 base64（带填充）编码后保存，与 ToGRPCMetadata 对应，
+// 合法的 headers 经过 ToGRPCMetadata 和本函数往返后保持不变。
+// 不符合 gRPC 键名规则的键（如 ":authority" 等伪头部）会被跳过。
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - md: gRPC metadata，如 metadata.FromIncomingContext 的结果
+//
+// 返回值:
+//   - context.Context: 包含 metadata 中 headers 的上下文，md 为空时直接返回 ctx
+//
+// 示例:
+//
+//	md, _ := metadata.FromIncomingContext(ctx)
+//	ctx = NewContextFromGRPCMetadata(ctx, md)
+func NewContextFromGRPCMetadata(ctx context.Context, md metadata.MD) context.Context {
+	return mergeHeaders(ctx, fromGRPCMetadata(md))
+}
+
+// OutgoingGRPCContext 将上下文中的 headers 追加到 gRPC 的 outgoing metadata 中
+//
+// 与 metadata.AppendToOutgoingContext 一致，上下文中已有的 outgoing metadata 会被保留，
+// headers 中的值追加在其后。转换规则见 ToGRPCMetadata。
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - context.Context: 携带 outgoing metadata 的上下文，可直接用于发起 gRPC 调用
+//
+// 示例:
+//
+//	resp, err := client.GetUser(OutgoingGRPCContext(ctx), req)
+func OutgoingGRPCContext(ctx context.Context) context.Context {
+	return appendOutgoingMetadata(ctx, ToGRPCMetadata(ctx))
+}
+
+// appendOutgoingMetadata 将 md 追加到上下文的 outgoing metadata 中，md 为空时直接返回 ctx
+func appendOutgoingMetadata(ctx context.Context, md metadata.MD) context.Context {
+	if len(md) == 0 {
+		return ctx
+	}
+	if existing, ok := metadata.FromOutgoingContext(ctx); ok {
+		md = metadata.Join(existing, md)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// toGRPCMetadata 按 gRPC 规则将 headers 转换为 metadata
+func toGRPCMetadata(headers map[string][]string) metadata.MD {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	md := metadata.MD{}
+	for _, key := range keys {
+		mdKey := strings.ToLower(key)
+		values, ok := toGRPCValues(mdKey, headers[key])
+		if !ok {
+			continue
+		}
+		md[mdKey] = append(md[mdKey], values...)
+	}
+	return md
+}
+
+// fromGRPCMetadata 将 gRPC metadata 转换为 headers，跳过不合法的键名和没有值的键
+func fromGRPCMetadata(md metadata.MD) map[string][]string {
+	headers := make(map[string][]string, len(md))
+	for key, values := range md {
+		key = strings.ToLower(key)
+		if len(values) == 0 || !isValidGRPCKey(key) {
+			continue
+		}
+		converted := make([]string, len(values))
+		for i, value := range values {
+			if strings.HasSuffix(key, binarySuffix) {
+				value = base64.StdEncoding.EncodeToString([]byte(value))
+			}
+			converted[i] = value
+		}
+		headers[key] = converted
+	}
+	return headers
+}
+
+// toGRPCValues 按 gRPC 规则校验并转换 header 的值，不符合规则时返回 false
+func toGRPCValues(key string, values []string) ([]string, bool) {
+	if !isValidGRPCKey(key) {
+		return nil, false
+	}
+	converted := make([]string, len(values))
+	for i, value := range values {
+		if strings.HasSuffix(key, binarySuffix) {
+			decoded, err := decodeBinaryValue(value)
+			if err != nil {
+				return nil, false
+			}
+			value = string(decoded)
+		} else if !isPrintableASCII(value) {
+			return nil, false
+		}
+		converted[i] = value
+	}
+	return converted, true
+}
+
+// decodeBinaryValue 按 base64 解码二进制 header 的值，同时接受带填充和不带填充的编码
+func decodeBinaryValue(value string) ([]byte, error) {
+	if len(value)%4 == 0 {
+		return base64.StdEncoding.DecodeString(value)
+	}
+	return base64.RawStdEncoding.DecodeString(value)
+}
+
+// isValidGRPCKey 判断键名是否符合 gRPC 的规则：非空，只包含数字、小写字母和 "-"、"_"、"."
+func isValidGRPCKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// isPrintableASCII 判断值是否只包含可打印的 ASCII 字符（包括空格）
+func isPrintableASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x20 || value[i] > 0x7E {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestToGRPCMetadata(t *testing.T) {
+	ctx := SetRPCHeaders(context.Background(), map[string]string{
+		"X-Request-Id": "req-1",
+		"x-tenant":     "acme",
+		"trace-bin":    "AAECAw==",
+	})
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.1")
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.2")
+
+	md := ToGRPCMetadata(ctx)
+	assert.Equal(t, metadata.MD{
+		"x-request-id":    {"req-1"},
+		"x-tenant":        {"acme"},
+		"trace-bin":       {"\x00\x01\x02\x03"},
+		"x-forwarded-for": {"10.0.0.1", "10.0.0.2"},
+	}, md)
+
+	// 空上下文
+	assert.Empty(t, ToGRPCMetadata(context.Background()))
+	assert.Empty(t, ToGRPCMetadata(nil))
+}
+
+func TestToGRPCMetadataInvalid(t *testing.T) {
+	ctx := SetRPCHeaders(context.Background(), map[string]string{
+		"x-valid":      "ok",
+		"x invalid":    "space in key",
+		"x:colon":      "colon in key",
+		"x-unicode":    "租户",
+		"x-newline":    "a\nb",
+		"broken-bin":   "not base64!",
+		"unpadded-bin": "AAECAw",
+	})
+
+	md := ToGRPCMetadata(ctx)
+	assert.Equal(t, metadata.MD{
+		"x-valid":      {"ok"},
+		"unpadded-bin": {"\x00\x01\x02\x03"},
+	}, md)
+}
+
+func TestToGRPCMetadataCaseMerge(t *testing.T) {
+	ctx := SetRPCHeaders(context.Background(), map[string]string{"X-A": "upper", "x-a": "lower"})
+
+	// 大小写不同的同名 header 按键名排序后合并
+	assert.Equal(t, metadata.MD{"x-a": {"upper", "lower"}}, ToGRPCMetadata(ctx))
+}
+
+func TestNewContextFromGRPCMetadata(t *testing.T) {
+	base := SetRPCHeaders(context.Background(), map[string]string{"x-a": "old", "x-keep": "1"})
+	md := metadata.MD{
+		"x-a":        {"new"},
+		"x-multi":    {"1", "2"},
+		"trace-bin":  {"\x00\x01\x02\x03"},
+		":authority": {"example.com"},
+		"x-empty":    {},
+	}
+
+	ctx := NewContextFromGRPCMetadata(base, md)
+	assert.Equal(t, map[string][]string{
+		"x-a":       {"new"},
+		"x-keep":    {"1"},
+		"x-multi":   {"1", "2"},
+		"trace-bin": {"AAECAw=="},
+	}, GetRPCHeadersMulti(ctx))
+	// 原上下文不受影响
+	assert.Equal(t, map[string]string{"x-a": "old", "x-keep": "1"}, GetRPCHeaders(base))
+
+	// 空 metadata 直接返回原上下文
+	assert.Equal(t, base, NewContextFromGRPCMetadata(base, nil))
+	assert.Equal(t, base, NewContextFromGRPCMetadata(base, metadata.MD{":path": {"/svc/Method"}}))
+}
+
+func TestGRPCMetadataRoundTrip(t *testing.T) {
+	ctx := SetRPCHeaders(context.Background(), map[string]string{
+		"x-request-id": "req-1",
+		"x-space":      "a b",
+		"trace-bin":    "AP8Q",
+	})
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.1")
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.2")
+	ctx = AddRPCHeader(ctx, "grpc-trace-bin", "AAE=")
+	ctx = AddRPCHeader(ctx, "grpc-trace-bin", "AAI=")
+
+	restored := NewContextFromGRPCMetadata(context.Background(), ToGRPCMetadata(ctx))
+	assert.Equal(t, GetRPCHeadersMulti(ctx), GetRPCHeadersMulti(restored))
+}
+
+func TestGRPCMetadataMultiValueRoundTrip(t *testing.T) {
+	ctx := AddRPCHeader(context.Background(), "x-forwarded-for", "10.0.0.1")
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.2")
+	ctx = AddRPCHeader(ctx, "grpc-trace-bin", "AAEC")
+	ctx = AddRPCHeader(ctx, "grpc-trace-bin", "AwQF")
+
+	// 每个值都写入 metadata
+	md := ToGRPCMetadata(ctx)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, md.Get("x-forwarded-for"))
+	assert.Equal(t, []string{"\x00\x01\x02", "\x03\x04\x05"}, md.Get("grpc-trace-bin"))
+
+	// 导入时保留所有值
+	restored := NewContextFromGRPCMetadata(context.Background(), md)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, GetRPCHeaderValues(restored, "x-forwarded-for"))
+	assert.Equal(t, GetRPCHeadersMulti(ctx), GetRPCHeadersMulti(restored))
+}
+
+func TestOutgoingGRPCContext(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-a", "existing", "authorization", "Bearer token")
+	ctx = SetRPCHeaders(ctx, map[string]string{"x-a": "header", "x-b": "2"})
+
+	outgoing, ok := metadata.FromOutgoingContext(OutgoingGRPCContext(ctx))
+	assert.True(t, ok)
+	assert.Equal(t, metadata.MD{
+		"x-a":           {"existing", "header"},
+		"x-b":           {"2"},
+		"authorization": {"Bearer token"},
+	}, outgoing)
+
+	// 没有 header 时直接返回原上下文
+	plain := context.Background()
+	assert.Equal(t, plain, OutgoingGRPCContext(plain))
+}
@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// defaultHTTPPropagation HTTPMiddleware 默认提取的请求头
+var defaultHTTPPropagation = []PropagationOption{
+	AllowHeaders(RequestIDHeader, TraceparentHeader, B3Header, PriorityHeader, DedupKeyHeader, ExperimentBucketHeader),
+	AllowHeaderPrefixes("x-b3-"),
+}
+
+// HTTPMiddleware 返回 net/http 中间件，将请求头提取到请求上下文的 headers 中
+//
+// 在默认策略（见 SetDefaultPropagationPolicy）的允许列表中追加 X-Request-Id、traceparent、b3、X-Priority、X-Dedup-Key、X-Experiment-Bucket 和 X-B3-* 请求头，
+// 零值的默认策略下只提取这些请求头。可以通过 AllowHeaders、AllowHeaderPrefixes
+// 追加（如 AllowHeaderPrefixes("x-ctx-")），通过 DenyHeaders 排除，总大小默认不超过 DefaultMaxPropagatedBytes。
+// 请求头名称转换为小写后作为 header 的键名（X-Request-Id → x-request-id），与 gRPC metadata 的键名一致；
+// 同名的多个请求头按顺序保存为多值 header。
+//
+// 参数:
+//   - next: 处理请求的 handler，通过 r.Context() 读取 headers
+//   - opts: 提取规则
+//
+// 返回值:
+//   - http.Handler: 包装后的 handler
+//
+// 示例:
+//
+//	handler := HTTPMiddleware(mux, AllowHeaderPrefixes("x-ctx-"))
+//	// 在 mux 的 handler 中:
+//	requestID, _ := GetRPCHeader(r.Context(), "x-request-id")
+func HTTPMiddleware(next http.Handler, opts ...PropagationOption) http.Handler {
+	cfg := newPropagationConfig(slices.Concat(defaultHTTPPropagation, opts)...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers := make(map[string][]string, len(r.Header))
+		for name, values := range r.Header {
+			if len(values) > 0 {
+				key := strings.ToLower(name)
+				headers[key] = append(headers[key], values...)
+			}
+		}
+		ctx := mergeHeaders(r.Context(), cfg.filter(headers))
+		if ctx != r.Context() {
+			r = r.WithContext(ctx)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InjectHTTPHeaders 将上下文中的 headers 写入发出的 HTTP 请求头，是 HTTPMiddleware 的反向操作
+//
+// 以 SetDefaultPropagationPolicy 设置的策略为基础，零值的默认策略下注入上下文中的所有 headers
+// （content-type、user-agent 等传输层 headers 除外），可以通过 AllowHeaders、AllowHeaderPrefixes 和 DenyHeaders 筛选，总大小默认不超过 DefaultMaxPropagatedBytes。
+// 键名按 http.CanonicalHeaderKey 转换为规范形式（x-request-id → X-Request-Id），多值 header 的所有值按顺序写入；
+// 键名或值不是合法 HTTP 请求头的 header 会被跳过。
+// h 中已有的同名请求头（不区分大小写）优先，传入 OverrideHeaders 时改为替换已有的值。
+//
+// 参数:
+//   - ctx: 上下文
+//   - h: 发出请求的请求头，如 req.Header，为 nil 时不做任何操作
+//   - opts: 注入规则
+//
+// 示例:
+//
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/orders", nil)
+//	InjectHTTPHeaders(ctx, req.Header, AllowHeaderPrefixes("x-ctx-"))
+//	resp, err := http.DefaultClient.Do(req)
+func InjectHTTPHeaders(ctx context.Context, h http.Header, opts ...PropagationOption) {
+	headers := headersFrom(ctx)
+	if h == nil || len(headers) == 0 {
+		return
+	}
+	cfg := newPropagationConfig(opts...)
+	filtered := cfg.filter(headers)
+
+	keys := make([]string, 0, len(filtered))
+	for key := range filtered {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// 请求中已有的键名，直接赋值到 h 的键名可能不是规范形式
+	existing := make(map[string][]string, len(h))
+	for name, values := range h {
+		if len(values) > 0 {
+			canonical := http.CanonicalHeaderKey(name)
+			existing[canonical] = append(existing[canonical], name)
+		}
+	}
+
+	// 先汇总再写入，避免大小写不同的同名 header 被误判为请求中已有的值
+	injected := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		if !httpguts.ValidHeaderFieldName(key) || !validHTTPValues(filtered[key]) {
+			continue
+		}
+		name := http.CanonicalHeaderKey(key)
+		if !cfg.override && len(existing[name]) > 0 {
+			continue
+		}
+		injected[name] = append(injected[name], filtered[key]...)
+	}
+	for name, values := range injected {
+		for _, old := range existing[name] {
+			delete(h, old)
+		}
+		h[name] = slices.Clone(values)
+	}
+}
+
+// validHTTPValues 判断所有值是否都是合法的 HTTP 请求头的值
+func validHTTPValues(values []string) bool {
+	for _, value := range values {
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serveWithMiddleware 使用 HTTPMiddleware 处理请求，返回 handler 中读取到的 headers
+func serveWithMiddleware(t *testing.T, req *http.Request, opts ...PropagationOption) map[string][]string {
+	var headers map[string][]string
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = GetRPCHeadersMulti(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}), opts...)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	return headers
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("X-Request-Id", "req-1")
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-B3-TraceId", "4bf92f3577b34da6")
+	req.Header.Set("X-B3-Sampled", "1")
+	req.Header.Set("X-Ctx-Tenant", "acme")
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Cookie", "session=1")
+
+	// 默认只提取 X-Request-Id、traceparent 和 X-B3-*，键名转换为小写
+	assert.Equal(t, map[string][]string{
+		"x-request-id": {"req-1"},
+		"traceparent":  {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"x-b3-traceid": {"4bf92f3577b34da6"},
+		"x-b3-sampled": {"1"},
+	}, serveWithMiddleware(t, req))
+
+	// 追加前缀，并排除默认的 header
+	assert.Equal(t, map[string][]string{
+		"x-request-id": {"req-1"},
+		"traceparent":  {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"x-ctx-tenant": {"acme"},
+	}, serveWithMiddleware(t, req, AllowHeaderPrefixes("X-Ctx-"), DenyHeaders("x-b3-traceid", "x-b3-sampled")))
+}
+
+func TestHTTPMiddlewareMultiValue(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Add("X-Forwarded-For", "10.0.0.1")
+	req.Header.Add("X-Forwarded-For", "10.0.0.2")
+	// 非规范大小写的请求头与规范形式合并
+	req.Header["x-forwarded-for"] = []string{"10.0.0.3"}
+
+	headers := serveWithMiddleware(t, req, AllowHeaders("x-forwarded-for"))
+	assert.ElementsMatch(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, headers["x-forwarded-for"])
+	assert.Len(t, headers, 1)
+}
+
+func TestHTTPMiddlewareLimits(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-Id", "req-1")
+	req.Header.Set("X-Ctx-Large", strings.Repeat("a", DefaultMaxPropagatedBytes))
+
+	// 超出大小限制的 header 整体丢弃
+	assert.Equal(t, map[string][]string{"x-request-id": {"req-1"}}, serveWithMiddleware(t, req, AllowHeaderPrefixes("x-ctx-")))
+}
+
+func TestHTTPMiddlewareKeepsContext(t *testing.T) {
+	type ctxKey struct{}
+	base := context.WithValue(context.Background(), ctxKey{}, "value")
+	base = SetRPCHeader(base, "x-existing", "1")
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(base)
+	req.Header.Set("X-Request-Id", "req-1")
+
+	var ctx context.Context
+	HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	// 保留请求上下文中已有的值和 headers
+	assert.Equal(t, "value", ctx.Value(ctxKey{}))
+	assert.Equal(t, map[string]string{"x-existing": "1", "x-request-id": "req-1"}, GetRPCHeaders(ctx))
+
+	// 没有可提取的请求头时使用原请求
+	req = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(base)
+	HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, base, ctx)
+}
+
+func TestInjectHTTPHeaders(t *testing.T) {
+	ctx := SetRPCHeaders(context.Background(), map[string]string{
+		"x-request-id": "req-1",
+		"x-ctx-tenant": "acme",
+		"content-type": "application/grpc",
+	})
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.1")
+	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.2")
+
+	// 默认注入所有 headers，传输层 headers 除外，键名转换为规范形式
+	h := http.Header{}
+	InjectHTTPHeaders(ctx, h)
+	assert.Equal(t, http.Header{
+		"X-Request-Id":    {"req-1"},
+		"X-Ctx-Tenant":    {"acme"},
+		"X-Forwarded-For": {"10.0.0.1", "10.0.0.2"},
+	}, h)
+
+	// 按允许列表、前缀和禁止列表筛选
+	h = http.Header{}
+	InjectHTTPHeaders(ctx, h, AllowHeaders("x-request-id", "x-forwarded-for"), AllowHeaderPrefixes("x-ctx-"), DenyHeaders("x-forwarded-for"))
+	assert.Equal(t, http.Header{
+		"X-Request-Id": {"req-1"},
+		"X-Ctx-Tenant": {"acme"},
+	}, h)
+
+	// 超出大小限制的 header 整体丢弃
+	h = http.Header{}
+	InjectHTTPHeaders(ctx, h, AllowHeaders("x-request-id", "x-ctx-tenant"), MaxPropagatedBytes(len("x-ctx-tenant")+len("acme")))
+	assert.Equal(t, http.Header{"X-Ctx-Tenant": {"acme"}}, h)
+}
+
+func TestInjectHTTPHeadersOverride(t *testing.T) {
+	ctx := SetRPCHeaders(context.Background(), map[string]string{
+		"x-request-id": "from-ctx",
+		"x-tenant":     "acme",
+	})
+	newHeader := func() http.Header {
+		h := http.Header{}
+		h.Set("X-Request-Id", "from-request")
+		// 非规范形式的键名同样视为已有的请求头
+		h["x-tenant"] = []string{"from-request"}
+		return h
+	}
+
+	// 默认请求中已有的值优先
+	h := newHeader()
+	InjectHTTPHeaders(ctx, h)
+	assert.Equal(t, newHeader(), h)
+
+	// 覆盖模式下替换已有的值，同时清理非规范形式的键名
+	h = newHeader()
+	InjectHTTPHeaders(ctx, h, OverrideHeaders())
+	assert.Equal(t, http.Header{
+		"X-Request-Id": {"from-ctx"},
+		"X-Tenant":     {"acme"},
+	}, h)
+}
+
+func TestInjectHTTPHeadersEdgeCases(t *testing.T) {
+	// 上下文中没有 headers
+	h := http.Header{"Accept": {"application/json"}}
+	InjectHTTPHeaders(context.Background(), h)
+	assert.Equal(t, http.Header{"Accept": {"application/json"}}, h)
+
+	// h 为 nil 时不做任何操作
+	ctx := SetRPCHeader(context.Background(), "x-request-id", "req-1")
+	assert.NotPanics(t, func() { InjectHTTPHeaders(ctx, nil) })
+
+	// 跳过不合法的键名和值，大小写不同的同名 header 合并
+	ctx = SetRPCHeaders(ctx, map[string]string{
+		"bad key":  "1",
+		"x-line":   "a\r\nb",
+		"X-Tenant": "acme",
+		"x-tenant": "beta",
+	})
+	h = http.Header{}
+	InjectHTTPHeaders(ctx, h)
+	assert.Equal(t, http.Header{
+		"X-Request-Id": {"req-1"},
+		"X-Tenant":     {"acme", "beta"},
+	}, h)
+
+	// 注入的值与上下文互不影响
+	h["X-Request-Id"][0] = "changed"
+	value, _ := GetRPCHeader(ctx, "x-request-id")
+	assert.Equal(t, "req-1", value)
+}
+
+func TestInjectHTTPHeadersRoundTrip(t *testing.T) {
+	ctx := SetRPCHeader(context.Background(), "x-request-id", "req-1")
+	ctx = SetPriority(ctx, PriorityLow)
+
+	server := httptest.NewServer(HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := GetRPCHeader(r.Context(), "x-request-id")
+		w.Header().Set("X-Seen", id+"/"+string(GetPriority(r.Context())))
+	})))
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	InjectHTTPHeaders(ctx, req.Header)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "req-1/low", resp.Header.Get("X-Seen"))
+}
@@ -0,0 +1,127 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor 返回 gRPC 一元服务端拦截器，将请求的 incoming metadata 提取到上下文的 headers 中
+//
+// handler 可以直接通过 GetRPCHeader 读取 x-request-id、traceparent 等 metadata，
+// 不需要调用 metadata.FromIncomingContext。多个值的 metadata 按顺序保存为多值 header，
+// 以 "-bin" 结尾的二进制 metadata 按 base64 编码保存，规则与 NewContextFromGRPCMetadata 相同。
+// 默认提取除 content-type、user-agent 以外的所有 metadata，总大小不超过 DefaultMaxPropagatedBytes，
+// 可以通过 SetDefaultPropagationPolicy 修改默认策略，或通过 AllowHeaders、DenyHeaders 和 MaxPropagatedBytes 调整。
+//
+// 参数:
+//   - opts: 提取规则
+//
+// 返回值:
+//   - grpc.UnaryServerInterceptor: 服务端拦截器
+//
+// 示例:
+//
+//	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+//	    UnaryServerInterceptor(AllowHeaders("x-request-id", "x-tenant-id", "traceparent")),
+//	))
+func UnaryServerInterceptor(opts ...PropagationOption) grpc.UnaryServerInterceptor {
+	cfg := newPropagationConfig(opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(extractGRPCHeaders(ctx, cfg), req)
+	}
+}
+
+// StreamServerInterceptor 返回 gRPC 流式服务端拦截器，将流的 incoming metadata 提取到上下文的 headers 中
+//
+// handler 收到的流的 Context() 在整个流的生命周期内都包含提取出的 headers，
+// SendMsg、RecvMsg 等方法保持原样。提取规则与 UnaryServerInterceptor 相同。
+//
+// 参数:
+//   - opts: 提取规则
+//
+// 返回值:
+//   - grpc.StreamServerInterceptor: 服务端拦截器
+//
+// 示例:
+//
+//	server := grpc.NewServer(
+//	    grpc.ChainUnaryInterceptor(UnaryServerInterceptor()),
+//	    grpc.ChainStreamInterceptor(StreamServerInterceptor()),
+//	)
+func StreamServerInterceptor(opts ...PropagationOption) grpc.StreamServerInterceptor {
+	cfg := newPropagationConfig(opts...)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &headersServerStream{ServerStream: stream, ctx: extractGRPCHeaders(stream.Context(), cfg)})
+	}
+}
+
+// UnaryClientInterceptor 返回 gRPC 一元客户端拦截器，将上下文中的 headers 追加到请求的 outgoing metadata 中
+//
+// 转换规则见 ToGRPCMetadata，传递规则以 SetDefaultPropagationPolicy 设置的默认策略为基础，
+// 通过 AllowHeaders、DenyHeaders 和 MaxPropagatedBytes 调整。
+//
+// 参数:
+//   - opts: 传递规则
+//
+// 返回值:
+//   - grpc.UnaryClientInterceptor: 客户端拦截器
+//
+// 示例:
+//
+//	conn, err := grpc.NewClient(target, grpc.WithChainUnaryInterceptor(UnaryClientInterceptor()))
+func UnaryClientInterceptor(opts ...PropagationOption) grpc.UnaryClientInterceptor {
+	cfg := newPropagationConfig(opts...)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		return invoker(injectGRPCHeaders(ctx, cfg), method, req, reply, cc, callOpts...)
+	}
+}
+
+// StreamClientInterceptor 返回 gRPC 流式客户端拦截器，在创建流时将上下文中的 headers 追加到 outgoing metadata 中
+//
+// 规则与 UnaryClientInterceptor 相同，返回的流保持原样。
+//
+// 参数:
+//   - opts: 传递规则
+//
+// 返回值:
+//   - grpc.StreamClientInterceptor: 客户端拦截器
+//
+// 示例:
+//
+//	conn, err := grpc.NewClient(target,
+//	    grpc.WithChainUnaryInterceptor(UnaryClientInterceptor()),
+//	    grpc.WithChainStreamInterceptor(StreamClientInterceptor()),
+//	)
+func StreamClientInterceptor(opts ...PropagationOption) grpc.StreamClientInterceptor {
+	cfg := newPropagationConfig(opts...)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(injectGRPCHeaders(ctx, cfg), desc, cc, method, callOpts...)
+	}
+}
+
+// headersServerStream 替换 Context() 返回值的服务端流，其他方法由内嵌的流实现
+type headersServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回包含提取出的 headers 的上下文
+func (s *headersServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// injectGRPCHeaders 按规则将上下文中的 headers 追加到 outgoing metadata 中
+func injectGRPCHeaders(ctx context.Context, cfg *propagationConfig) context.Context {
+	return appendOutgoingMetadata(ctx, toGRPCMetadata(cfg.filter(headersFrom(ctx))))
+}
+
+// extractGRPCHeaders 按规则将 incoming metadata 提取到上下文的 headers 中
+func extractGRPCHeaders(ctx context.Context, cfg *propagationConfig) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return mergeHeaders(ctx, cfg.filter(fromGRPCMetadata(md)))
+}
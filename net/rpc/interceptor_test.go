@@ -0,0 +1,258 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// 测试服务的方法
+const (
+	// checkMethod 一元方法
+	checkMethod = "/rpc.test.Echo/Check"
+	// echoMethod 双向流方法，原样返回收到的每条消息
+	echoMethod = "/rpc.test.Echo/Echo"
+)
+
+// echoStreamDesc 双向流方法的描述
+var echoStreamDesc = &grpc.StreamDesc{StreamName: "Echo", ServerStreams: true, ClientStreams: true}
+
+// newTestConn 启动测试服务并返回连接到该服务的客户端
+func newTestConn(t *testing.T, handler func(ctx context.Context), opts ...grpc.ServerOption) *grpc.ClientConn {
+	return dialTestServer(t, startTestServer(t, handler, opts...))
+}
+
+// startTestServer 在 bufconn 上启动包含一个一元方法和一个双向流方法的测试服务，handler 收到服务端的上下文
+func startTestServer(t *testing.T, handler func(ctx context.Context), opts ...grpc.ServerOption) *bufconn.Listener {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "rpc.test.Echo",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Check",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := &healthpb.HealthCheckRequest{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: checkMethod}
+				unary := func(ctx context.Context, req interface{}) (interface{}, error) {
+					handler(ctx)
+					return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+				}
+				if interceptor == nil {
+					return unary(ctx, req)
+				}
+				return interceptor(ctx, req, info, unary)
+			},
+		}},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    echoStreamDesc.StreamName,
+			ServerStreams: true,
+			ClientStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				handler(stream.Context())
+				for {
+					msg := &healthpb.HealthCheckRequest{}
+					if err := stream.RecvMsg(msg); err != nil {
+						if errors.Is(err, io.EOF) {
+							return nil
+						}
+						return err
+					}
+					// 每条消息都确认 headers 在整个流的生命周期内可见
+					value, _ := GetRPCHeader(stream.Context(), "x-request-id")
+					msg.Service += "@" + value
+					if err := stream.SendMsg(msg); err != nil {
+						return err
+					}
+				}
+			},
+		}},
+	}, struct{}{})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return listener
+}
+
+// dialTestServer 创建连接到测试服务的客户端
+func dialTestServer(t *testing.T, listener *bufconn.Listener, opts ...grpc.DialOption) *grpc.ClientConn {
+	opts = append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// invokeCheck 调用测试服务的一元方法
+func invokeCheck(t *testing.T, ctx context.Context, conn *grpc.ClientConn) {
+	err := conn.Invoke(ctx, checkMethod, &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	assert.NoError(t, err)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var headers map[string][]string
+	conn := newTestConn(t, func(ctx context.Context) {
+		headers = GetRPCHeadersMulti(ctx)
+	}, grpc.UnaryInterceptor(UnaryServerInterceptor()))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"x-request-id", "req-1",
+		"x-tenant-id", "acme",
+		"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"x-forwarded-for", "10.0.0.1",
+		"x-forwarded-for", "10.0.0.2",
+		"trace-bin", "\x00\x01",
+	)
+	invokeCheck(t, ctx, conn)
+
+	assert.Equal(t, []string{"req-1"}, headers["x-request-id"])
+	assert.Equal(t, []string{"acme"}, headers["x-tenant-id"])
+	assert.Equal(t, []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, headers["traceparent"])
+	// 多个值保存为多值 header，二进制值按 base64 编码
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, headers["x-forwarded-for"])
+	assert.Equal(t, []string{"AAE="}, headers["trace-bin"])
+	// 默认不提取传输层 headers 和伪头部
+	assert.NotContains(t, headers, "content-type")
+	assert.NotContains(t, headers, "user-agent")
+	assert.NotContains(t, headers, ":authority")
+}
+
+func TestUnaryServerInterceptorPolicy(t *testing.T) {
+	var headers map[string]string
+	conn := newTestConn(t, func(ctx context.Context) {
+		headers = GetRPCHeaders(ctx)
+	}, grpc.UnaryInterceptor(UnaryServerInterceptor(
+		AllowHeaders("X-Request-Id", "x-tenant-id", "x-debug", "x-large", "x-small"),
+		DenyHeaders("x-debug"),
+		MaxPropagatedBytes(64),
+	)))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"x-request-id", "req-1",
+		"x-tenant-id", "acme",
+		"x-debug", "on",
+		"x-other", "not allowed",
+		"x-large", strings.Repeat("a", 1<<20),
+		"x-small", "s",
+	)
+	invokeCheck(t, ctx, conn)
+
+	// 只提取允许的 headers，禁止列表优先，超出大小限制的 header 整体丢弃
+	assert.Equal(t, map[string]string{
+		"x-request-id": "req-1",
+		"x-tenant-id":  "acme",
+		"x-small":      "s",
+	}, headers)
+}
+
+func TestUnaryServerInterceptorSizeCap(t *testing.T) {
+	var headers map[string]string
+	conn := newTestConn(t, func(ctx context.Context) {
+		headers = GetRPCHeaders(ctx)
+	}, grpc.UnaryInterceptor(UnaryServerInterceptor()))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"x-request-id", "req-1",
+		"x-stuffed", strings.Repeat("a", DefaultMaxPropagatedBytes),
+	)
+	invokeCheck(t, ctx, conn)
+
+	// 默认限制 DefaultMaxPropagatedBytes
+	assert.Equal(t, map[string]string{"x-request-id": "req-1"}, headers)
+}
+
+func TestUnaryServerInterceptorNoMetadata(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	base := SetRPCHeader(context.Background(), "x-a", "1")
+	_, err := interceptor(base, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		assert.Equal(t, base, ctx)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	var headers map[string][]string
+	conn := newTestConn(t, func(ctx context.Context) {
+		headers = GetRPCHeadersMulti(ctx)
+	}, grpc.StreamInterceptor(StreamServerInterceptor(DenyHeaders("x-debug"))))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"x-request-id", "req-1",
+		"x-forwarded-for", "10.0.0.1",
+		"x-forwarded-for", "10.0.0.2",
+		"x-debug", "on",
+	)
+	stream, err := conn.NewStream(ctx, echoStreamDesc, echoMethod)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, service := range []string{"a", "b", "c"} {
+		assert.NoError(t, stream.SendMsg(&healthpb.HealthCheckRequest{Service: service}))
+		reply := &healthpb.HealthCheckRequest{}
+		assert.NoError(t, stream.RecvMsg(reply))
+		assert.Equal(t, service+"@req-1", reply.Service)
+	}
+	assert.NoError(t, stream.CloseSend())
+	assert.ErrorIs(t, stream.RecvMsg(&healthpb.HealthCheckRequest{}), io.EOF)
+
+	assert.Equal(t, []string{"req-1"}, headers["x-request-id"])
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, headers["x-forwarded-for"])
+	assert.NotContains(t, headers, "x-debug")
+}
+
+func TestClientInterceptors(t *testing.T) {
+	var headers []map[string][]string
+	listener := startTestServer(t, func(ctx context.Context) {
+		headers = append(headers, GetRPCHeadersMulti(ctx))
+	}, grpc.UnaryInterceptor(UnaryServerInterceptor()), grpc.StreamInterceptor(StreamServerInterceptor()))
+	conn := dialTestServer(t, listener,
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(DenyHeaders("x-internal"))),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(DenyHeaders("x-internal"))),
+	)
+
+	ctx := SetRPCHeaders(context.Background(), map[string]string{"x-request-id": "req-1", "x-internal": "secret"})
+	ctx = AddRPCHeader(ctx, "x-hop", "a")
+	ctx = AddRPCHeader(ctx, "x-hop", "b")
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token")
+
+	invokeCheck(t, ctx, conn)
+	stream, err := conn.NewStream(ctx, echoStreamDesc, echoMethod)
+	if assert.NoError(t, err) {
+		assert.NoError(t, stream.SendMsg(&healthpb.HealthCheckRequest{Service: "a"}))
+		reply := &healthpb.HealthCheckRequest{}
+		assert.NoError(t, stream.RecvMsg(reply))
+		assert.Equal(t, "a@req-1", reply.Service)
+		assert.NoError(t, stream.CloseSend())
+		assert.ErrorIs(t, stream.RecvMsg(reply), io.EOF)
+	}
+
+	// 一元调用和流都携带了上下文中的 headers，已有的 outgoing metadata 保留，禁止的 header 不会发出
+	if assert.Len(t, headers, 2) {
+		for _, h := range headers {
+			assert.Equal(t, []string{"req-1"}, h["x-request-id"])
+			assert.Equal(t, []string{"a", "b"}, h["x-hop"])
+			assert.Equal(t, []string{"Bearer token"}, h["authorization"])
+			assert.NotContains(t, h, "x-internal")
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+)
+
+// PriorityHeader 请求优先级的 header 名称
+//
+// 优先级以普通 RPC header 的形式存储在上下文中，值为 "high"、"normal" 或 "low"，
+// 会随其他 headers 一起透传到下游调用，HTTPMiddleware 默认提取该请求头。
+const PriorityHeader = "x-priority"
+
+// Priority 请求优先级，后端据此对低优先级流量降级
+type Priority string
+
+const (
+	// PriorityHigh 高优先级，如用户直接触发的请求
+	PriorityHigh Priority = "high"
+	// PriorityNormal 普通优先级，未设置优先级时的默认值
+	PriorityNormal Priority = "normal"
+	// PriorityLow 低优先级，如后台任务和批处理
+	PriorityLow Priority = "low"
+)
+
+// ParsePriority 解析优先级，不区分大小写，忽略首尾空白
+//
+// 参数:
+//   - s: 优先级字符串，如 "high"
+//
+// 返回值:
+//   - Priority: 解析后的优先级，无法识别时为 PriorityNormal
+//   - bool: 是否为合法的优先级
+func ParsePriority(s string) (Priority, bool) {
+	switch p := Priority(strings.ToLower(strings.TrimSpace(s))); p {
+	case PriorityHigh, PriorityNormal, PriorityLow:
+		return p, true
+	}
+	return PriorityNormal, false
+}
+
+// SetPriority 在上下文中设置请求优先级
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - p: 请求优先级，无法识别的值按 PriorityNormal 保存
+//
+// 返回值:
+//   - context.Context: 新的上下文，包含优先级 header
+//
+// 示例:
+//
+//	// 后台任务将自身标记为低优先级，下游调用会一直携带该标记
+//	ctx = SetPriority(ctx, PriorityLow)
+func SetPriority(ctx context.Context, p Priority) context.Context {
+	p, _ = ParsePriority(string(p))
+	return SetRPCHeader(ctx, PriorityHeader, string(p))
+}
+
+// GetPriority 从上下文中获取请求优先级
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - Priority: 请求优先级，未设置或值无法识别时为 PriorityNormal
+//
+// 示例:
+//
+//	if GetPriority(r.Context()) == PriorityLow {
+//	    // 排入低优先级队列
+//	}
+func GetPriority(ctx context.Context) Priority {
+	value, ok := GetRPCHeader(ctx, PriorityHeader)
+	if !ok {
+		return PriorityNormal
+	}
+	p, _ := ParsePriority(value)
+	return p
+}
@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriority(t *testing.T) {
+	// 未设置时默认为普通优先级
+	assert.Equal(t, PriorityNormal, GetPriority(context.Background()))
+
+	ctx := SetPriority(context.Background(), PriorityLow)
+	assert.Equal(t, PriorityLow, GetPriority(ctx))
+	assert.Equal(t, map[string]string{PriorityHeader: "low"}, GetRPCHeaders(ctx))
+
+	// 覆盖
+	ctx = SetPriority(ctx, PriorityHigh)
+	assert.Equal(t, PriorityHigh, GetPriority(ctx))
+
+	// 无法识别的值按普通优先级处理
+	ctx = SetPriority(ctx, "urgent")
+	assert.Equal(t, map[string]string{PriorityHeader: "normal"}, GetRPCHeaders(ctx))
+	ctx = SetRPCHeader(ctx, PriorityHeader, "urgent")
+	assert.Equal(t, PriorityNormal, GetPriority(ctx))
+
+	// 对端传入的值不区分大小写
+	ctx = SetRPCHeader(ctx, PriorityHeader, " LOW ")
+	assert.Equal(t, PriorityLow, GetPriority(ctx))
+}
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Priority
+		ok    bool
+	}{
+		{"high", PriorityHigh, true},
+		{"Normal", PriorityNormal, true},
+		{"low", PriorityLow, true},
+		{"", PriorityNormal, false},
+		{"urgent", PriorityNormal, false},
+	}
+	for _, tt := range tests {
+		p, ok := ParsePriority(tt.input)
+		assert.Equal(t, tt.want, p, tt.input)
+		assert.Equal(t, tt.ok, ok, tt.input)
+	}
+}
+
+func TestPriorityHTTPMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Priority", "low")
+
+	var p Priority
+	HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p = GetPriority(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, PriorityLow, p)
+}
@@ -0,0 +1,288 @@
+package rpc
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultMaxPropagatedBytes 从对端提取 headers 时默认允许的总字节数（键名与值的长度之和）
+const DefaultMaxPropagatedBytes = 8192
+
+// defaultDeniedKeys 默认不提取的传输层 headers
+var defaultDeniedKeys = []string{"content-type", "user-agent"}
+
+// PropagationPolicy headers 跨进程传递（注入到发出的请求或从收到的请求中提取）的策略
+//
+// 规则按以下顺序判断，键名均不区分大小写：
+//  1. DenyKeys 以及 content-type、user-agent 等传输层 headers 一律不传递，禁止列表优先于允许列表
+//  2. AllowKeys 和 AllowPrefixes 都为空时允许其余所有键名，否则键名须在 AllowKeys 中或以 AllowPrefixes 中的某个前缀开头
+//  3. 任意一个值超过 MaxValueBytes 的 header 整体丢弃
+//  4. 剩余的 header 按键名排序依次放入，最多 MaxKeys 个，总大小不超过 MaxPropagatedBytes 设置的限制
+//
+// 设置了 TraceFormats 时，trace header 在上述规则之前先转换为指定的格式。
+// 零值的策略允许除传输层 headers 以外的所有 headers。
+type PropagationPolicy struct {
+	// AllowKeys 允许传递的键名
+	AllowKeys []string
+	// DenyKeys 禁止传递的键名，优先于 AllowKeys 和 AllowPrefixes
+	DenyKeys []string
+	// AllowPrefixes 允许传递的键名前缀
+	AllowPrefixes []string
+	// MaxKeys 最多传递的 header 数量，小于等于 0 时不限制
+	MaxKeys int
+	// MaxValueBytes 单个值的最大字节数，小于等于 0 时不限制
+	MaxValueBytes int
+	// TraceFormats 链路追踪信息的传递格式，为空时 trace header 按普通 header 原样传递
+	//
+	// 设置后，传递前先从 headers 中读取链路追踪信息（读取顺序见 ExtractTraceContext），
+	// 删除所有格式的 trace header，再按列表中的每一种格式重新写入，之后才按上述规则筛选，
+	// 因此使用 B3 的旧服务和使用 W3C 的新服务可以互通。
+	TraceFormats []TraceFormat
+}
+
+// defaultPolicy 包级别的默认传递策略
+var defaultPolicy atomic.Pointer[PropagationPolicy]
+
+// SetDefaultPropagationPolicy 设置包级别的默认传递策略
+//
+// 之后创建的拦截器、HTTPMiddleware 以及 InjectHTTPHeaders 等函数以该策略为基础，
+// 再依次应用各自传入的选项。已创建的拦截器和中间件不受影响，通常在程序启动时调用一次。
+//
+// 参数:
+//   - policy: 默认传递策略，零值表示恢复为允许所有 headers
+//
+// 示例:
+//
+//	SetDefaultPropagationPolicy(PropagationPolicy{
+//	    AllowKeys:     []string{"x-request-id", "traceparent"},
+//	    AllowPrefixes: []string{"x-ctx-"},
+//	    DenyKeys:      []string{"x-ctx-debug"},
+//	    MaxValueBytes: 1024,
+//	})
+func SetDefaultPropagationPolicy(policy PropagationPolicy) {
+	policy = policy.clone()
+	defaultPolicy.Store(&policy)
+}
+
+// DefaultPropagationPolicy 返回包级别的默认传递策略，未设置时为零值
+func DefaultPropagationPolicy() PropagationPolicy {
+	if policy := defaultPolicy.Load(); policy != nil {
+		return policy.clone()
+	}
+	return PropagationPolicy{}
+}
+
+// clone 复制策略，避免调用方修改切片影响已保存的策略
+func (p PropagationPolicy) clone() PropagationPolicy {
+	p.AllowKeys = slices.Clone(p.AllowKeys)
+	p.DenyKeys = slices.Clone(p.DenyKeys)
+	p.AllowPrefixes = slices.Clone(p.AllowPrefixes)
+	p.TraceFormats = slices.Clone(p.TraceFormats)
+	return p
+}
+
+// propagationConfig headers 跨进程传递时的筛选规则
+type propagationConfig struct {
+	// allow 允许传递的键名，allow 和 prefixes 都为空时允许所有键名
+	allow map[string]bool
+	// prefixes 允许传递的键名前缀
+	prefixes []string
+	// deny 禁止传递的键名，优先于 allow
+	deny map[string]bool
+	// maxBytes 允许传递的总字节数，小于等于 0 时不限制
+	maxBytes int
+	// maxKeys 允许传递的 header 数量，小于等于 0 时不限制
+	maxKeys int
+	// maxValueBytes 单个值的最大字节数，小于等于 0 时不限制
+	maxValueBytes int
+	// traceFormats 链路追踪信息的传递格式，为空时不转换
+	traceFormats []TraceFormat
+	// override 注入时是否覆盖请求中已有的同名 header
+	override bool
+}
+
+// PropagationOption headers 跨进程传递时的配置项
+type PropagationOption func(*propagationConfig)
+
+// AllowHeaders 只传递指定的 headers，键名不区分大小写，可以多次调用以追加
+func AllowHeaders(keys ...string) PropagationOption {
+	return func(c *propagationConfig) {
+		if c.allow == nil {
+			c.allow = make(map[string]bool, len(keys))
+		}
+		for _, key := range keys {
+			c.allow[strings.ToLower(key)] = true
+		}
+	}
+}
+
+// AllowHeaderPrefixes 同时传递键名以指定前缀开头的 headers，前缀不区分大小写，可以多次调用以追加
+//
+// 与 AllowHeaders 共同组成允许列表，满足其中之一即可传递。
+func AllowHeaderPrefixes(prefixes ...string) PropagationOption {
+	return func(c *propagationConfig) {
+		for _, prefix := range prefixes {
+			c.prefixes = append(c.prefixes, strings.ToLower(prefix))
+		}
+	}
+}
+
+// DenyHeaders 不传递指定的 headers，键名不区分大小写，优先于 AllowHeaders
+//
+// 默认已禁止 content-type 和 user-agent 等传输层 headers。
+func DenyHeaders(keys ...string) PropagationOption {
+	return func(c *propagationConfig) {
+		for _, key := range keys {
+			c.deny[strings.ToLower(key)] = true
+		}
+	}
+}
+
+// MaxPropagatedBytes 限制传递的 headers 的总字节数，n 小于等于 0 时不限制，默认为 DefaultMaxPropagatedBytes
+//
+// 超出限制的 header 整体丢弃，用于防止对端在上下文中塞入大量数据。
+func MaxPropagatedBytes(n int) PropagationOption {
+	return func(c *propagationConfig) {
+		c.maxBytes = n
+	}
+}
+
+// WithPropagationPolicy 使用指定的策略替代包级别的默认策略
+//
+// 会清除之前的选项设置的允许列表、禁止列表和数量限制，因此应放在其他选项之前，
+// 之后的 AllowHeaders、DenyHeaders 等选项在该策略的基础上追加。
+//
+// 示例:
+//
+//	interceptor := UnaryClientInterceptor(WithPropagationPolicy(PropagationPolicy{
+//	    AllowKeys: []string{"x-request-id"},
+//	}))
+func WithPropagationPolicy(policy PropagationPolicy) PropagationOption {
+	return func(c *propagationConfig) {
+		c.applyPolicy(policy)
+	}
+}
+
+// WithTraceFormats 按指定的格式传递链路追踪信息，替换默认策略中的 TraceFormats
+//
+// 提取时可以读取任意一种格式，列表的顺序决定多种格式冲突时的优先级；注入时写入列表中的所有格式。
+// 转换规则见 PropagationPolicy.TraceFormats。
+//
+// 示例:
+//
+//	// 同时兼容 W3C 和 B3 的服务
+//	handler := HTTPMiddleware(mux, WithTraceFormats(TraceFormatW3C, TraceFormatB3Multi))
+func WithTraceFormats(formats ...TraceFormat) PropagationOption {
+	return func(c *propagationConfig) {
+		c.traceFormats = slices.Clone(formats)
+	}
+}
+
+// OverrideHeaders 注入 headers 时覆盖请求中已有的同名请求头，只对 InjectHTTPHeaders 等注入函数生效
+//
+// 默认请求中已有的值优先，上下文中的同名 header 不会被注入。
+func OverrideHeaders() PropagationOption {
+	return func(c *propagationConfig) {
+		c.override = true
+	}
+}
+
+// newPropagationConfig 按顺序应用所有选项，生成筛选规则
+func newPropagationConfig(opts ...PropagationOption) *propagationConfig {
+	cfg := &propagationConfig{maxBytes: DefaultMaxPropagatedBytes}
+	cfg.applyPolicy(DefaultPropagationPolicy())
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	return cfg
+}
+
+// applyPolicy 以策略替换当前的筛选规则，传输层 headers 始终被禁止
+func (c *propagationConfig) applyPolicy(policy PropagationPolicy) {
+	c.allow = nil
+	c.prefixes = nil
+	c.deny = make(map[string]bool, len(defaultDeniedKeys)+len(policy.DenyKeys))
+	for _, key := range defaultDeniedKeys {
+		c.deny[key] = true
+	}
+	if len(policy.AllowKeys) > 0 {
+		AllowHeaders(policy.AllowKeys...)(c)
+	}
+	AllowHeaderPrefixes(policy.AllowPrefixes...)(c)
+	DenyHeaders(policy.DenyKeys...)(c)
+	c.maxKeys = policy.MaxKeys
+	c.maxValueBytes = policy.MaxValueBytes
+	c.traceFormats = slices.Clone(policy.TraceFormats)
+}
+
+// allowed 判断键名是否允许传递，禁止列表优先于允许列表
+func (c *propagationConfig) allowed(key string) bool {
+	key = strings.ToLower(key)
+	if c.deny[key] {
+		return false
+	}
+	if c.allow == nil && len(c.prefixes) == 0 {
+		return true
+	}
+	if c.allow[key] {
+		return true
+	}
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter 按规则筛选 headers，返回新的 map
+//
+// 设置了传递格式时先转换 trace header。键名按字典序依次计入数量和总字节数，
+// 放不下的 header 整体丢弃，较小的 header 仍可能放入，因此相同的输入总是得到相同的结果。
+func (c *propagationConfig) filter(headers map[string][]string) map[string][]string {
+	if len(c.traceFormats) > 0 {
+		headers = convertTraceHeaders(headers, c.traceFormats)
+	}
+	keys := make([]string, 0, len(headers))
+	for key, values := range headers {
+		if c.allowed(key) && c.withinValueLimit(values) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	filtered := make(map[string][]string, len(keys))
+	total := 0
+	for _, key := range keys {
+		if c.maxKeys > 0 && len(filtered) >= c.maxKeys {
+			break
+		}
+		size := 0
+		for _, value := range headers[key] {
+			size += len(key) + len(value)
+		}
+		if c.maxBytes > 0 && total+size > c.maxBytes {
+			continue
+		}
+		total += size
+		filtered[key] = headers[key]
+	}
+	return filtered
+}
+
+// withinValueLimit 判断所有值是否都不超过单个值的大小限制
+func (c *propagationConfig) withinValueLimit(values []string) bool {
+	if c.maxValueBytes <= 0 {
+		return true
+	}
+	for _, value := range values {
+		if len(value) > c.maxValueBytes {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,240 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestPropagationFilter(t *testing.T) {
+	headers := map[string][]string{
+		"x-a":          {"1"},
+		"x-b":          {"22", "33"},
+		"content-type": {"application/grpc"},
+		"X-Debug":      {"on"},
+	}
+
+	// 默认只排除传输层 headers
+	assert.Equal(t, map[string][]string{
+		"x-a":     {"1"},
+		"x-b":     {"22", "33"},
+		"X-Debug": {"on"},
+	}, newPropagationConfig().filter(headers))
+
+	// 禁止列表不区分大小写，且优先于允许列表
+	cfg := newPropagationConfig(AllowHeaders("x-a", "x-debug"), DenyHeaders("X-DEBUG"))
+	assert.Equal(t, map[string][]string{"x-a": {"1"}}, cfg.filter(headers))
+
+	// 大小按键名与每个值的长度计算，按键名排序依次放入，X-Debug 和 x-a 共 13 字节，x-b 占 10 字节放不下，被跳过
+	cfg = newPropagationConfig(MaxPropagatedBytes(13))
+	assert.Equal(t, map[string][]string{"X-Debug": {"on"}, "x-a": {"1"}}, cfg.filter(headers))
+
+	// 不限制大小
+	cfg = newPropagationConfig(MaxPropagatedBytes(0))
+	assert.Len(t, cfg.filter(headers), 3)
+}
+
+func TestPropagationPrefixes(t *testing.T) {
+	headers := map[string][]string{
+		"x-ctx-tenant": {"acme"},
+		"X-CTX-Region": {"eu"},
+		"x-ctx-debug":  {"on"},
+		"x-request-id": {"req-1"},
+		"x-other":      {"1"},
+	}
+
+	// 前缀与键名共同组成允许列表，不区分大小写，禁止列表优先
+	cfg := newPropagationConfig(AllowHeaders("x-request-id"), AllowHeaderPrefixes("X-Ctx-"), DenyHeaders("x-ctx-debug"))
+	assert.Equal(t, map[string][]string{
+		"x-ctx-tenant": {"acme"},
+		"X-CTX-Region": {"eu"},
+		"x-request-id": {"req-1"},
+	}, cfg.filter(headers))
+}
+
+// setDefaultPolicy 在测试期间设置默认传递策略，结束后恢复
+func setDefaultPolicy(t *testing.T, policy PropagationPolicy) {
+	previous := DefaultPropagationPolicy()
+	SetDefaultPropagationPolicy(policy)
+	t.Cleanup(func() { SetDefaultPropagationPolicy(previous) })
+}
+
+func TestPropagationPolicyRules(t *testing.T) {
+	headers := map[string][]string{
+		"x-request-id": {"req-1"},
+		"x-ctx-tenant": {"acme"},
+		"x-ctx-debug":  {"on"},
+		"x-internal":   {"1"},
+		"user-agent":   {"grpc-go"},
+	}
+	tests := []struct {
+		name   string
+		policy PropagationPolicy
+		want   []string
+	}{
+		{
+			name:   "zero policy allows all but transport headers",
+			policy: PropagationPolicy{},
+			want:   []string{"x-ctx-debug", "x-ctx-tenant", "x-internal", "x-request-id"},
+		},
+		{
+			name:   "allow keys",
+			policy: PropagationPolicy{AllowKeys: []string{"X-Request-ID"}},
+			want:   []string{"x-request-id"},
+		},
+		{
+			name:   "allow prefixes",
+			policy: PropagationPolicy{AllowPrefixes: []string{"x-ctx-"}},
+			want:   []string{"x-ctx-debug", "x-ctx-tenant"},
+		},
+		{
+			name:   "allow keys and prefixes are combined",
+			policy: PropagationPolicy{AllowKeys: []string{"x-request-id"}, AllowPrefixes: []string{"x-ctx-"}},
+			want:   []string{"x-ctx-debug", "x-ctx-tenant", "x-request-id"},
+		},
+		{
+			name:   "deny keys only",
+			policy: PropagationPolicy{DenyKeys: []string{"x-internal"}},
+			want:   []string{"x-ctx-debug", "x-ctx-tenant", "x-request-id"},
+		},
+		{
+			name:   "deny beats allow key and prefix",
+			policy: PropagationPolicy{AllowKeys: []string{"x-request-id", "x-internal"}, AllowPrefixes: []string{"x-ctx-"}, DenyKeys: []string{"x-internal", "X-Ctx-Debug"}},
+			want:   []string{"x-ctx-tenant", "x-request-id"},
+		},
+		{
+			name:   "transport headers cannot be allowed",
+			policy: PropagationPolicy{AllowKeys: []string{"user-agent"}},
+			want:   []string{},
+		},
+		{
+			name:   "max keys keeps the first keys in order",
+			policy: PropagationPolicy{MaxKeys: 2},
+			want:   []string{"x-ctx-debug", "x-ctx-tenant"},
+		},
+		{
+			name:   "max keys counts only allowed keys",
+			policy: PropagationPolicy{DenyKeys: []string{"x-ctx-debug"}, MaxKeys: 2},
+			want:   []string{"x-ctx-tenant", "x-internal"},
+		},
+		{
+			name:   "max value bytes drops long values",
+			policy: PropagationPolicy{MaxValueBytes: 2},
+			want:   []string{"x-ctx-debug", "x-internal"},
+		},
+		{
+			name:   "value limit applies before key limit",
+			policy: PropagationPolicy{MaxValueBytes: 2, MaxKeys: 1},
+			want:   []string{"x-ctx-debug"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := newPropagationConfig(WithPropagationPolicy(tt.policy)).filter(headers)
+			keys := make([]string, 0, len(filtered))
+			for key := range filtered {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, tt.want, keys)
+		})
+	}
+}
+
+func TestPropagationPolicyMultiValue(t *testing.T) {
+	headers := map[string][]string{
+		"x-a": {"1", "long-value"},
+		"x-b": {"1", "2", "3"},
+	}
+
+	// 任意一个值超出限制时整体丢弃
+	cfg := newPropagationConfig(WithPropagationPolicy(PropagationPolicy{MaxValueBytes: 4}))
+	assert.Equal(t, map[string][]string{"x-b": {"1", "2", "3"}}, cfg.filter(headers))
+
+	// 数量限制与总大小限制同时生效，放不下的 header 不计入数量
+	cfg = newPropagationConfig(WithPropagationPolicy(PropagationPolicy{MaxKeys: 1}), MaxPropagatedBytes(len("x-b")*3+3))
+	assert.Equal(t, map[string][]string{"x-b": {"1", "2", "3"}}, cfg.filter(headers))
+}
+
+func TestDefaultPropagationPolicy(t *testing.T) {
+	assert.Equal(t, PropagationPolicy{}, DefaultPropagationPolicy())
+
+	allow := []string{"x-request-id"}
+	setDefaultPolicy(t, PropagationPolicy{AllowKeys: allow, AllowPrefixes: []string{"x-ctx-"}, DenyKeys: []string{"x-ctx-debug"}})
+	// 保存的是副本，修改原切片不影响默认策略
+	allow[0] = "x-other"
+	assert.Equal(t, []string{"x-request-id"}, DefaultPropagationPolicy().AllowKeys)
+
+	headers := map[string][]string{
+		"x-request-id": {"req-1"},
+		"x-ctx-tenant": {"acme"},
+		"x-ctx-debug":  {"on"},
+		"x-other":      {"1"},
+	}
+	assert.Equal(t, map[string][]string{
+		"x-request-id": {"req-1"},
+		"x-ctx-tenant": {"acme"},
+	}, newPropagationConfig().filter(headers))
+
+	// 选项在默认策略的基础上追加
+	assert.Equal(t, map[string][]string{
+		"x-request-id": {"req-1"},
+		"x-other":      {"1"},
+	}, newPropagationConfig(AllowHeaders("x-other"), DenyHeaders("x-ctx-tenant")).filter(headers))
+
+	// 显式的策略替换默认策略
+	assert.Equal(t, map[string][]string{"x-other": {"1"}},
+		newPropagationConfig(WithPropagationPolicy(PropagationPolicy{AllowKeys: []string{"x-other"}})).filter(headers))
+}
+
+func TestDefaultPropagationPolicyHelpers(t *testing.T) {
+	setDefaultPolicy(t, PropagationPolicy{
+		AllowKeys:     []string{"x-request-id"},
+		AllowPrefixes: []string{"x-ctx-"},
+		DenyKeys:      []string{"x-ctx-debug"},
+		MaxValueBytes: 16,
+	})
+
+	ctx := SetRPCHeaders(context.Background(), map[string]string{
+		"x-request-id": "req-1",
+		"x-ctx-tenant": "acme",
+		"x-ctx-debug":  "on",
+		"x-ctx-blob":   strings.Repeat("a", 17),
+		"x-internal":   "1",
+	})
+
+	// 注入使用默认策略
+	h := http.Header{}
+	InjectHTTPHeaders(ctx, h)
+	assert.Equal(t, http.Header{
+		"X-Request-Id": {"req-1"},
+		"X-Ctx-Tenant": {"acme"},
+	}, h)
+
+	// 提取同样使用默认策略，HTTPMiddleware 的默认请求头追加到允许列表中
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Ctx-Tenant", "acme")
+	req.Header.Set("X-Ctx-Debug", "on")
+	req.Header.Set("X-Priority", "low")
+	req.Header.Set("X-Internal", "1")
+	assert.Equal(t, map[string][]string{
+		"x-ctx-tenant": {"acme"},
+		"x-priority":   {"low"},
+	}, serveWithMiddleware(t, req))
+
+	// gRPC 拦截器同样使用默认策略
+	md, _ := metadata.FromOutgoingContext(injectGRPCHeaders(ctx, newPropagationConfig()))
+	assert.Equal(t, metadata.MD{
+		"x-request-id": {"req-1"},
+		"x-ctx-tenant": {"acme"},
+	}, md)
+	md.Set("x-internal", "1")
+	extracted := extractGRPCHeaders(metadata.NewIncomingContext(context.Background(), md), newPropagationConfig())
+	assert.Equal(t, map[string][]string{
+		"x-request-id": {"req-1"},
+		"x-ctx-tenant": {"acme"},
+	}, GetRPCHeadersMulti(extracted))
+}
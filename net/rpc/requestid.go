@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync/atomic"
+)
+
+// RequestIDHeader 请求 ID 的 header 名称
+//
+// 请求 ID 以普通 RPC header 的形式存储在上下文中，HTTPMiddleware 默认提取 X-Request-Id 请求头，
+// InjectHTTPHeaders 和 gRPC 客户端拦截器会将其传递到下游调用。
+const RequestIDHeader = "x-request-id"
+
+// requestIDGenerator EnsureRequestID 使用的请求 ID 生成函数，为 nil 时使用 NewRequestID
+var requestIDGenerator atomic.Pointer[func() string]
+
+// SetRequestIDGenerator 设置 EnsureRequestID 生成请求 ID 的函数，可以替换为 ULID 等格式，或在测试中返回固定值
+//
+// 参数:
+//   - gen: 生成函数，需要并发安全，为 nil 时恢复为默认的 NewRequestID
+//
+// 示例:
+//
+//	SetRequestIDGenerator(func() string { return ulid.Make().String() })
+func SetRequestIDGenerator(gen func() string) {
+	if gen == nil {
+		requestIDGenerator.Store(nil)
+		return
+	}
+	requestIDGenerator.Store(&gen)
+}
+
+// NewRequestID 生成一个随机的 UUID（版本 4）作为请求 ID
+func NewRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// RequestIDFromContext 从上下文中获取请求 ID
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - string: 请求 ID
+//   - bool: 是否设置了非空的请求 ID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := GetRPCHeader(ctx, RequestIDHeader)
+	return id, ok && id != ""
+}
+
+// WithRequestID 在上下文中设置请求 ID，替换已有的值
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - id: 请求 ID
+//
+// 返回值:
+//   - context.Context: 新的上下文，包含请求 ID header
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return SetRPCHeader(ctx, RequestIDHeader, id)
+}
+
+// EnsureRequestID 确保上下文中有请求 ID，没有时生成一个新的
+//
+// 上下文中已有非空的请求 ID（如 HTTPMiddleware 从请求头中提取的值）时沿用，
+// 否则通过 SetRequestIDGenerator 设置的函数生成，默认为 UUID（版本 4）。
+//
+// 参数:
+//   - ctx: 原始上下文
+//
+// 返回值:
+//   - context.Context: 包含请求 ID 的上下文，已有请求 ID 时直接返回 ctx
+//   - string: 请求 ID
+//
+// 示例:
+//
+//	ctx, requestID := EnsureRequestID(r.Context())
+//	w.Header().Set("X-Request-Id", requestID)
+//	logger := zap.L().With(zap.String("request_id", requestID))
+func EnsureRequestID(ctx context.Context) (context.Context, string) {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return ctx, id
+	}
+	gen := NewRequestID
+	if custom := requestIDGenerator.Load(); custom != nil {
+		gen = *custom
+	}
+	id := gen()
+	return WithRequestID(ctx, id), id
+}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+// setRequestIDGenerator 在测试期间设置请求 ID 生成函数，结束后恢复默认
+func setRequestIDGenerator(t *testing.T, gen func() string) {
+	SetRequestIDGenerator(gen)
+	t.Cleanup(func() { SetRequestIDGenerator(nil) })
+}
+
+func TestRequestID(t *testing.T) {
+	// 未设置
+	id, ok := RequestIDFromContext(context.Background())
+	assert.False(t, ok)
+	assert.Empty(t, id)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	id, ok = RequestIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "req-1", id)
+	assert.Equal(t, map[string]string{RequestIDHeader: "req-1"}, GetRPCHeaders(ctx))
+
+	// 空值视为未设置
+	_, ok = RequestIDFromContext(WithRequestID(ctx, ""))
+	assert.False(t, ok)
+}
+
+func TestEnsureRequestID(t *testing.T) {
+	// 默认生成 UUID（版本 4），每次不同
+	ctx, id := EnsureRequestID(context.Background())
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	stored, _ := RequestIDFromContext(ctx)
+	assert.Equal(t, id, stored)
+	_, other := EnsureRequestID(context.Background())
+	assert.NotEqual(t, id, other)
+
+	// 已有请求 ID 时沿用，不生成新的
+	calls := 0
+	setRequestIDGenerator(t, func() string {
+		calls++
+		return "generated"
+	})
+	existing := WithRequestID(context.Background(), "req-1")
+	ctx, id = EnsureRequestID(existing)
+	assert.Equal(t, "req-1", id)
+	assert.Equal(t, existing, ctx)
+	assert.Zero(t, calls)
+
+	// 使用自定义的生成函数，空值视为没有请求 ID
+	ctx, id = EnsureRequestID(WithRequestID(context.Background(), ""))
+	assert.Equal(t, "generated", id)
+	stored, _ = RequestIDFromContext(ctx)
+	assert.Equal(t, "generated", stored)
+	assert.Equal(t, 1, calls)
+
+	// 恢复默认
+	SetRequestIDGenerator(nil)
+	_, id = EnsureRequestID(context.Background())
+	assert.NotEqual(t, "generated", id)
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	setRequestIDGenerator(t, func() string { return "generated" })
+
+	// 从请求头中提取后沿用
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-Id", "from-client")
+	var id string
+	HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, id = EnsureRequestID(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "from-client", id)
+
+	// 生成的请求 ID 通过注入函数传递到下游
+	ctx, _ := EnsureRequestID(context.Background())
+	h := http.Header{}
+	InjectHTTPHeaders(ctx, h)
+	assert.Equal(t, http.Header{"X-Request-Id": {"generated"}}, h)
+
+	md, _ := metadata.FromOutgoingContext(OutgoingGRPCContext(ctx))
+	assert.Equal(t, []string{"generated"}, md.Get(RequestIDHeader))
+}
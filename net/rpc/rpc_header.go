@@ -2,22 +2,72 @@ package rpc
 
 import (
 	"context"
-	"sync"
+	"slices"
+	"sort"
+	"strings"
 )
 
-// headerKey 用于在 context 中存储 header 的 key
-type headerKey string
+// headersKey 用于在 context 中存储 headers 的 key
+type headersKey struct{}
 
-// headerKeysKey 用于在 context 中存储所有 header keys 的 key
-const headerKeysKey headerKey = "__header_keys__"
+// headersFrom 返回上下文中存储的 headers，返回的 map 和其中的切片都不可修改
+//
+// headers 以不可变 map 的形式存储在单个 context 值中，每次设置都复制出新的 map（写时复制），
+// 已经创建的上下文不会被修改，也不需要包级别的全局状态，上下文被丢弃后 headers 随之回收。
+// 每个 key 对应按添加顺序排列的一个或多个值，不存在值为空切片的 key。
+func headersFrom(ctx context.Context) map[string][]string {
+	if ctx == nil {
+		return nil
+	}
+	headers, _ := ctx.Value(headersKey{}).(map[string][]string)
+	return headers
+}
 
-var (
-	headerKeysMutex sync.RWMutex
-	headerKeysMap   = make(map[context.Context][]string)
-)
+// withHeaders 复制上下文中已有的 headers 并用 updates 替换同名 header 的所有值，返回存储了新 headers 的上下文
+func withHeaders(ctx context.Context, updates map[string]string) context.Context {
+	headers := copyHeaders(headersFrom(ctx), len(updates))
+	for key, value := range updates {
+		headers[key] = []string{value}
+	}
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// copyHeaders 浅复制 headers，并为之后写入的 extra 个 key 预留空间
+//
+// 值切片在新旧 map 之间共享，因此任何修改都必须替换切片，而不能原地修改。
+func copyHeaders(current map[string][]string, extra int) map[string][]string {
+	headers := make(map[string][]string, len(current)+extra)
+	for key, values := range current {
+		headers[key] = values
+	}
+	return headers
+}
+
+// mergeHeaders 用 updates 替换上下文中同名 header 的所有值，updates 为空时直接返回 ctx
+func mergeHeaders(ctx context.Context, updates map[string][]string) context.Context {
+	if len(updates) == 0 {
+		return ctx
+	}
+	headers := copyHeaders(headersFrom(ctx), len(updates))
+	for key, values := range updates {
+		headers[key] = values
+	}
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// lastValues 返回每个 header 的最后一个值
+func lastValues(current map[string][]string) map[string]string {
+	headers := make(map[string]string, len(current))
+	for key, values := range current {
+		headers[key] = values[len(values)-1]
+	}
+	return headers
+}
 
 // GetRPCHeader 从上下文中获取指定的 header 值
 //
+// header 有多个值时返回最后添加的值，需要所有值时使用 GetRPCHeaderValues。
+//
 // 参数:
 //   - ctx: 上下文
 //   - key: header 的键名
@@ -26,21 +76,14 @@ var (
 //   - string: header 的值
 //   - bool: 是否存在该 header
 func GetRPCHeader(ctx context.Context, key string) (string, bool) {
-	if ctx == nil {
-		return "", false
-	}
-	value := ctx.Value(headerKey(key))
-	if value == nil {
-		return "", false
-	}
-	strValue, ok := value.(string)
-	if !ok {
+	values := headersFrom(ctx)[key]
+	if len(values) == 0 {
 		return "", false
 	}
-	return strValue, true
+	return values[len(values)-1], true
 }
 
-// SetRPCHeader 在上下文中设置 header
+// SetRPCHeader 在上下文中设置 header，替换该 header 已有的所有值
 //
 // 参数:
 //   - ctx: 原始上下文
@@ -50,58 +93,78 @@ func GetRPCHeader(ctx context.Context, key string) (string, bool) {
 // 返回值:
 //   - context.Context: 新的上下文，包含设置的 header
 func SetRPCHeader(ctx context.Context, key, value string) context.Context {
-	// 设置 header 值
-	newCtx := context.WithValue(ctx, headerKey(key), value)
-
-	// 更新 header keys
-	headerKeysMutex.Lock()
-	defer headerKeysMutex.Unlock()
-
-	keys := headerKeysMap[ctx]
-	found := false
-	for _, k := range keys {
-		if k == key {
-			found = true
-			break
-		}
-	}
-	if !found {
-		keys = append(keys, key)
-	}
-	headerKeysMap[newCtx] = keys
-	delete(headerKeysMap, ctx) // 清理旧的 context
+	headers := copyHeaders(headersFrom(ctx), 1)
+	headers[key] = []string{value}
+	return context.WithValue(ctx, headersKey{}, headers)
+}
 
-	return newCtx
+// AddRPCHeader 在上下文中为 header 追加一个值，已有的值保持不变
+//
+// 用于 gRPC metadata 和 HTTP 中允许重复的 header，如多条 warning 或多跳的 x-forwarded-for。
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - key: header 的键名
+//   - value: 追加的值
+//
+// 返回值:
+//   - context.Context: 新的上下文，该 header 的值列表末尾增加了 value
+//
+// 示例:
+//
+//	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.1")
+//	ctx = AddRPCHeader(ctx, "x-forwarded-for", "10.0.0.2")
+//	values := GetRPCHeaderValues(ctx, "x-forwarded-for") // [10.0.0.1 10.0.0.2]
+func AddRPCHeader(ctx context.Context, key, value string) context.Context {
+	current := headersFrom(ctx)
+	headers := copyHeaders(current, 1)
+	// Clip 保证 append 分配新的底层数组，不会影响共享同一切片的其他上下文
+	headers[key] = append(slices.Clip(current[key]), value)
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// GetRPCHeaderValues 获取指定 header 的所有值
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: header 的键名
+//
+// 返回值:
+//   - []string: 按添加顺序排列的值的副本，header 不存在时为 nil
+func GetRPCHeaderValues(ctx context.Context, key string) []string {
+	return slices.Clone(headersFrom(ctx)[key])
 }
 
 // GetRPCHeaders 获取上下文中的所有 headers
 //
+// header 有多个值时只返回最后添加的值，需要所有值时使用 GetRPCHeadersMulti。
+//
 // 参数:
 //   - ctx: 上下文
 //
 // 返回值:
-//   - map[string]string: 所有的 headers
+//   - map[string]string: 所有 headers 的副本，修改不会影响上下文
 func GetRPCHeaders(ctx context.Context) map[string]string {
-	if ctx == nil {
-		return make(map[string]string)
-	}
-
-	headers := make(map[string]string)
-
-	headerKeysMutex.RLock()
-	keys := headerKeysMap[ctx]
-	headerKeysMutex.RUnlock()
+	return lastValues(headersFrom(ctx))
+}
 
-	for _, key := range keys {
-		if value, ok := GetRPCHeader(ctx, key); ok {
-			headers[key] = value
-		}
+// GetRPCHeadersMulti 获取上下文中的所有 headers 及其全部值
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - map[string][]string: 所有 headers 的副本，每个 header 的值按添加顺序排列，修改不会影响上下文
+func GetRPCHeadersMulti(ctx context.Context) map[string][]string {
+	current := headersFrom(ctx)
+	headers := make(map[string][]string, len(current))
+	for key, values := range current {
+		headers[key] = slices.Clone(values)
 	}
-
 	return headers
 }
 
-// SetRPCHeaders 在上下文中批量设置 headers
+// SetRPCHeaders 在上下文中批量设置 headers，每个 header 已有的所有值都会被替换
 //
 // 参数:
 //   - ctx: 原始上下文
@@ -110,9 +173,235 @@ func GetRPCHeaders(ctx context.Context) map[string]string {
 // 返回值:
 //   - context.Context: 新的上下文，包含设置的所有 headers
 func SetRPCHeaders(ctx context.Context, headers map[string]string) context.Context {
-	newCtx := ctx
-	for key, value := range headers {
-		newCtx = SetRPCHeader(newCtx, key, value)
+	if len(headers) == 0 {
+		return ctx
+	}
+	return withHeaders(ctx, headers)
+}
+
+// DeleteRPCHeader 从上下文中删除指定的 header
+//
+// 返回的上下文及其派生的上下文中不再包含该 header，父上下文不受影响，仍然保留原来的值。
+// 适用于在把上下文交给转发 headers 的代码之前去掉内部使用的 header。
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - key: header 的键名
+//
+// 返回值:
+//   - context.Context: 不包含该 header 的上下文，header 不存在时直接返回 ctx
+//
+// 示例:
+//
+//	ctx = DeleteRPCHeader(ctx, "x-debug")
+//	headers := GetRPCHeaders(ctx) // 不包含 x-debug
+func DeleteRPCHeader(ctx context.Context, key string) context.Context {
+	current := headersFrom(ctx)
+	if _, ok := current[key]; !ok {
+		return ctx
+	}
+	headers := copyHeaders(current, 0)
+	delete(headers, key)
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// UpdateRPCHeaders 在一次操作中批量修改上下文中的 headers
+//
+// fn 收到所有 headers 的可修改副本（每个 header 取最后一个值），可以在其中任意设置和删除，
+// fn 返回后所有修改一次性生效，只创建一个新的上下文。
+// 值被修改的 header 的所有值都会被替换，未修改的 header 保留原有的多个值，被删除的 header 不再存在。
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - fn: 修改 headers 的函数，不能在 fn 返回后继续使用收到的 map
+//
+// 返回值:
+//   - context.Context: 包含修改后 headers 的上下文，没有任何修改时直接返回 ctx
+//
+// 示例:
+//
+//	ctx = UpdateRPCHeaders(ctx, func(h map[string]string) {
+//	    h["x-caller"] = "billing"
+//	    h["x-request-id"] = newRequestID()
+//	    delete(h, "x-debug")
+//	})
+func UpdateRPCHeaders(ctx context.Context, fn func(h map[string]string)) context.Context {
+	current := headersFrom(ctx)
+	updated := lastValues(current)
+	fn(updated)
+
+	var headers map[string][]string
+	// 首次发现修改时才复制，没有修改时不产生新的上下文
+	mutate := func() map[string][]string {
+		if headers == nil {
+			headers = copyHeaders(current, len(updated))
+		}
+		return headers
+	}
+	for key, values := range current {
+		value, ok := updated[key]
+		if !ok {
+			delete(mutate(), key)
+		} else if value != values[len(values)-1] {
+			mutate()[key] = []string{value}
+		}
+	}
+	for key, value := range updated {
+		if _, ok := current[key]; !ok {
+			mutate()[key] = []string{value}
+		}
+	}
+	if headers == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// HasAnyRPCHeaders 判断上下文中是否设置了任意 header
+//
+// 与 len(GetRPCHeaders(ctx)) > 0 相比，不会构建 map，也不会产生内存分配，
+// 适合在热路径中快速跳过 header 导出逻辑。
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - bool: 至少存在一个 header 时返回 true
+func HasAnyRPCHeaders(ctx context.Context) bool {
+	return len(headersFrom(ctx)) > 0
+}
+
+// HasRPCHeader 判断上下文中是否设置了指定的 header
+//
+// 只进行一次查找，不会构建 map。
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: header 的键名
+//
+// 返回值:
+//   - bool: 存在该 header 时返回 true，通过 DeleteRPCHeader 删除的 header 返回 false
+func HasRPCHeader(ctx context.Context, key string) bool {
+	_, ok := headersFrom(ctx)[key]
+	return ok
+}
+
+// RPCHeaderKeys 获取上下文中所有 header 的键名，按字典序排列
+//
+// 适用于只需要记录请求携带了哪些 header 的场景，不会复制 header 的值。
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - []string: 排序后的键名，没有 header 时为空切片
+//
+// 示例:
+//
+//	zap.L().Info("rpc headers", zap.Strings("keys", RPCHeaderKeys(ctx)))
+func RPCHeaderKeys(ctx context.Context) []string {
+	headers := headersFrom(ctx)
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
 	}
-	return newCtx
+	sort.Strings(keys)
+	return keys
+}
+
+// SortedRPCHeaders 获取上下文中的所有 headers，并按规范化后的 key 排序，可作为请求签名的规范化输入
+//
+// key 先转换为小写再按字节序排序，大小写不同的同名 header（如 "X-Foo" 和 "x-foo"）合并为一项，
+// 与 ToGRPCMetadata 一致，按原始 key 的字节序依次合并，取最后一个的最后添加的值。
+// 因此相同的 headers 总是得到相同的结果。
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - []struct{ Key, Value string }: 按小写 key 升序排列的 headers，key 为小写形式，没有 header 时为空切片
+//
+// 示例:
+//
+//	for _, h := range SortedRPCHeaders(ctx) {
+//	    canonical.WriteString(h.Key + ":" + h.Value + "\n")
+//	}
+func SortedRPCHeaders(ctx context.Context) []struct{ Key, Value string } {
+	current := headersFrom(ctx)
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	merged := make(map[string]string, len(keys))
+	for _, key := range keys {
+		values := current[key]
+		merged[strings.ToLower(key)] = values[len(values)-1]
+	}
+	entries := make([]struct{ Key, Value string }, 0, len(merged))
+	for key, value := range merged {
+		entries = append(entries, struct{ Key, Value string }{key, value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// GetRPCHeadersByPrefix 获取上下文中 key 以 prefix 开头的 headers
+//
+// 前缀匹配不区分大小写，"X-Ctx-" 与 "x-ctx-tenant" 匹配；返回的 key 保持设置时的原样。
+// 与 GetRPCHeaders 一致，header 有多个值时只返回最后添加的值。
+//
+// 参数:
+//   - ctx: 上下文
+//   - prefix: key 的前缀，为空时返回所有 headers
+//
+// 返回值:
+//   - map[string]string: 匹配的 headers，没有匹配时为空 map
+//
+// 示例:
+//
+//	headers := GetRPCHeadersByPrefix(ctx, "x-ctx-")
+//	// map[x-ctx-tenant:acme x-ctx-region:eu]
+func GetRPCHeadersByPrefix(ctx context.Context, prefix string) map[string]string {
+	return filterRPCHeaders(ctx, prefix, false)
+}
+
+// GetRPCHeadersByPrefixTrimmed 获取上下文中 key 以 prefix 开头的 headers，并去掉 key 中的前缀
+//
+// 前缀匹配规则与 GetRPCHeadersByPrefix 相同，去掉前缀后为空的 key 会被忽略。
+//
+// 参数:
+//   - ctx: 上下文
+//   - prefix: key 的前缀
+//
+// 返回值:
+//   - map[string]string: 去掉前缀后的 headers，没有匹配时为空 map
+//
+// 示例:
+//
+//	headers := GetRPCHeadersByPrefixTrimmed(ctx, "x-ctx-")
+//	// map[tenant:acme region:eu]
+func GetRPCHeadersByPrefixTrimmed(ctx context.Context, prefix string) map[string]string {
+	return filterRPCHeaders(ctx, prefix, true)
+}
+
+// filterRPCHeaders 按前缀（不区分大小写）筛选 headers，trim 为 true 时去掉 key 中的前缀
+func filterRPCHeaders(ctx context.Context, prefix string, trim bool) map[string]string {
+	headers := make(map[string]string)
+	for key, values := range headersFrom(ctx) {
+		if len(key) < len(prefix) || !strings.EqualFold(key[:len(prefix)], prefix) {
+			continue
+		}
+		if trim {
+			key = key[len(prefix):]
+			if key == "" {
+				continue
+			}
+		}
+		headers[key] = values[len(values)-1]
+	}
+	return headers
 }
@@ -2,7 +2,10 @@ package rpc
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -164,3 +167,428 @@ func TestRPCHeadersOverwrite(t *testing.T) {
 	assert.True(t, ok, "header should be set")
 	assert.Equal(t, "value2", value, "value should be overwritten")
 }
+
+func TestDeleteRPCHeader(t *testing.T) {
+	parent := SetRPCHeaders(context.Background(), map[string]string{"x-debug": "1", "x-user-id": "42"})
+	child := DeleteRPCHeader(parent, "x-debug")
+
+	// 删除后子上下文中不存在该 header
+	_, ok := GetRPCHeader(child, "x-debug")
+	assert.False(t, ok)
+	assert.Equal(t, map[string]string{"x-user-id": "42"}, GetRPCHeaders(child))
+
+	// 父上下文不受影响
+	value, ok := GetRPCHeader(parent, "x-debug")
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+	assert.Equal(t, map[string]string{"x-debug": "1", "x-user-id": "42"}, GetRPCHeaders(parent))
+
+	// 删除对派生的上下文同样生效
+	derived, cancel := context.WithCancel(child)
+	defer cancel()
+	_, ok = GetRPCHeader(derived, "x-debug")
+	assert.False(t, ok)
+
+	// 删除后可以重新设置
+	reset := SetRPCHeader(derived, "x-debug", "2")
+	value, ok = GetRPCHeader(reset, "x-debug")
+	assert.True(t, ok)
+	assert.Equal(t, "2", value)
+
+	// 删除不存在的 header 直接返回原上下文
+	assert.Equal(t, child, DeleteRPCHeader(child, "x-debug"))
+	assert.Equal(t, child, DeleteRPCHeader(child, "x-missing"))
+
+	// 删除最后一个 header
+	empty := DeleteRPCHeader(child, "x-user-id")
+	assert.False(t, HasAnyRPCHeaders(empty))
+	assert.Nil(t, DeleteRPCHeader(nil, "x-debug"))
+}
+
+func TestUpdateRPCHeaders(t *testing.T) {
+	parent := SetRPCHeaders(context.Background(), map[string]string{"x-a": "1", "x-b": "2", "x-debug": "on"})
+	parent = AddRPCHeader(parent, "x-hop", "a")
+	parent = AddRPCHeader(parent, "x-hop", "b")
+
+	ctx := UpdateRPCHeaders(parent, func(h map[string]string) {
+		assert.Equal(t, map[string]string{"x-a": "1", "x-b": "2", "x-debug": "on", "x-hop": "b"}, h)
+		h["x-a"] = "10"
+		h["x-c"] = "3"
+		delete(h, "x-debug")
+	})
+
+	// 修改一次性生效，未修改的 header 保留多个值
+	assert.Equal(t, map[string][]string{
+		"x-a":   {"10"},
+		"x-b":   {"2"},
+		"x-c":   {"3"},
+		"x-hop": {"a", "b"},
+	}, GetRPCHeadersMulti(ctx))
+	// 父上下文不受影响
+	assert.Equal(t, map[string]string{"x-a": "1", "x-b": "2", "x-debug": "on", "x-hop": "b"}, GetRPCHeaders(parent))
+
+	// 修改多值 header 时替换所有值
+	replaced := UpdateRPCHeaders(parent, func(h map[string]string) {
+		h["x-hop"] = "c"
+	})
+	assert.Equal(t, []string{"c"}, GetRPCHeaderValues(replaced, "x-hop"))
+
+	// 没有修改时返回原上下文
+	assert.Equal(t, parent, UpdateRPCHeaders(parent, func(h map[string]string) {
+		h["x-a"] = "1"
+	}))
+
+	// 空上下文
+	ctx = UpdateRPCHeaders(context.Background(), func(h map[string]string) {
+		h["x-new"] = "1"
+	})
+	assert.Equal(t, map[string]string{"x-new": "1"}, GetRPCHeaders(ctx))
+	empty := UpdateRPCHeaders(ctx, func(h map[string]string) {
+		delete(h, "x-new")
+	})
+	assert.False(t, HasAnyRPCHeaders(empty))
+}
+
+func TestHasAnyRPCHeaders(t *testing.T) {
+	// 空上下文
+	assert.False(t, HasAnyRPCHeaders(context.Background()), "empty context should have no headers")
+	assert.False(t, HasAnyRPCHeaders(nil), "nil context should have no headers")
+
+	// 设置 header 后
+	ctx := SetRPCHeader(context.Background(), "key", "value")
+	assert.True(t, HasAnyRPCHeaders(ctx), "context should have headers")
+
+	// 批量设置
+	ctx = SetRPCHeaders(context.Background(), map[string]string{"key1": "value1", "key2": "value2"})
+	assert.True(t, HasAnyRPCHeaders(ctx), "context should have headers")
+}
+
+func BenchmarkHasAnyRPCHeaders(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = HasAnyRPCHeaders(ctx)
+	}
+}
+
+func BenchmarkHasAnyRPCHeadersByMap(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = len(GetRPCHeaders(ctx)) > 0
+	}
+}
+
+// headersContext 返回设置了 n 个 header 的上下文
+func headersContext(n int) context.Context {
+	headers := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		headers["x-key-"+strconv.Itoa(i)] = "value-" + strconv.Itoa(i)
+	}
+	return SetRPCHeaders(context.Background(), headers)
+}
+
+func TestRPCHeaderReadAllocs(t *testing.T) {
+	ctx, cancel := context.WithCancel(headersContext(50))
+	defer cancel()
+
+	// 读取单个 header 只需要一次 Value 查找，不分配内存
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_, _ = GetRPCHeader(ctx, "x-key-10")
+	}))
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = HasAnyRPCHeaders(ctx)
+	}))
+}
+
+func BenchmarkSetRPCHeader(b *testing.B) {
+	for _, n := range []int{1, 10, 50} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			ctx := headersContext(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = SetRPCHeader(ctx, "x-request-id", "req")
+			}
+		})
+	}
+}
+
+func BenchmarkGetRPCHeader(b *testing.B) {
+	for _, n := range []int{1, 10, 50} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			ctx := headersContext(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = GetRPCHeader(ctx, "x-key-0")
+			}
+		})
+	}
+}
+
+func BenchmarkGetRPCHeaders(b *testing.B) {
+	for _, n := range []int{1, 10, 50} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			ctx := headersContext(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = GetRPCHeaders(ctx)
+			}
+		})
+	}
+}
+
+func TestAddRPCHeader(t *testing.T) {
+	ctx := AddRPCHeader(context.Background(), "warning", "199 - first")
+	ctx = AddRPCHeader(ctx, "warning", "199 - second")
+	ctx = AddRPCHeader(ctx, "x-a", "1")
+
+	// 按添加顺序返回所有值，单值接口返回最后一个值
+	assert.Equal(t, []string{"199 - first", "199 - second"}, GetRPCHeaderValues(ctx, "warning"))
+	value, ok := GetRPCHeader(ctx, "warning")
+	assert.True(t, ok)
+	assert.Equal(t, "199 - second", value)
+	assert.Equal(t, map[string]string{"warning": "199 - second", "x-a": "1"}, GetRPCHeaders(ctx))
+	assert.Equal(t, map[string][]string{"warning": {"199 - first", "199 - second"}, "x-a": {"1"}}, GetRPCHeadersMulti(ctx))
+	assert.Nil(t, GetRPCHeaderValues(ctx, "x-missing"))
+	assert.Empty(t, GetRPCHeadersMulti(nil))
+
+	// Set 替换所有值
+	replaced := SetRPCHeader(ctx, "warning", "199 - only")
+	assert.Equal(t, []string{"199 - only"}, GetRPCHeaderValues(replaced, "warning"))
+
+	// 批量设置同样替换所有值，未涉及的 header 保留多个值
+	batch := AddRPCHeader(ctx, "x-a", "2")
+	batch = SetRPCHeaders(batch, map[string]string{"x-a": "3"})
+	assert.Equal(t, []string{"3"}, GetRPCHeaderValues(batch, "x-a"))
+	assert.Equal(t, []string{"199 - first", "199 - second"}, GetRPCHeaderValues(batch, "warning"))
+
+	// Set 之后可以继续追加
+	appended := AddRPCHeader(replaced, "warning", "199 - more")
+	assert.Equal(t, []string{"199 - only", "199 - more"}, GetRPCHeaderValues(appended, "warning"))
+
+	// 删除移除所有值
+	assert.Nil(t, GetRPCHeaderValues(DeleteRPCHeader(ctx, "warning"), "warning"))
+}
+
+func TestAddRPCHeaderImmutable(t *testing.T) {
+	parent := AddRPCHeader(context.Background(), "x-hop", "a")
+	parent = AddRPCHeader(parent, "x-hop", "b")
+
+	// 从同一个父上下文追加不会互相影响
+	left := AddRPCHeader(parent, "x-hop", "left")
+	right := AddRPCHeader(parent, "x-hop", "right")
+	assert.Equal(t, []string{"a", "b"}, GetRPCHeaderValues(parent, "x-hop"))
+	assert.Equal(t, []string{"a", "b", "left"}, GetRPCHeaderValues(left, "x-hop"))
+	assert.Equal(t, []string{"a", "b", "right"}, GetRPCHeaderValues(right, "x-hop"))
+
+	// 修改返回的切片不会影响上下文
+	values := GetRPCHeaderValues(parent, "x-hop")
+	values[0] = "changed"
+	GetRPCHeadersMulti(parent)["x-hop"][1] = "changed"
+	assert.Equal(t, []string{"a", "b"}, GetRPCHeaderValues(parent, "x-hop"))
+}
+
+func TestHasRPCHeader(t *testing.T) {
+	assert.False(t, HasRPCHeader(context.Background(), "x-a"))
+	assert.False(t, HasRPCHeader(nil, "x-a"))
+
+	ctx := SetRPCHeader(context.Background(), "x-a", "1")
+	ctx = SetRPCHeaders(ctx, map[string]string{"x-b": "2", "x-empty": ""})
+	assert.True(t, HasRPCHeader(ctx, "x-a"))
+	assert.True(t, HasRPCHeader(ctx, "x-b"))
+	// 值为空字符串的 header 同样存在
+	assert.True(t, HasRPCHeader(ctx, "x-empty"))
+	assert.False(t, HasRPCHeader(ctx, "x-c"))
+
+	deleted := DeleteRPCHeader(ctx, "x-a")
+	assert.False(t, HasRPCHeader(deleted, "x-a"))
+	assert.True(t, HasRPCHeader(ctx, "x-a"))
+}
+
+func TestRPCHeaderKeys(t *testing.T) {
+	assert.Empty(t, RPCHeaderKeys(context.Background()))
+	assert.Empty(t, RPCHeaderKeys(nil))
+
+	ctx := SetRPCHeader(context.Background(), "x-c", "3")
+	ctx = SetRPCHeaders(ctx, map[string]string{"x-b": "2", "x-a": "1"})
+	ctx = SetRPCHeader(ctx, "x-d", "4")
+	assert.Equal(t, []string{"x-a", "x-b", "x-c", "x-d"}, RPCHeaderKeys(ctx))
+
+	// 删除的 header 不出现在结果中
+	assert.Equal(t, []string{"x-a", "x-c", "x-d"}, RPCHeaderKeys(DeleteRPCHeader(ctx, "x-b")))
+}
+
+func TestSortedRPCHeaders(t *testing.T) {
+	ctx := context.Background()
+	ctx = SetRPCHeader(ctx, "x-b", "2")
+	ctx = SetRPCHeader(ctx, "X-C", "3")
+	ctx = SetRPCHeader(ctx, "x-a", "1")
+
+	// key 转换为小写后按字节序排序
+	expected := []struct{ Key, Value string }{
+		{Key: "x-a", Value: "1"},
+		{Key: "x-b", Value: "2"},
+		{Key: "x-c", Value: "3"},
+	}
+	assert.Equal(t, expected, SortedRPCHeaders(ctx))
+
+	// 多次调用结果稳定
+	assert.Equal(t, SortedRPCHeaders(ctx), SortedRPCHeaders(ctx))
+
+	// 大小写不同的同名 header 合并为一项，按原始 key 的字节序取最后一个的值
+	ctx = SetRPCHeader(ctx, "x-c", "lower")
+	ctx = AddRPCHeader(ctx, "X-Foo", "upper")
+	ctx = SetRPCHeader(ctx, "x-foo", "lower")
+	assert.Equal(t, []struct{ Key, Value string }{
+		{Key: "x-a", Value: "1"},
+		{Key: "x-b", Value: "2"},
+		{Key: "x-c", Value: "lower"},
+		{Key: "x-foo", Value: "lower"},
+	}, SortedRPCHeaders(ctx))
+
+	// 非 ASCII 字节排在 ASCII 之后
+	ctx = SetRPCHeader(context.Background(), "x-é", "1")
+	ctx = SetRPCHeader(ctx, "x-z", "2")
+	assert.Equal(t, []struct{ Key, Value string }{{Key: "x-z", Value: "2"}, {Key: "x-é", Value: "1"}}, SortedRPCHeaders(ctx))
+
+	// 空上下文
+	assert.Empty(t, SortedRPCHeaders(context.Background()))
+}
+
+func TestRPCHeadersImmutable(t *testing.T) {
+	parent := SetRPCHeader(context.Background(), "x-a", "1")
+	child := SetRPCHeader(parent, "x-b", "2")
+	child = SetRPCHeader(child, "x-a", "3")
+
+	// 设置 header 不会影响已有的上下文
+	assert.Equal(t, map[string]string{"x-a": "1"}, GetRPCHeaders(parent))
+	assert.Equal(t, map[string]string{"x-a": "3", "x-b": "2"}, GetRPCHeaders(child))
+
+	// 修改返回的 map 不会影响上下文
+	headers := GetRPCHeaders(parent)
+	headers["x-a"] = "changed"
+	value, _ := GetRPCHeader(parent, "x-a")
+	assert.Equal(t, "1", value)
+
+	// 派生的上下文同样可以导出 headers
+	derived, cancel := context.WithCancel(child)
+	defer cancel()
+	assert.Equal(t, map[string]string{"x-a": "3", "x-b": "2"}, GetRPCHeaders(derived))
+	assert.True(t, HasAnyRPCHeaders(derived))
+}
+
+func TestRPCHeadersDerivedContexts(t *testing.T) {
+	type ctxKey struct{}
+
+	ctx := SetRPCHeader(context.Background(), "x-a", "1")
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ctx = SetRPCHeader(ctx, "x-b", "2")
+	ctx, cancelTimeout := context.WithTimeout(ctx, time.Minute)
+	defer cancelTimeout()
+	ctx = context.WithValue(ctx, ctxKey{}, "value")
+	ctx = SetRPCHeaders(ctx, map[string]string{"x-c": "3"})
+	ctx, cancelDeadline := context.WithDeadline(ctx, time.Now().Add(time.Minute))
+	defer cancelDeadline()
+
+	// 中间插入的 WithCancel、WithTimeout、WithValue 不影响 headers 的导出
+	expected := map[string]string{"x-a": "1", "x-b": "2", "x-c": "3"}
+	assert.Equal(t, expected, GetRPCHeaders(ctx))
+	assert.Equal(t, expected, GetRPCHeadersByPrefix(ctx, "x-"))
+	assert.Equal(t, []struct{ Key, Value string }{{Key: "x-a", Value: "1"}, {Key: "x-b", Value: "2"}, {Key: "x-c", Value: "3"}}, SortedRPCHeaders(ctx))
+	assert.True(t, HasAnyRPCHeaders(ctx))
+	assert.Equal(t, "value", ctx.Value(ctxKey{}))
+
+	// 取消后 headers 仍然可用，便于在清理逻辑中记录
+	cancel()
+	<-ctx.Done()
+	assert.Equal(t, expected, GetRPCHeaders(ctx))
+
+	// 只带有非 header 值的上下文没有 headers
+	plain := context.WithValue(context.Background(), ctxKey{}, "value")
+	assert.Empty(t, GetRPCHeaders(plain))
+	assert.False(t, HasAnyRPCHeaders(plain))
+}
+
+func TestRPCHeadersNoLeak(t *testing.T) {
+	parent := SetRPCHeader(context.Background(), "x-request-id", "req")
+	before := headersFrom(parent)
+
+	// 派生上下文写入的是新的 map，父上下文保存的 map 不变
+	child := SetRPCHeader(parent, "x-request-id", "other")
+	child = AddRPCHeader(child, "x-forwarded-for", "10.0.0.1")
+	assert.Equal(t, map[string][]string{"x-request-id": {"req"}}, headersFrom(parent))
+	assert.Equal(t, map[string][]string{"x-request-id": {"req"}}, before)
+	assert.Equal(t, map[string]string{"x-request-id": "other", "x-forwarded-for": "10.0.0.1"}, GetRPCHeaders(child))
+
+	// headers 只保存在上下文中，丢弃上下文后没有任何包级别的状态残留
+	assert.Empty(t, GetRPCHeaders(context.Background()))
+	assert.False(t, HasAnyRPCHeaders(context.Background()))
+
+	// 每次设置的分配次数固定，不随调用次数增长
+	setAndGet := func() {
+		ctx := SetRPCHeader(context.Background(), "x-request-id", "req")
+		_ = GetRPCHeaders(ctx)
+	}
+	assert.Equal(t, testing.AllocsPerRun(100, setAndGet), testing.AllocsPerRun(1000, setAndGet))
+}
+
+func TestSetRPCHeaderConcurrentDerivation(t *testing.T) {
+	parent := SetRPCHeaders(context.Background(), map[string]string{"x-tenant-id": "acme", "x-user-id": "42"})
+
+	const goroutines = 100
+	var wg sync.WaitGroup
+	results := make([]map[string]string, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := SetRPCHeader(parent, "x-worker", strconv.Itoa(i))
+			ctx = SetRPCHeader(ctx, "x-step", "2")
+			results[i] = GetRPCHeaders(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	// 每个派生上下文都完整地包含父上下文和自己设置的 headers
+	for i, headers := range results {
+		assert.Equal(t, map[string]string{
+			"x-tenant-id": "acme",
+			"x-user-id":   "42",
+			"x-worker":    strconv.Itoa(i),
+			"x-step":      "2",
+		}, headers)
+	}
+	// 父上下文不受影响
+	assert.Equal(t, map[string]string{"x-tenant-id": "acme", "x-user-id": "42"}, GetRPCHeaders(parent))
+}
+
+func TestGetRPCHeadersByPrefix(t *testing.T) {
+	ctx := SetRPCHeaders(context.Background(), map[string]string{
+		"x-ctx-tenant": "acme",
+		"X-Ctx-Region": "eu",
+		"x-ctx-":       "empty",
+		"x-user-id":    "42",
+		"x-ct":         "short",
+	})
+
+	// 不区分大小写，key 保持原样
+	assert.Equal(t, map[string]string{
+		"x-ctx-tenant": "acme",
+		"X-Ctx-Region": "eu",
+		"x-ctx-":       "empty",
+	}, GetRPCHeadersByPrefix(ctx, "X-CTX-"))
+
+	// 去掉前缀，去掉后为空的 key 被忽略
+	assert.Equal(t, map[string]string{
+		"tenant": "acme",
+		"Region": "eu",
+	}, GetRPCHeadersByPrefixTrimmed(ctx, "x-ctx-"))
+
+	assert.Len(t, GetRPCHeadersByPrefix(ctx, ""), 5)
+	assert.Empty(t, GetRPCHeadersByPrefix(ctx, "x-none-"))
+	assert.Empty(t, GetRPCHeadersByPrefixTrimmed(context.Background(), "x-ctx-"))
+}
@@ -0,0 +1,26 @@
+package rpc
+
+import "context"
+
+// WithScopedHeaders 在 fn 执行期间使用覆盖后的 headers，fn 返回后外层上下文保持不变
+//
+// fn 收到的上下文包含外层的所有 headers，并用 overrides 覆盖同名的 header，
+// 适合中间件在子调用中临时修改 headers。fn 结束后外层上下文的 headers 与调用前完全一致，
+// 包括 GetRPCHeaders 导出的结果。
+//
+// 参数:
+//   - ctx: 外层上下文
+//   - overrides: 在 fn 中覆盖的 headers
+//   - fn: 使用覆盖后上下文执行的函数
+//
+// 返回值:
+//   - error: fn 返回的错误
+//
+// 示例:
+//
+//	err := WithScopedHeaders(ctx, map[string]string{"x-caller": "billing"}, func(ctx context.Context) error {
+//	    return client.Charge(ctx, req)
+//	})
+func WithScopedHeaders(ctx context.Context, overrides map[string]string, fn func(ctx context.Context) error) error {
+	return fn(SetRPCHeaders(ctx, overrides))
+}
@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithScopedHeaders(t *testing.T) {
+	ctx := SetRPCHeaders(context.Background(), map[string]string{"x-user-id": "42", "x-caller": "gateway"})
+
+	err := WithScopedHeaders(ctx, map[string]string{"x-caller": "billing", "x-priority": "low"}, func(ctx context.Context) error {
+		assert.Equal(t, map[string]string{
+			"x-user-id":  "42",
+			"x-caller":   "billing",
+			"x-priority": "low",
+		}, GetRPCHeaders(ctx))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// 外层上下文不受影响
+	assert.Equal(t, map[string]string{"x-user-id": "42", "x-caller": "gateway"}, GetRPCHeaders(ctx))
+	caller, _ := GetRPCHeader(ctx, "x-caller")
+	assert.Equal(t, "gateway", caller)
+	_, ok := GetRPCHeader(ctx, "x-priority")
+	assert.False(t, ok)
+}
+
+func TestWithScopedHeadersError(t *testing.T) {
+	errFailed := errors.New("failed")
+	ctx := SetRPCHeader(context.Background(), "x-user-id", "42")
+
+	err := WithScopedHeaders(ctx, map[string]string{"x-user-id": "7"}, func(ctx context.Context) error {
+		return errFailed
+	})
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, map[string]string{"x-user-id": "42"}, GetRPCHeaders(ctx))
+
+	// 外层没有 header，也没有覆盖
+	err = WithScopedHeaders(context.Background(), nil, func(ctx context.Context) error {
+		assert.False(t, HasAnyRPCHeaders(ctx))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, HasAnyRPCHeaders(context.Background()))
+}
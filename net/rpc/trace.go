@@ -0,0 +1,477 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 链路追踪相关的 header 名称
+const (
+	// TraceparentHeader W3C Trace Context 的 header 名称
+	TraceparentHeader = "traceparent"
+	// B3Header Zipkin B3 单 header 格式的 header 名称
+	B3Header = "b3"
+	// B3TraceIDHeader Zipkin B3 多 header 格式中 trace ID 的 header 名称
+	B3TraceIDHeader = "x-b3-traceid"
+	// B3SpanIDHeader Zipkin B3 多 header 格式中 span ID 的 header 名称
+	B3SpanIDHeader = "x-b3-spanid"
+	// B3ParentSpanIDHeader Zipkin B3 多 header 格式中父 span ID 的 header 名称
+	B3ParentSpanIDHeader = "x-b3-parentspanid"
+	// B3SampledHeader Zipkin B3 多 header 格式中采样标记的 header 名称
+	B3SampledHeader = "x-b3-sampled"
+	// B3FlagsHeader Zipkin B3 多 header 格式中 debug 标记的 header 名称
+	B3FlagsHeader = "x-b3-flags"
+)
+
+// traceHeaders 所有格式使用的 header，转换格式时会被整体替换
+var traceHeaders = []string{
+	TraceparentHeader,
+	B3Header,
+	B3TraceIDHeader,
+	B3SpanIDHeader,
+	B3ParentSpanIDHeader,
+	B3SampledHeader,
+	B3FlagsHeader,
+}
+
+// ErrInvalidTraceContext trace header 的格式不合法
+var ErrInvalidTraceContext = errors.New("invalid trace context")
+
+// TraceFormat 链路追踪信息的传递格式
+type TraceFormat int
+
+// 支持的传递格式
+const (
+	// TraceFormatW3C W3C Trace Context，即 traceparent header
+	TraceFormatW3C TraceFormat = iota + 1
+	// TraceFormatB3Multi Zipkin B3 多 header 格式，即 X-B3-TraceId、X-B3-SpanId 等
+	TraceFormatB3Multi
+	// TraceFormatB3Single Zipkin B3 单 header 格式，即 b3 header
+	TraceFormatB3Single
+)
+
+// DefaultTraceFormats 读取链路追踪信息时默认的格式优先级
+//
+// 多种格式同时存在且内容冲突时，W3C 优先，其次是 B3 单 header 格式，最后是 B3 多 header 格式。
+var DefaultTraceFormats = []TraceFormat{TraceFormatW3C, TraceFormatB3Single, TraceFormatB3Multi}
+
+// String 返回格式的名称
+func (f TraceFormat) String() string {
+	switch f {
+	case TraceFormatW3C:
+		return "w3c"
+	case TraceFormatB3Multi:
+		return "b3multi"
+	case TraceFormatB3Single:
+		return "b3single"
+	}
+	return fmt.Sprintf("TraceFormat(%d)", int(f))
+}
+
+// TraceContext 与格式无关的链路追踪信息
+//
+// 各种格式的 ID 在解析后统一为小写十六进制，64 位的 B3 trace ID 在左侧补零扩展为 128 位，
+// 编码为 B3 格式时高 64 位为零的 trace ID 仍然输出为 16 位，以便与只支持 64 位的服务互通。
+type TraceContext struct {
+	// TraceID 32 位十六进制的 trace ID
+	TraceID string
+	// SpanID 16 位十六进制的 span ID
+	SpanID string
+	// ParentSpanID 16 位十六进制的父 span ID，只有 B3 格式会传递，可以为空
+	ParentSpanID string
+	// Sampled 是否采样
+	Sampled bool
+	// Debug 是否为 debug 请求，只有 B3 格式会传递，为 true 时同时视为采样
+	Debug bool
+}
+
+// IsValid 判断 trace ID 和 span ID 是否合法
+func (tc TraceContext) IsValid() bool {
+	return isHexID(tc.TraceID, 32) && isHexID(tc.SpanID, 16) &&
+		(tc.ParentSpanID == "" || isHexID(tc.ParentSpanID, 16))
+}
+
+// Traceparent 将链路追踪信息编码为 W3C traceparent header 的值
+//
+// 示例:
+//
+//	tc.Traceparent() // 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func (tc TraceContext) Traceparent() string {
+	flags := "00"
+	if tc.Sampled || tc.Debug {
+		flags = "01"
+	}
+	return "00-" + tc.TraceID + "-" + tc.SpanID + "-" + flags
+}
+
+// B3 将链路追踪信息编码为 B3 单 header 格式的值：{TraceId}-{SpanId}-{SamplingState}[-{ParentSpanId}]
+//
+// 示例:
+//
+//	tc.B3() // 80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90
+func (tc TraceContext) B3() string {
+	value := b3TraceID(tc.TraceID) + "-" + tc.SpanID + "-" + tc.b3SamplingState()
+	if tc.ParentSpanID != "" {
+		value += "-" + tc.ParentSpanID
+	}
+	return value
+}
+
+// B3Headers 将链路追踪信息编码为 B3 多 header 格式，键名为小写
+//
+// debug 请求只设置 X-B3-Flags: 1，不设置 X-B3-Sampled（debug 已隐含采样）。
+func (tc TraceContext) B3Headers() map[string]string {
+	headers := map[string]string{
+		B3TraceIDHeader: b3TraceID(tc.TraceID),
+		B3SpanIDHeader:  tc.SpanID,
+	}
+	if tc.ParentSpanID != "" {
+		headers[B3ParentSpanIDHeader] = tc.ParentSpanID
+	}
+	switch {
+	case tc.Debug:
+		headers[B3FlagsHeader] = "1"
+	case tc.Sampled:
+		headers[B3SampledHeader] = "1"
+	default:
+		headers[B3SampledHeader] = "0"
+	}
+	return headers
+}
+
+// b3SamplingState 返回 B3 单 header 格式中的采样状态
+func (tc TraceContext) b3SamplingState() string {
+	switch {
+	case tc.Debug:
+		return "d"
+	case tc.Sampled:
+		return "1"
+	}
+	return "0"
+}
+
+// ParseTraceparent 解析 W3C traceparent header 的值
+//
+// 按 W3C Trace Context 规范校验：ID 必须为小写十六进制且不全为零，版本 ff 不合法，
+// 版本 00 不允许额外的字段，更高的版本忽略 flags 之后的字段。
+//
+// 参数:
+//   - value: traceparent header 的值
+//
+// 返回值:
+//   - TraceContext: 解析后的链路追踪信息
+//   - error: 格式不合法时为 ErrInvalidTraceContext
+func ParseTraceparent(value string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || !isLowerHex(parts[0]) || parts[0] == "ff" ||
+		(parts[0] == "00" && len(parts) != 4) || len(parts[3]) != 2 || !isLowerHex(parts[3]) {
+		return TraceContext{}, fmt.Errorf("%w: traceparent %q", ErrInvalidTraceContext, value)
+	}
+	tc := TraceContext{TraceID: parts[1], SpanID: parts[2]}
+	if !tc.IsValid() {
+		return TraceContext{}, fmt.Errorf("%w: traceparent %q", ErrInvalidTraceContext, value)
+	}
+	// flags 的最低位为采样标记
+	tc.Sampled = strings.IndexByte("13579bdf", parts[3][1]) >= 0
+	return tc, nil
+}
+
+// ParseB3 解析 B3 单 header 格式的值：{TraceId}-{SpanId}[-{SamplingState}[-{ParentSpanId}]]
+//
+// trace ID 可以是 16 位（64 位）或 32 位（128 位）十六进制，采样状态为 "1"、"0" 或 "d"（debug）。
+// 只有采样状态的值（如 "b3: 0"）不包含 ID，无法用于传递链路，返回错误。
+//
+// 参数:
+//   - value: b3 header 的值
+//
+// 返回值:
+//   - TraceContext: 解析后的链路追踪信息
+//   - error: 格式不合法时为 ErrInvalidTraceContext
+func ParseB3(value string) (TraceContext, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(value)), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return TraceContext{}, fmt.Errorf("%w: b3 %q", ErrInvalidTraceContext, value)
+	}
+	tc := TraceContext{TraceID: padTraceID(parts[0]), SpanID: parts[1]}
+	if len(parts) > 2 {
+		switch parts[2] {
+		case "1":
+			tc.Sampled = true
+		case "d":
+			tc.Sampled, tc.Debug = true, true
+		case "0":
+		default:
+			return TraceContext{}, fmt.Errorf("%w: b3 %q", ErrInvalidTraceContext, value)
+		}
+	}
+	if len(parts) > 3 {
+		tc.ParentSpanID = parts[3]
+	}
+	if !tc.IsValid() {
+		return TraceContext{}, fmt.Errorf("%w: b3 %q", ErrInvalidTraceContext, value)
+	}
+	return tc, nil
+}
+
+// ParseB3Headers 解析 B3 多 header 格式，键名不区分大小写
+//
+// X-B3-Sampled 接受 "1"、"0" 以及旧版本使用的 "true"、"false"，X-B3-Flags 为 "1" 时为 debug 请求。
+//
+// 参数:
+//   - headers: 包含 X-B3-TraceId、X-B3-SpanId 等的 headers
+//
+// 返回值:
+//   - TraceContext: 解析后的链路追踪信息
+//   - error: 缺少 ID 或格式不合法时为 ErrInvalidTraceContext
+func ParseB3Headers(headers map[string]string) (TraceContext, error) {
+	lookup := make(map[string]string, len(headers))
+	for key, value := range headers {
+		lookup[strings.ToLower(key)] = value
+	}
+	return parseB3Multi(func(key string) string { return lookup[key] })
+}
+
+// parseB3Multi 通过 get 读取小写键名的 header，解析 B3 多 header 格式
+func parseB3Multi(get func(key string) string) (TraceContext, error) {
+	tc := TraceContext{
+		TraceID:      padTraceID(strings.ToLower(strings.TrimSpace(get(B3TraceIDHeader)))),
+		SpanID:       strings.ToLower(strings.TrimSpace(get(B3SpanIDHeader))),
+		ParentSpanID: strings.ToLower(strings.TrimSpace(get(B3ParentSpanIDHeader))),
+	}
+	switch strings.ToLower(strings.TrimSpace(get(B3SampledHeader))) {
+	case "1", "true":
+		tc.Sampled = true
+	case "0", "false", "":
+	default:
+		return TraceContext{}, fmt.Errorf("%w: %s %q", ErrInvalidTraceContext, B3SampledHeader, get(B3SampledHeader))
+	}
+	if strings.TrimSpace(get(B3FlagsHeader)) == "1" {
+		tc.Sampled, tc.Debug = true, true
+	}
+	if !tc.IsValid() {
+		return TraceContext{}, fmt.Errorf("%w: %s %q, %s %q", ErrInvalidTraceContext,
+			B3TraceIDHeader, get(B3TraceIDHeader), B3SpanIDHeader, get(B3SpanIDHeader))
+	}
+	return tc, nil
+}
+
+// ExtractTraceContext 从 HTTP 请求头中读取链路追踪信息
+//
+// 先按 formats 的顺序，再按 DefaultTraceFormats 的顺序查找其余格式，返回第一个存在且合法的格式，
+// 因此 formats 决定了多种格式冲突时的优先级，未列出的格式同样可以读取。
+//
+// 参数:
+//   - h: HTTP 请求头
+//   - formats: 优先读取的格式，为空时使用 DefaultTraceFormats
+//
+// 返回值:
+//   - TraceContext: 链路追踪信息
+//   - TraceFormat: 读取到的格式
+//   - bool: 是否读取到合法的链路追踪信息
+//
+// 示例:
+//
+//	tc, format, ok := ExtractTraceContext(r.Header, TraceFormatB3Multi)
+func ExtractTraceContext(h http.Header, formats ...TraceFormat) (TraceContext, TraceFormat, bool) {
+	return decodeTraceContext(h.Get, formats)
+}
+
+// InjectTraceContext 按 formats 中的每一种格式将链路追踪信息写入 HTTP 请求头
+//
+// 所有格式使用的请求头会先被删除，避免残留的旧格式与新的链路追踪信息冲突；tc 不合法时只做删除。
+//
+// 参数:
+//   - h: HTTP 请求头
+//   - tc: 链路追踪信息
+//   - formats: 写入的格式，为空时只写入 W3C 格式
+//
+// 示例:
+//
+//	InjectTraceContext(req.Header, tc, TraceFormatW3C, TraceFormatB3Multi)
+func InjectTraceContext(h http.Header, tc TraceContext, formats ...TraceFormat) {
+	for _, key := range traceHeaders {
+		h.Del(key)
+	}
+	for key, value := range encodeTraceContext(tc, formats) {
+		h.Set(key, value)
+	}
+}
+
+// TraceContextFromContext 从上下文的 headers 中读取链路追踪信息
+//
+// 按 DefaultTraceFormats 的顺序查找，返回第一个存在且合法的格式。
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - TraceContext: 链路追踪信息
+//   - bool: 是否读取到合法的链路追踪信息
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, _, ok := decodeTraceContext(traceLookup(headersFrom(ctx)), nil)
+	return tc, ok
+}
+
+// WithTraceContext 将链路追踪信息按 formats 中的每一种格式写入上下文的 headers
+//
+// 上下文中所有格式已有的 trace header 都会被替换。
+//
+// 参数:
+//   - ctx: 原始上下文
+//   - tc: 链路追踪信息
+//   - formats: 写入的格式，为空时只写入 W3C 格式
+//
+// 返回值:
+//   - context.Context: 新的上下文
+//
+// 示例:
+//
+//	ctx = WithTraceContext(ctx, tc, TraceFormatW3C, TraceFormatB3Single)
+func WithTraceContext(ctx context.Context, tc TraceContext, formats ...TraceFormat) context.Context {
+	headers := withoutTraceHeaders(headersFrom(ctx))
+	for key, value := range encodeTraceContext(tc, formats) {
+		headers[key] = []string{value}
+	}
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// convertTraceHeaders 将 headers 中的链路追踪信息转换为 formats 中的所有格式，返回新的 map
+//
+// 读取顺序见 ExtractTraceContext；所有格式已有的 trace header 都会被删除，没有合法的链路追踪信息时不再写入。
+func convertTraceHeaders(headers map[string][]string, formats []TraceFormat) map[string][]string {
+	tc, _, ok := decodeTraceContext(traceLookup(headers), formats)
+	converted := withoutTraceHeaders(headers)
+	if ok {
+		for key, value := range encodeTraceContext(tc, formats) {
+			converted[key] = []string{value}
+		}
+	}
+	return converted
+}
+
+// decodeTraceContext 依次按 formats 和 DefaultTraceFormats 中其余的格式读取链路追踪信息
+func decodeTraceContext(get func(key string) string, formats []TraceFormat) (TraceContext, TraceFormat, bool) {
+	order := append(formats[:len(formats):len(formats)], DefaultTraceFormats...)
+	for _, format := range order {
+		var (
+			tc  TraceContext
+			err error
+		)
+		switch format {
+		case TraceFormatW3C:
+			value := get(TraceparentHeader)
+			if value == "" {
+				continue
+			}
+			tc, err = ParseTraceparent(value)
+		case TraceFormatB3Single:
+			value := get(B3Header)
+			if value == "" {
+				continue
+			}
+			tc, err = ParseB3(value)
+		case TraceFormatB3Multi:
+			if get(B3TraceIDHeader) == "" {
+				continue
+			}
+			tc, err = parseB3Multi(get)
+		default:
+			continue
+		}
+		if err == nil {
+			return tc, format, true
+		}
+	}
+	return TraceContext{}, 0, false
+}
+
+// encodeTraceContext 按 formats 编码链路追踪信息，formats 为空时只编码 W3C 格式，tc 不合法时返回空 map
+func encodeTraceContext(tc TraceContext, formats []TraceFormat) map[string]string {
+	encoded := make(map[string]string)
+	if !tc.IsValid() {
+		return encoded
+	}
+	if len(formats) == 0 {
+		formats = []TraceFormat{TraceFormatW3C}
+	}
+	for _, format := range formats {
+		switch format {
+		case TraceFormatW3C:
+			encoded[TraceparentHeader] = tc.Traceparent()
+		case TraceFormatB3Single:
+			encoded[B3Header] = tc.B3()
+		case TraceFormatB3Multi:
+			for key, value := range tc.B3Headers() {
+				encoded[key] = value
+			}
+		}
+	}
+	return encoded
+}
+
+// traceLookup 返回按小写键名读取 headers 中 trace header 最后一个值的函数
+func traceLookup(headers map[string][]string) func(key string) string {
+	values := make(map[string]string)
+	for key, v := range headers {
+		if lower := strings.ToLower(key); isTraceHeader(lower) && len(v) > 0 {
+			values[lower] = v[len(v)-1]
+		}
+	}
+	return func(key string) string { return values[key] }
+}
+
+// withoutTraceHeaders 复制 headers 并删除所有格式的 trace header，键名不区分大小写
+func withoutTraceHeaders(headers map[string][]string) map[string][]string {
+	copied := copyHeaders(headers, len(traceHeaders))
+	for key := range copied {
+		if isTraceHeader(strings.ToLower(key)) {
+			delete(copied, key)
+		}
+	}
+	return copied
+}
+
+// isTraceHeader 判断小写的键名是否为某种格式的 trace header
+func isTraceHeader(key string) bool {
+	for _, header := range traceHeaders {
+		if key == header {
+			return true
+		}
+	}
+	return false
+}
+
+// padTraceID 将 64 位的 trace ID 左侧补零扩展为 128 位
+func padTraceID(id string) string {
+	if len(id) == 16 {
+		return strings.Repeat("0", 16) + id
+	}
+	return id
+}
+
+// b3TraceID 返回 B3 格式的 trace ID，高 64 位为零时只输出低 64 位
+func b3TraceID(id string) string {
+	if strings.HasPrefix(id, strings.Repeat("0", 16)) {
+		return id[16:]
+	}
+	return id
+}
+
+// isHexID 判断 id 是否为指定长度、不全为零的小写十六进制
+func isHexID(id string, length int) bool {
+	return len(id) == length && isLowerHex(id) && strings.Trim(id, "0") != ""
+}
+
+// isLowerHex 判断字符串是否只包含小写十六进制字符
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
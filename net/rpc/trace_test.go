@@ -0,0 +1,296 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	testTraceID   = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID    = "00f067aa0ba902b7"
+	testParentID  = "05e3ac9a4f6e3b90"
+	testTraceID64 = "a3ce929d0e0e4736"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tc, err := ParseTraceparent("00-" + testTraceID + "-" + testSpanID + "-01")
+	assert.NoError(t, err)
+	assert.Equal(t, TraceContext{TraceID: testTraceID, SpanID: testSpanID, Sampled: true}, tc)
+	assert.Equal(t, "00-"+testTraceID+"-"+testSpanID+"-01", tc.Traceparent())
+
+	tc, err = ParseTraceparent("00-" + testTraceID + "-" + testSpanID + "-00")
+	assert.NoError(t, err)
+	assert.False(t, tc.Sampled)
+
+	// 更高的版本忽略多余的字段
+	tc, err = ParseTraceparent("01-" + testTraceID + "-" + testSpanID + "-03-extra")
+	assert.NoError(t, err)
+	assert.True(t, tc.Sampled)
+
+	for _, value := range []string{
+		"",
+		"00-" + testTraceID + "-" + testSpanID,
+		"00-" + testTraceID + "-" + testSpanID + "-01-extra",
+		"ff-" + testTraceID + "-" + testSpanID + "-01",
+		"00-00000000000000000000000000000000-" + testSpanID + "-01",
+		"00-" + testTraceID + "-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testSpanID + "-01",
+		"00-" + testTraceID64 + "-" + testSpanID + "-01",
+		"00-" + testTraceID + "-" + testSpanID + "-1",
+	} {
+		_, err := ParseTraceparent(value)
+		assert.ErrorIs(t, err, ErrInvalidTraceContext, value)
+	}
+}
+
+func TestParseB3(t *testing.T) {
+	tests := []struct {
+		value string
+		want  TraceContext
+	}{
+		// 128 位 trace ID
+		{testTraceID + "-" + testSpanID + "-1", TraceContext{TraceID: testTraceID, SpanID: testSpanID, Sampled: true}},
+		// 64 位 trace ID 左侧补零
+		{testTraceID64 + "-" + testSpanID + "-0", TraceContext{TraceID: "0000000000000000" + testTraceID64, SpanID: testSpanID}},
+		// debug 隐含采样
+		{testTraceID + "-" + testSpanID + "-d", TraceContext{TraceID: testTraceID, SpanID: testSpanID, Sampled: true, Debug: true}},
+		// 带父 span ID
+		{testTraceID + "-" + testSpanID + "-1-" + testParentID, TraceContext{TraceID: testTraceID, SpanID: testSpanID, ParentSpanID: testParentID, Sampled: true}},
+		// 没有采样状态
+		{testTraceID + "-" + testSpanID, TraceContext{TraceID: testTraceID, SpanID: testSpanID}},
+	}
+	for _, tt := range tests {
+		tc, err := ParseB3(tt.value)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, tc, tt.value)
+		// 编码后与原值一致，64 位的 trace ID 仍输出为 16 位
+		if tt.value != testTraceID+"-"+testSpanID {
+			assert.Equal(t, tt.value, tc.B3(), tt.value)
+		}
+	}
+
+	for _, value := range []string{
+		"",
+		"0",
+		"d",
+		testTraceID + "-" + testSpanID + "-x",
+		testTraceID + "-" + testSpanID + "-1-" + testParentID + "-extra",
+		"abc-" + testSpanID + "-1",
+		testTraceID + "-0000000000000000-1",
+	} {
+		_, err := ParseB3(value)
+		assert.ErrorIs(t, err, ErrInvalidTraceContext, value)
+	}
+}
+
+func TestParseB3Headers(t *testing.T) {
+	// 键名不区分大小写，64 位 trace ID 左侧补零
+	tc, err := ParseB3Headers(map[string]string{
+		"X-B3-TraceId":      testTraceID64,
+		"X-B3-SpanId":       testSpanID,
+		"X-B3-ParentSpanId": testParentID,
+		"X-B3-Sampled":      "1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TraceContext{TraceID: "0000000000000000" + testTraceID64, SpanID: testSpanID, ParentSpanID: testParentID, Sampled: true}, tc)
+	assert.Equal(t, map[string]string{
+		B3TraceIDHeader:      testTraceID64,
+		B3SpanIDHeader:       testSpanID,
+		B3ParentSpanIDHeader: testParentID,
+		B3SampledHeader:      "1",
+	}, tc.B3Headers())
+
+	// 旧版本的 true/false 采样标记
+	tc, err = ParseB3Headers(map[string]string{B3TraceIDHeader: testTraceID, B3SpanIDHeader: testSpanID, B3SampledHeader: "true"})
+	assert.NoError(t, err)
+	assert.True(t, tc.Sampled)
+
+	// X-B3-Flags: 1 表示 debug，编码时不再设置 X-B3-Sampled
+	tc, err = ParseB3Headers(map[string]string{B3TraceIDHeader: testTraceID, B3SpanIDHeader: testSpanID, B3FlagsHeader: "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, TraceContext{TraceID: testTraceID, SpanID: testSpanID, Sampled: true, Debug: true}, tc)
+	assert.Equal(t, map[string]string{
+		B3TraceIDHeader: testTraceID,
+		B3SpanIDHeader:  testSpanID,
+		B3FlagsHeader:   "1",
+	}, tc.B3Headers())
+	assert.Equal(t, "00-"+testTraceID+"-"+testSpanID+"-01", tc.Traceparent())
+
+	for _, headers := range []map[string]string{
+		{B3TraceIDHeader: testTraceID},
+		{B3TraceIDHeader: testTraceID, B3SpanIDHeader: testSpanID, B3SampledHeader: "yes"},
+		{B3TraceIDHeader: testTraceID, B3SpanIDHeader: testSpanID, B3ParentSpanIDHeader: "bad"},
+	} {
+		_, err := ParseB3Headers(headers)
+		assert.ErrorIs(t, err, ErrInvalidTraceContext)
+	}
+}
+
+func TestExtractTraceContext(t *testing.T) {
+	w3c := TraceContext{TraceID: testTraceID, SpanID: testSpanID, Sampled: true}
+	b3 := TraceContext{TraceID: "0000000000000000" + testTraceID64, SpanID: testParentID}
+
+	h := http.Header{}
+	_, _, ok := ExtractTraceContext(h)
+	assert.False(t, ok)
+
+	h.Set("X-B3-TraceId", testTraceID64)
+	h.Set("X-B3-SpanId", testParentID)
+	h.Set("X-B3-Sampled", "0")
+	tc, format, ok := ExtractTraceContext(h)
+	assert.True(t, ok)
+	assert.Equal(t, TraceFormatB3Multi, format)
+	assert.Equal(t, b3, tc)
+
+	// 冲突时默认 W3C 优先
+	h.Set("Traceparent", w3c.Traceparent())
+	tc, format, _ = ExtractTraceContext(h)
+	assert.Equal(t, TraceFormatW3C, format)
+	assert.Equal(t, w3c, tc)
+
+	// 指定的格式优先
+	tc, format, _ = ExtractTraceContext(h, TraceFormatB3Multi)
+	assert.Equal(t, TraceFormatB3Multi, format)
+	assert.Equal(t, b3, tc)
+
+	// 不合法的格式被跳过
+	h.Set("Traceparent", "garbage")
+	_, format, _ = ExtractTraceContext(h)
+	assert.Equal(t, TraceFormatB3Multi, format)
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	tc := TraceContext{TraceID: testTraceID, SpanID: testSpanID, Sampled: true}
+	h := http.Header{}
+	h.Set("X-B3-Flags", "1")
+	h.Set("Accept", "application/json")
+
+	// 写入所有格式，并清除残留的旧 header
+	InjectTraceContext(h, tc, TraceFormatW3C, TraceFormatB3Single, TraceFormatB3Multi)
+	assert.Equal(t, http.Header{
+		"Accept":       {"application/json"},
+		"Traceparent":  {tc.Traceparent()},
+		"B3":           {testTraceID + "-" + testSpanID + "-1"},
+		"X-B3-Traceid": {testTraceID},
+		"X-B3-Spanid":  {testSpanID},
+		"X-B3-Sampled": {"1"},
+	}, h)
+
+	// 默认只写入 W3C 格式
+	h = http.Header{}
+	InjectTraceContext(h, tc)
+	assert.Equal(t, http.Header{"Traceparent": {tc.Traceparent()}}, h)
+
+	// 不合法的链路追踪信息不写入
+	h = http.Header{}
+	InjectTraceContext(h, TraceContext{TraceID: "bad"})
+	assert.Empty(t, h)
+}
+
+func TestTraceContextFromContext(t *testing.T) {
+	_, ok := TraceContextFromContext(context.Background())
+	assert.False(t, ok)
+
+	tc := TraceContext{TraceID: testTraceID, SpanID: testSpanID, Debug: true, Sampled: true}
+	ctx := SetRPCHeader(context.Background(), "traceparent", "00-"+testTraceID+"-0000000000000001-00")
+	ctx = WithTraceContext(ctx, tc, TraceFormatB3Single)
+
+	// 已有的其他格式被替换
+	assert.Equal(t, map[string]string{B3Header: testTraceID + "-" + testSpanID + "-d"}, GetRPCHeaders(ctx))
+	got, ok := TraceContextFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, tc, got)
+
+	// 默认写入 W3C 格式
+	ctx = WithTraceContext(ctx, tc)
+	assert.Equal(t, map[string]string{TraceparentHeader: tc.Traceparent()}, GetRPCHeaders(ctx))
+}
+
+func TestTraceFormatsMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats []TraceFormat
+		header  http.Header
+		want    map[string]string
+	}{
+		{
+			name:    "b3 single to w3c and b3 multi",
+			formats: []TraceFormat{TraceFormatW3C, TraceFormatB3Multi},
+			header:  http.Header{"B3": {testTraceID64 + "-" + testSpanID + "-1"}},
+			want: map[string]string{
+				TraceparentHeader: "00-0000000000000000" + testTraceID64 + "-" + testSpanID + "-01",
+				B3TraceIDHeader:   testTraceID64,
+				B3SpanIDHeader:    testSpanID,
+				B3SampledHeader:   "1",
+			},
+		},
+		{
+			name:    "conflicting formats prefer the configured order",
+			formats: []TraceFormat{TraceFormatB3Multi, TraceFormatW3C},
+			header: http.Header{
+				"Traceparent":  {"00-" + testTraceID + "-" + testSpanID + "-00"},
+				"X-B3-Traceid": {testTraceID},
+				"X-B3-Spanid":  {testParentID},
+				"X-B3-Flags":   {"1"},
+			},
+			want: map[string]string{
+				TraceparentHeader: "00-" + testTraceID + "-" + testParentID + "-01",
+				B3TraceIDHeader:   testTraceID,
+				B3SpanIDHeader:    testParentID,
+				B3FlagsHeader:     "1",
+			},
+		},
+		{
+			name:    "invalid trace headers are dropped",
+			formats: []TraceFormat{TraceFormatW3C},
+			header:  http.Header{"Traceparent": {"garbage"}, "X-Request-Id": {"req-1"}},
+			want:    map[string]string{RequestIDHeader: "req-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header = tt.header
+			var got map[string]string
+			HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetRPCHeaders(r.Context())
+			}), WithTraceFormats(tt.formats...)).ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTraceFormatsInject(t *testing.T) {
+	// 上下文中是 W3C 格式，注入到只支持 B3 的旧服务
+	ctx := SetRPCHeaders(context.Background(), map[string]string{
+		TraceparentHeader: "00-" + testTraceID + "-" + testSpanID + "-01",
+		RequestIDHeader:   "req-1",
+	})
+	h := http.Header{}
+	InjectHTTPHeaders(ctx, h, WithTraceFormats(TraceFormatB3Single))
+	assert.Equal(t, http.Header{
+		"X-Request-Id": {"req-1"},
+		"B3":           {testTraceID + "-" + testSpanID + "-1"},
+	}, h)
+
+	// 通过默认策略为 gRPC 客户端配置格式
+	setDefaultPolicy(t, PropagationPolicy{TraceFormats: []TraceFormat{TraceFormatW3C, TraceFormatB3Multi}})
+	ctx = WithTraceContext(context.Background(), TraceContext{TraceID: "0000000000000000" + testTraceID64, SpanID: testSpanID}, TraceFormatB3Single)
+	md, _ := metadata.FromOutgoingContext(injectGRPCHeaders(ctx, newPropagationConfig()))
+	assert.Equal(t, metadata.MD{
+		TraceparentHeader: {"00-0000000000000000" + testTraceID64 + "-" + testSpanID + "-00"},
+		B3TraceIDHeader:   {testTraceID64},
+		B3SpanIDHeader:    {testSpanID},
+		B3SampledHeader:   {"0"},
+	}, md)
+
+	// 未配置格式时原样传递
+	h = http.Header{}
+	InjectHTTPHeaders(ctx, h, WithTraceFormats())
+	assert.Equal(t, http.Header{"B3": {testTraceID64 + "-" + testSpanID + "-0"}}, h)
+}
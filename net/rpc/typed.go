@@ -0,0 +1,154 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidHeaderValue header 存在，但值无法解析为要求的类型
+var ErrInvalidHeaderValue = errors.New("invalid header value")
+
+// GetRPCHeaderInt 从上下文中获取 header，并解析为十进制整数
+//
+// 与 GetRPCHeader 一致，header 有多个值时使用最后添加的值，解析前忽略首尾空白。
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: header 的键名
+//
+// 返回值:
+//   - int64: 解析后的值，不存在或解析失败时为 0
+//   - bool: 是否存在该 header
+//   - error: 值无法解析时为 ErrInvalidHeaderValue，错误信息包含原始值
+//
+// 示例:
+//
+//	retries, ok, err := GetRPCHeaderInt(ctx, "x-retry-count")
+//	if err != nil {
+//	    return err
+//	}
+//	if ok && retries >= 3 {
+//	    // 放入死信队列
+//	}
+func GetRPCHeaderInt(ctx context.Context, key string) (int64, bool, error) {
+	return getTypedHeader(ctx, key, func(value string) (int64, error) {
+		return strconv.ParseInt(value, 10, 64)
+	})
+}
+
+// SetRPCHeaderInt 在上下文中设置整数 header，按十进制格式化，替换该 header 已有的所有值
+//
+// 示例:
+//
+//	ctx = SetRPCHeaderInt(ctx, "x-retry-count", int64(attempt))
+func SetRPCHeaderInt(ctx context.Context, key string, value int64) context.Context {
+	return SetRPCHeader(ctx, key, strconv.FormatInt(value, 10))
+}
+
+// GetRPCHeaderBool 从上下文中获取 header，并解析为布尔值
+//
+// 接受 strconv.ParseBool 支持的值（"1"、"t"、"true"、"0"、"f"、"false" 等），解析前忽略首尾空白。
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: header 的键名
+//
+// 返回值:
+//   - bool: 解析后的值，不存在或解析失败时为 false
+//   - bool: 是否存在该 header
+//   - error: 值无法解析时为 ErrInvalidHeaderValue，错误信息包含原始值
+//
+// 示例:
+//
+//	internal, _, err := GetRPCHeaderBool(ctx, "x-is-internal")
+func GetRPCHeaderBool(ctx context.Context, key string) (bool, bool, error) {
+	return getTypedHeader(ctx, key, strconv.ParseBool)
+}
+
+// SetRPCHeaderBool 在上下文中设置布尔 header，格式化为 "true" 或 "false"，替换该 header 已有的所有值
+func SetRPCHeaderBool(ctx context.Context, key string, value bool) context.Context {
+	return SetRPCHeader(ctx, key, strconv.FormatBool(value))
+}
+
+// GetRPCHeaderTime 从上下文中获取 header，并解析为时间
+//
+// 接受 RFC 3339 格式（可以带小数秒）或 Unix 时间戳（秒），解析前忽略首尾空白。
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: header 的键名
+//
+// 返回值:
+//   - time.Time: 解析后的时间，Unix 时间戳按 UTC 返回；不存在或解析失败时为零值
+//   - bool: 是否存在该 header
+//   - error: 值无法解析时为 ErrInvalidHeaderValue，错误信息包含原始值
+//
+// 示例:
+//
+//	deadline, ok, err := GetRPCHeaderTime(ctx, "x-deadline")
+//	if err == nil && ok && time.Now().After(deadline) {
+//	    return context.DeadlineExceeded
+//	}
+func GetRPCHeaderTime(ctx context.Context, key string) (time.Time, bool, error) {
+	return getTypedHeader(ctx, key, parseHeaderTime)
+}
+
+// SetRPCHeaderTime 在上下文中设置时间 header，按 UTC 格式化为 RFC 3339（保留小数秒），替换该 header 已有的所有值
+//
+// 示例:
+//
+//	ctx = SetRPCHeaderTime(ctx, "x-deadline", time.Now().Add(30*time.Second))
+func SetRPCHeaderTime(ctx context.Context, key string, value time.Time) context.Context {
+	return SetRPCHeader(ctx, key, value.UTC().Format(time.RFC3339Nano))
+}
+
+// GetRPCHeaderDuration 从上下文中获取 header，并按 time.ParseDuration 解析为时长
+//
+// 值须带单位，如 "1.5s"、"300ms"、"1h30m"，解析前忽略首尾空白。
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: header 的键名
+//
+// 返回值:
+//   - time.Duration: 解析后的时长，不存在或解析失败时为 0
+//   - bool: 是否存在该 header
+//   - error: 值无法解析时为 ErrInvalidHeaderValue，错误信息包含原始值
+//
+// 示例:
+//
+//	budget, ok, err := GetRPCHeaderDuration(ctx, "x-timeout-budget")
+func GetRPCHeaderDuration(ctx context.Context, key string) (time.Duration, bool, error) {
+	return getTypedHeader(ctx, key, time.ParseDuration)
+}
+
+// SetRPCHeaderDuration 在上下文中设置时长 header，按 time.Duration.String 格式化，替换该 header 已有的所有值
+func SetRPCHeaderDuration(ctx context.Context, key string, value time.Duration) context.Context {
+	return SetRPCHeader(ctx, key, value.String())
+}
+
+// getTypedHeader 获取 header 的值并解析，区分不存在和解析失败
+func getTypedHeader[T any](ctx context.Context, key string, parse func(string) (T, error)) (T, bool, error) {
+	var zero T
+	raw, ok := GetRPCHeader(ctx, key)
+	if !ok {
+		return zero, false, nil
+	}
+	value, err := parse(strings.TrimSpace(raw))
+	if err != nil {
+		return zero, true, fmt.Errorf("%w: %s=%q", ErrInvalidHeaderValue, key, raw)
+	}
+	return value, true, nil
+}
+
+// parseHeaderTime 解析 RFC 3339 格式的时间或 Unix 时间戳（秒）
+func parseHeaderTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// typedHeaderCase 类型化 header 的测试用例，raw 为 nil 时表示未设置
+type typedHeaderCase[T any] struct {
+	name    string
+	raw     *string
+	want    T
+	present bool
+	wantErr bool
+}
+
+// runTypedHeaderCases 依次设置原始值并用 get 读取，检查结果
+func runTypedHeaderCases[T any](t *testing.T, get func(context.Context, string) (T, bool, error), cases []typedHeaderCase[T]) {
+	t.Helper()
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.raw != nil {
+				ctx = SetRPCHeader(ctx, "x-key", *tt.raw)
+			}
+			got, present, err := get(ctx, "x-key")
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.present, present)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidHeaderValue)
+				// 错误信息包含原始值
+				assert.Contains(t, err.Error(), *tt.raw)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// strPtr 返回字符串的指针
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestGetRPCHeaderInt(t *testing.T) {
+	runTypedHeaderCases(t, GetRPCHeaderInt, []typedHeaderCase[int64]{
+		{name: "absent"},
+		{name: "valid", raw: strPtr("42"), want: 42, present: true},
+		{name: "negative", raw: strPtr("-7"), want: -7, present: true},
+		{name: "whitespace", raw: strPtr(" 3 "), want: 3, present: true},
+		{name: "empty", raw: strPtr(""), present: true, wantErr: true},
+		{name: "malformed", raw: strPtr("3x"), present: true, wantErr: true},
+		{name: "overflow", raw: strPtr("9223372036854775808"), present: true, wantErr: true},
+	})
+}
+
+func TestGetRPCHeaderBool(t *testing.T) {
+	runTypedHeaderCases(t, GetRPCHeaderBool, []typedHeaderCase[bool]{
+		{name: "absent"},
+		{name: "true", raw: strPtr("true"), want: true, present: true},
+		{name: "one", raw: strPtr("1"), want: true, present: true},
+		{name: "false", raw: strPtr("FALSE"), want: false, present: true},
+		{name: "malformed", raw: strPtr("yes"), present: true, wantErr: true},
+	})
+}
+
+func TestGetRPCHeaderTime(t *testing.T) {
+	runTypedHeaderCases(t, GetRPCHeaderTime, []typedHeaderCase[time.Time]{
+		{name: "absent"},
+		{name: "rfc3339", raw: strPtr("2024-05-01T08:00:00Z"), want: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC), present: true},
+		{name: "rfc3339 nano", raw: strPtr("2024-05-01T08:00:00.5Z"), want: time.Date(2024, 5, 1, 8, 0, 0, 5e8, time.UTC), present: true},
+		{name: "unix seconds", raw: strPtr("1714550400"), want: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC), present: true},
+		{name: "malformed", raw: strPtr("2024-05-01"), present: true, wantErr: true},
+		{name: "fractional unix", raw: strPtr("1714550400.5"), present: true, wantErr: true},
+	})
+
+	// 带时区的时间与 UTC 表示同一时刻
+	ctx := SetRPCHeader(context.Background(), "x-key", "2024-05-01T16:00:00+08:00")
+	got, _, err := GetRPCHeaderTime(ctx, "x-key")
+	assert.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)))
+}
+
+func TestGetRPCHeaderDuration(t *testing.T) {
+	runTypedHeaderCases(t, GetRPCHeaderDuration, []typedHeaderCase[time.Duration]{
+		{name: "absent"},
+		{name: "valid", raw: strPtr("1.5s"), want: 1500 * time.Millisecond, present: true},
+		{name: "compound", raw: strPtr("1h30m"), want: 90 * time.Minute, present: true},
+		{name: "zero", raw: strPtr("0"), want: 0, present: true},
+		{name: "missing unit", raw: strPtr("30"), present: true, wantErr: true},
+		{name: "malformed", raw: strPtr("soon"), present: true, wantErr: true},
+	})
+}
+
+func TestSetRPCHeaderTyped(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 16, 0, 0, 250e6, time.FixedZone("CST", 8*3600))
+	ctx := context.Background()
+	ctx = SetRPCHeaderInt(ctx, "x-retry-count", 3)
+	ctx = SetRPCHeaderBool(ctx, "x-is-internal", true)
+	ctx = SetRPCHeaderTime(ctx, "x-deadline", ts)
+	ctx = SetRPCHeaderDuration(ctx, "x-budget", 1500*time.Millisecond)
+
+	// 按规范格式写入
+	assert.Equal(t, map[string]string{
+		"x-retry-count": "3",
+		"x-is-internal": "true",
+		"x-deadline":    "2024-05-01T08:00:00.25Z",
+		"x-budget":      "1.5s",
+	}, GetRPCHeaders(ctx))
+
+	// 读写往返
+	n, _, _ := GetRPCHeaderInt(ctx, "x-retry-count")
+	assert.Equal(t, int64(3), n)
+	b, _, _ := GetRPCHeaderBool(ctx, "x-is-internal")
+	assert.True(t, b)
+	tm, _, _ := GetRPCHeaderTime(ctx, "x-deadline")
+	assert.True(t, tm.Equal(ts))
+	d, _, _ := GetRPCHeaderDuration(ctx, "x-budget")
+	assert.Equal(t, 1500*time.Millisecond, d)
+
+	// 替换已有的多个值
+	ctx = AddRPCHeader(ctx, "x-retry-count", "9")
+	ctx = SetRPCHeaderInt(ctx, "x-retry-count", 4)
+	assert.Equal(t, []string{"4"}, GetRPCHeaderValues(ctx, "x-retry-count"))
+}